@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/controller"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
+	"go-structure-demo/internal/repository/postgresrepo"
+	"go-structure-demo/internal/validator"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// apiKeyCommand manages the api keys as the system, whoever can run it can reach the database
+// anyway.
+func apiKeyCommand(args []string) {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx = auth.WithPrincipal(ctx, auth.SystemPrincipal())
+	cfg := config.Read()
+
+	logger, loggerCloser := log.NewZapFromEnv(cfg.AppName)
+	defer loggerCloser()
+
+	postgresRepo, postgresRepoCloser, err := postgresrepo.New(cfg)
+	if err != nil {
+		logger.Fatal("initializing postgres", err)
+	}
+	defer postgresRepoCloser()
+
+	apiKeyController := controller.NewAPIKeyController(logger, postgresRepo, policy.New())
+
+	switch args[0] {
+	case "create":
+		flags := flag.NewFlagSet("apikey create", flag.ExitOnError)
+		name := flags.String("name", "", "what the key is for")
+		owner := flags.String("owner", "", "the team or service owning the key")
+		scopes := flags.String("scopes", "", "comma separated scopes, like user:create")
+		expiresIn := flags.Duration("expires-in", 0, "lifetime of the key, it doesn't expire by default")
+		_ = flags.Parse(args[1:])
+
+		request := &param.CreateAPIKeyRequest{Name: *name, Owner: *owner}
+		if *scopes != "" {
+			request.Scopes = strings.Split(*scopes, ",")
+		}
+		if *expiresIn > 0 {
+			expiresAt := time.Now().Add(*expiresIn)
+			request.ExpiresAt = &expiresAt
+		}
+		if err := validator.CreateAPIKeyRequest(ctx, request); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+
+		response := apiKeyController.CreateAPIKey(ctx, request)
+		if response.Error != nil {
+			logger.Fatal("creating api key", response.Error)
+		}
+		fmt.Printf("api key %d created, it is shown only once:\n%s\n", response.APIKey.ID, response.Key)
+	case "list":
+		response := apiKeyController.ListAPIKeys(ctx, &param.ListAPIKeysRequest{})
+		if response.Error != nil {
+			logger.Fatal("listing api keys", response.Error)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tPREFIX\tNAME\tOWNER\tSCOPES\tEXPIRES\tLAST USED\tREVOKED")
+		for _, key := range response.APIKeys {
+			fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+				key.ID, key.Prefix, key.Name, key.Owner, strings.Join(key.Scopes, ","),
+				formatTime(key.ExpiresAt), formatTime(key.LastUsedAt), formatTime(key.RevokedAt))
+		}
+		_ = w.Flush()
+	case "revoke":
+		if len(args) != 2 {
+			fmt.Fprint(os.Stderr, usage)
+			os.Exit(2)
+		}
+		id, err := strconv.ParseUint(args[1], 10, 64)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid api key id", args[1])
+			os.Exit(2)
+		}
+		response := apiKeyController.RevokeAPIKey(ctx, &param.RevokeAPIKeyRequest{ID: uint(id)})
+		if response.Error != nil {
+			logger.Fatal("revoking api key", response.Error)
+		}
+		fmt.Printf("api key %d revoked\n", id)
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/controller"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/policy"
+	"go-structure-demo/internal/repository/cacherepo"
+	"go-structure-demo/internal/repository/postgresrepo"
+	"go-structure-demo/internal/repository/redisrepo"
+	"go-structure-demo/internal/ridersync"
+	"go-structure-demo/internal/webhook"
+)
+
+// userControllers are the controllers every command creating users needs.
+type userControllers struct {
+	userStore      contract.UserStore
+	authController *controller.AuthController
+	userController *controller.UserController
+	riderSyncer    *ridersync.Syncer
+	dispatcher     *webhook.Dispatcher
+}
+
+func newUserControllers(
+	cfg *config.Config,
+	logger log.Logger,
+	metricsClient metrics.Metrics,
+	redisRepo *redisrepo.RedisRepo,
+	postgresRepo *postgresrepo.PostgresRepo,
+	notifierClient contract.Notifier,
+) userControllers {
+	userStore := cacherepo.NewUserStore(cfg.UserCache, logger, metricsClient, redisRepo.Adapter(), postgresRepo, postgresRepo)
+	authController := controller.NewAuthController(cfg.Auth, logger, userStore, redisRepo, redisRepo, notifierClient)
+	riderSyncer := ridersync.NewSyncer(
+		cfg.RiderSync,
+		logger,
+		metricsClient,
+		cacherepo.NewRiderSyncStore(redisRepo.Adapter(), postgresRepo, postgresRepo),
+		newRiderProfileClient(cfg.RiderProfile, logger, metricsClient),
+	)
+	dispatcher := webhook.NewDispatcher(cfg.Webhook, logger, metricsClient, postgresRepo, postgresRepo)
+	return userControllers{
+		userStore:      userStore,
+		authController: authController,
+		userController: controller.NewUserController(logger, postgresRepo, userStore, postgresRepo, policy.New(), authController, riderSyncer, dispatcher),
+		riderSyncer:    riderSyncer,
+		dispatcher:     dispatcher,
+	}
+}
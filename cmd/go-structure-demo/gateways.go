@@ -0,0 +1,35 @@
+package main
+
+import (
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/gateway/quinyxgateway"
+	"go-structure-demo/internal/gateway/riderprofilegateway"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+)
+
+// newRiderProfileClient returns the fake of the FakeScenario when there is one.
+func newRiderProfileClient(cfg config.RiderProfile, logger log.Logger, metricsClient metrics.Metrics) riderprofilegateway.Client {
+	if cfg.FakeScenario == "" {
+		return riderprofilegateway.New(cfg, logger, metricsClient)
+	}
+	fake, err := riderprofilegateway.LoadFake(cfg.FakeScenario)
+	if err != nil {
+		logger.Fatal("loading the rider profile fake scenario", err)
+	}
+	logger.Info("the rider profile service is faked", map[string]interface{}{"scenario": cfg.FakeScenario})
+	return fake
+}
+
+// newQuinyxClient returns the fake of the FakeScenario when there is one.
+func newQuinyxClient(cfg config.Quinyx, logger log.Logger, metricsClient metrics.Metrics) quinyxgateway.Client {
+	if cfg.FakeScenario == "" {
+		return quinyxgateway.New(cfg, logger, metricsClient)
+	}
+	fake, err := quinyxgateway.LoadFake(cfg.FakeScenario)
+	if err != nil {
+		logger.Fatal("loading the quinyx fake scenario", err)
+	}
+	logger.Info("quinyx is faked", map[string]interface{}{"scenario": cfg.FakeScenario})
+	return fake
+}
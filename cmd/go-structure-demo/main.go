@@ -1,54 +1,46 @@
 package main
 
 import (
-	"context"
-	"go-structure-demo/internal/config"
-	"go-structure-demo/internal/delivery/http/httpserver"
-	"go-structure-demo/internal/delivery/pubsub/subscriber"
-	"go-structure-demo/internal/log"
-	"go-structure-demo/internal/pubsub"
-	"go-structure-demo/internal/repository/postgresrepo"
-	"go-structure-demo/internal/repository/redisrepo"
+	"fmt"
 	"os"
-	"os/signal"
 )
 
-func main() {
-	ctx := context.Background()
-	cfg := config.Read()
-
-	logger, loggerCloser := log.NewZapFromEnv(cfg.AppName)
-	defer loggerCloser()
-
-	redisRepo, redisRepoClose := redisrepo.New(cfg)
-	defer redisRepoClose()
+const usage = `usage: go-structure-demo [command]
+
+commands:
+  serve           run the http server and the pubsub subscribers (default)
+  outbox relay    run the outbox relay only
+  pubsub provision
+                  create or update the topics and subscriptions of the config
+  apikey create -name NAME -owner OWNER -scopes SCOPE[,SCOPE] [-expires-in DURATION]
+                  create an api key, the key is printed once
+  apikey list     list the api keys
+  apikey revoke ID
+                  revoke an api key
+  reconcile quinyx [-dry-run]
+                  create the users missing for the quinyx employees and report the drift
+`
 
-	postgresRepo, postgresRepoCloser := postgresrepo.New(cfg)
-	defer postgresRepoCloser()
-
-	pubsubClientA, err := pubsub.New(logger, ctx, cfg.PubSub.ProjectA)
-	if err != nil {
-		logger.Error("initializing pubsub", err)
-	}
-	pubsubClientB, err := pubsub.New(logger, ctx, cfg.PubSub.ProjectB)
-	if err != nil {
-		logger.Error("initializing pubsub", err)
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 {
+		serve()
+		return
 	}
 
-	httpServer := httpserver.New(cfg, logger, redisRepo, postgresRepo, pubsubClientA, pubsubClientB)
-	go httpServer.Start()
-
-	pubsubClientCloser := subscriber.Subscribe(ctx, cfg, logger, redisRepo, postgresRepo, pubsubClientA, pubsubClientB)
-	defer pubsubClientCloser()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
-	<-quit
-
-	logger.Info("shutting signal received")
-	c, cancel := context.WithTimeout(context.Background(), cfg.HTTP.GracefulShutdown)
-	defer cancel()
-	httpServer.Shutdown(c)
-	<-c.Done()
-	logger.Info("bye bye")
+	switch args[0] {
+	case "serve":
+		serve()
+	case "outbox":
+		outbox(args[1:])
+	case "pubsub":
+		pubsubCommand(args[1:])
+	case "apikey":
+		apiKeyCommand(args[1:])
+	case "reconcile":
+		reconcileCommand(args[1:])
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
 }
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/adapter/redis"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/lock"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	outboxrelay "go-structure-demo/internal/outbox"
+	"go-structure-demo/internal/repository/postgresrepo"
+	"os"
+	"os/signal"
+)
+
+func outbox(args []string) {
+	if len(args) == 0 || args[0] != "relay" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cfg := config.Read()
+
+	logger, loggerCloser := log.NewZapFromEnv(cfg.AppName)
+	defer loggerCloser()
+
+	metricsClient, metricsCloser, err := metrics.New(cfg.Metrics)
+	if err != nil {
+		logger.Fatal("initializing metrics", err)
+	}
+	defer metricsCloser()
+	log.ReportDrops(logger, metricsClient)
+
+	postgresRepo, postgresRepoCloser, err := postgresrepo.New(cfg)
+	if err != nil {
+		logger.Fatal("initializing postgres", err)
+	}
+	defer postgresRepoCloser()
+
+	redisAdapter, err := redis.New(cfg.Redis, logger)
+	if err != nil {
+		logger.Fatal("initializing redis", err)
+	}
+	defer redisAdapter.Close()
+
+	pubsubClientB, err := newPubSubClient(ctx, cfg, logger, cfg.PubSub.ProjectB)
+	if err != nil {
+		logger.Fatal("initializing pubsub", err)
+	}
+	defer pubsubClientB.Close()
+
+	relay := outboxrelay.NewRelay(cfg.Outbox, logger, metricsClient, postgresRepo, pubsubClientB)
+	lock.NewElector(cfg.Lock, logger, lock.NewLocker(redisAdapter, logger), outboxrelay.LockKey).Run(ctx, relay.Run)
+	logger.Info("bye bye")
+}
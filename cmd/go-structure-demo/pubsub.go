@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/pubsub"
+	"os"
+	"os/signal"
+
+	"google.golang.org/api/option"
+)
+
+func newPubSubClient(ctx context.Context, cfg *config.Config, logger log.Logger, projectID string) (*pubsub.GCPClient, error) {
+	var opts []option.ClientOption
+	if cfg.PubSub.EmulatorHost != "" {
+		emulatorOpts, err := pubsub.EmulatorOptions(cfg.PubSub.EmulatorHost)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, emulatorOpts...)
+	}
+
+	return pubsub.New(logger, ctx, projectID, pubsub.PublishSettings{
+		CountThreshold: cfg.PubSub.Publish.CountThreshold,
+		ByteThreshold:  cfg.PubSub.Publish.ByteThreshold,
+		DelayThreshold: cfg.PubSub.Publish.DelayThreshold,
+		Timeout:        cfg.PubSub.Publish.Timeout,
+	}, opts...)
+}
+
+func pubsubCommand(args []string) {
+	if len(args) == 0 || args[0] != "provision" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	cfg := config.Read()
+
+	logger, loggerCloser := log.NewZapFromEnv(cfg.AppName)
+	defer loggerCloser()
+
+	clients := make(map[string]*pubsub.GCPClient)
+	clientOf := func(project string) *pubsub.GCPClient {
+		client, ok := clients[project]
+		if !ok {
+			var err error
+			client, err = newPubSubClient(ctx, cfg, logger, project)
+			if err != nil {
+				logger.Fatal("initializing pubsub", err)
+			}
+			clients[project] = client
+		}
+		return client
+	}
+	defer func() {
+		for _, client := range clients {
+			client.Close()
+		}
+	}()
+
+	// topics first, the subscriptions and dead letter policies refer to them
+	for _, topic := range cfg.PubSub.Topics {
+		created, err := clientOf(topic.Project).EnsureTopic(ctx, topic.ID)
+		fields := map[string]interface{}{
+			"project_id": topic.Project,
+			"topic_id":   topic.ID,
+			"created":    created,
+		}
+		if err != nil {
+			fields[log.KeyError] = err.Error()
+			logger.Fatal("provisioning topic", fields)
+		}
+		logger.Info("topic provisioned", fields)
+	}
+
+	for _, subscription := range cfg.PubSub.Subscriptions {
+		created, err := clientOf(subscription.Project).EnsureSubscription(ctx, pubsub.SubscriptionConfig{
+			ID:                  subscription.ID,
+			TopicID:             subscription.TopicID,
+			AckDeadline:         subscription.AckDeadline,
+			RetryMinimumBackoff: subscription.RetryMinimumBackoff,
+			RetryMaximumBackoff: subscription.RetryMaximumBackoff,
+			DeadLetterTopicID:   subscription.DeadLetterTopicID,
+			MaxDeliveryAttempts: subscription.MaxDeliveryAttempts,
+		})
+		fields := map[string]interface{}{
+			"project_id":      subscription.Project,
+			"subscription_id": subscription.ID,
+			"topic_id":        subscription.TopicID,
+			"created":         created,
+		}
+		if err != nil {
+			fields[log.KeyError] = err.Error()
+			logger.Fatal("provisioning subscription", fields)
+		}
+		logger.Info("subscription provisioned", fields)
+	}
+}
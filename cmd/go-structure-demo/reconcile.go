@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/lock"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/notifier"
+	"go-structure-demo/internal/reconcile"
+	"go-structure-demo/internal/repository/postgresrepo"
+	"go-structure-demo/internal/repository/redisrepo"
+	"os"
+	"os/signal"
+	"strings"
+)
+
+// reconcileCommand runs a single reconciliation, it resumes the partial run left by a
+// scheduled one or by a previous command. It refuses to start while another run is in
+// progress, see reconcile.ErrRunning.
+func reconcileCommand(args []string) {
+	if len(args) == 0 || args[0] != "quinyx" {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	cfg := config.Read()
+	flags := flag.NewFlagSet("reconcile quinyx", flag.ExitOnError)
+	dryRun := flags.Bool("dry-run", cfg.Reconcile.DryRun, "report the missing users without creating them")
+	_ = flags.Parse(args[1:])
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	logger, loggerCloser := log.NewZapFromEnv(cfg.AppName)
+	defer loggerCloser()
+
+	metricsClient, metricsCloser, err := metrics.New(cfg.Metrics)
+	if err != nil {
+		logger.Fatal("initializing metrics", err)
+	}
+	defer metricsCloser()
+	log.ReportDrops(logger, metricsClient)
+
+	redisRepo, redisRepoClose, err := redisrepo.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("initializing redis", err)
+	}
+	defer redisRepoClose()
+
+	postgresRepo, postgresRepoCloser, err := postgresrepo.New(cfg)
+	if err != nil {
+		logger.Fatal("initializing postgres", err)
+	}
+	defer postgresRepoCloser()
+
+	notifierClient, notifierCloser, err := notifier.New(cfg.Notifier, cfg.Env, logger)
+	if err != nil {
+		logger.Fatal("initializing notifier", err)
+	}
+	defer notifierCloser()
+
+	controllers := newUserControllers(cfg, logger, metricsClient, redisRepo, postgresRepo, notifierClient)
+	reconciler := reconcile.NewQuinyx(
+		cfg.Reconcile,
+		cfg.Lock,
+		logger,
+		metricsClient,
+		newQuinyxClient(cfg.Quinyx, logger, metricsClient),
+		postgresRepo,
+		controllers.userController,
+		redisRepo,
+		lock.NewLocker(redisRepo.Adapter(), logger),
+	)
+
+	report, err := reconciler.Reconcile(ctx, *dryRun)
+	if errors.Is(err, reconcile.ErrRunning) {
+		logger.Fatal("a quinyx reconciliation is running already, try again once it is over")
+	}
+	printReport(report)
+	if err != nil {
+		logger.Fatal("reconciling quinyx", err)
+	}
+}
+
+func printReport(report reconcile.Report) {
+	if report.DryRun {
+		fmt.Println("dry run, no user was created")
+	}
+	fmt.Printf("pages: %d, employees: %d, matched: %d, skipped: %d\n", report.Pages, report.Employees, report.Matched, report.Skipped)
+	fmt.Printf("missing: %d %s\n", len(report.Missing), strings.Join(report.Missing, " "))
+	fmt.Printf("created: %d %s\n", len(report.Created), strings.Join(report.Created, " "))
+	fmt.Printf("failed: %d %s\n", len(report.Failed), strings.Join(report.Failed, " "))
+	fmt.Printf("drifts: %d\n", len(report.Drifts))
+	for _, drift := range report.Drifts {
+		fmt.Printf("  %s (user %d, employee %s) %s: quinyx %q, user %q\n", drift.Email, drift.UserID, drift.EmployeeID, drift.Field, drift.Quinyx, drift.User)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/controller"
+	"go-structure-demo/internal/delivery/http/httpserver"
+	"go-structure-demo/internal/delivery/pubsub/subscriber"
+	"go-structure-demo/internal/lock"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/notifier"
+	outboxrelay "go-structure-demo/internal/outbox"
+	"go-structure-demo/internal/policy"
+	"go-structure-demo/internal/reconcile"
+	"go-structure-demo/internal/repository/postgresrepo"
+	"go-structure-demo/internal/repository/redisrepo"
+	"go-structure-demo/internal/ridersync"
+	"go-structure-demo/internal/webhook"
+	"os"
+	"os/signal"
+)
+
+func serve() {
+	ctx := context.Background()
+	cfg := config.Read()
+
+	logger, loggerCloser := log.NewZapFromEnv(cfg.AppName)
+	defer loggerCloser()
+
+	metricsClient, metricsCloser, err := metrics.New(cfg.Metrics)
+	if err != nil {
+		logger.Fatal("initializing metrics", err)
+	}
+	defer metricsCloser()
+	log.ReportDrops(logger, metricsClient)
+
+	redisRepo, redisRepoClose, err := redisrepo.New(cfg, logger)
+	if err != nil {
+		logger.Fatal("initializing redis", err)
+	}
+	defer redisRepoClose()
+
+	postgresRepo, postgresRepoCloser, err := postgresrepo.New(cfg)
+	if err != nil {
+		logger.Fatal("initializing postgres", err)
+	}
+	defer postgresRepoCloser()
+
+	pubsubClientA, err := newPubSubClient(ctx, cfg, logger, cfg.PubSub.ProjectA)
+	if err != nil {
+		logger.Fatal("initializing pubsub", err)
+	}
+	defer pubsubClientA.Close()
+	pubsubClientB, err := newPubSubClient(ctx, cfg, logger, cfg.PubSub.ProjectB)
+	if err != nil {
+		logger.Fatal("initializing pubsub", err)
+	}
+	defer pubsubClientB.Close()
+
+	notifierClient, notifierCloser, err := notifier.New(cfg.Notifier, cfg.Env, logger)
+	if err != nil {
+		logger.Fatal("initializing notifier", err)
+	}
+	defer notifierCloser()
+
+	controllers := newUserControllers(cfg, logger, metricsClient, redisRepo, postgresRepo, notifierClient)
+	userStore, authController, userController := controllers.userStore, controllers.authController, controllers.userController
+	apiKeyController := controller.NewAPIKeyController(logger, postgresRepo, policy.New())
+	webhookController := controller.NewWebhookController(logger, postgresRepo, postgresRepo, policy.New())
+	logLevelController := controller.NewLogLevelController(logger, policy.New())
+
+	workerCtx, workerCancel := context.WithCancel(ctx)
+	defer workerCancel()
+	locker := lock.NewLocker(redisRepo.Adapter(), logger)
+	if cfg.Outbox.RunInProcess {
+		relay := outboxrelay.NewRelay(cfg.Outbox, logger, metricsClient, postgresRepo, pubsubClientB)
+		elector := lock.NewElector(cfg.Lock, logger, locker, outboxrelay.LockKey)
+		go elector.Run(workerCtx, relay.Run)
+	}
+	go lock.NewElector(cfg.Lock, logger, locker, ridersync.LockKey).Run(workerCtx, controllers.riderSyncer.Run)
+	go lock.NewElector(cfg.Lock, logger, locker, webhook.LockKey).Run(workerCtx, controllers.dispatcher.Run)
+	if cfg.Reconcile.Interval > 0 {
+		reconciler := reconcile.NewQuinyx(
+			cfg.Reconcile,
+			cfg.Lock,
+			logger,
+			metricsClient,
+			newQuinyxClient(cfg.Quinyx, logger, metricsClient),
+			postgresRepo,
+			userController,
+			redisRepo,
+			locker,
+		)
+		go lock.NewElector(cfg.Lock, logger, locker, reconcile.QuinyxLockKey).Run(workerCtx, reconciler.Run)
+	}
+
+	httpServer := httpserver.New(cfg, logger, metricsClient, redisRepo, postgresRepo, userStore, userController, authController, apiKeyController, webhookController, logLevelController, pubsubClientA, pubsubClientB)
+	go httpServer.Start()
+
+	pubsubClientCloser := subscriber.Subscribe(ctx, cfg, logger, metricsClient, redisRepo, postgresRepo, userController, pubsubClientA, pubsubClientB)
+	defer pubsubClientCloser()
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, os.Interrupt)
+	<-quit
+
+	logger.Info("shutting signal received")
+	workerCancel()
+	c, cancel := context.WithTimeout(context.Background(), cfg.HTTP.GracefulShutdown)
+	defer cancel()
+	httpServer.Shutdown(c)
+	<-c.Done()
+	logger.Info("bye bye")
+}
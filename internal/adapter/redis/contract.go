@@ -7,19 +7,37 @@ import (
 	goredis "github.com/go-redis/redis/v8"
 )
 
+// NoExpiration is the TTL of a key stored without expiration
+const NoExpiration time.Duration = -1
+
 type Adapter interface {
 	Client() *goredis.Client
 	Ping(ctx context.Context) (string, error)
 
 	Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error
 	Forever(ctx context.Context, key string, value interface{}) error
+	// SetNX stores the value only when the key doesn't exist and reports whether it did.
+	SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error)
+
+	// Incr increments the counter of the key, the expiration is only set when the key is created.
+	Incr(ctx context.Context, key string, expiration time.Duration) (int64, error)
+	// CompareAndSwap stores the value only when the key holds old, an empty old means the key
+	// must not exist.
+	CompareAndSwap(ctx context.Context, key string, old string, value string, expiration time.Duration) (bool, error)
 
 	Has(ctx context.Context, key string) bool
-	Get(ctx context.Context, key string) (string, bool)
+	// Get returns false for a missing key, the errors are returned so an outage isn't taken
+	// for a missing key.
+	Get(ctx context.Context, key string) (string, bool, error)
 	TTL(ctx context.Context, key string) (time.Duration, bool)
 
-	Pull(ctx context.Context, key string) (interface{}, bool)
+	// Pull returns false for a missing key, like Get.
+	Pull(ctx context.Context, key string) (interface{}, bool, error)
 	Invalidate(ctx context.Context, tags ...string)
 	Del(ctx context.Context, key string) bool
+	// DelIfEqual deletes the key only when it holds the value.
+	DelIfEqual(ctx context.Context, key string, value string) (bool, error)
+	// ExpireIfEqual resets the expiration of the key only when it holds the value.
+	ExpireIfEqual(ctx context.Context, key string, value string, expiration time.Duration) (bool, error)
 	Flush(ctx context.Context) error
 }
@@ -0,0 +1,386 @@
+package redis
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+const (
+	ModeStandalone = "standalone"
+	ModeSentinel   = "sentinel"
+	ModeCluster    = "cluster"
+
+	tagPrefix = "tag:"
+)
+
+var _ Adapter = (*GoRedis)(nil)
+
+// setScript stores the value and adds the key to the tag sets, a tag set lives as long as
+// its longest living key.
+var setScript = goredis.NewScript(`
+local ttl = tonumber(ARGV[2])
+if ttl > 0 then
+	redis.call('SET', KEYS[1], ARGV[1], 'PX', ttl)
+else
+	redis.call('SET', KEYS[1], ARGV[1])
+end
+for i = 2, #KEYS do
+	local existed = redis.call('EXISTS', KEYS[i])
+	redis.call('SADD', KEYS[i], KEYS[1])
+	if ttl > 0 then
+		local current = redis.call('PTTL', KEYS[i])
+		if existed == 0 or (current >= 0 and current < ttl) then
+			redis.call('PEXPIRE', KEYS[i], ttl)
+		end
+	else
+		redis.call('PERSIST', KEYS[i])
+	end
+end
+return 1
+`)
+
+// invalidateScript deletes the keys of the tag sets and the sets themselves
+var invalidateScript = goredis.NewScript(`
+for i = 1, #KEYS do
+	local keys = redis.call('SMEMBERS', KEYS[i])
+	for j = 1, #keys, 500 do
+		redis.call('DEL', unpack(keys, j, math.min(j + 499, #keys)))
+	end
+	redis.call('DEL', KEYS[i])
+end
+return 1
+`)
+
+// pullScript is a GETDEL that works before redis 6.2
+var pullScript = goredis.NewScript(`
+local value = redis.call('GET', KEYS[1])
+if value then
+	redis.call('DEL', KEYS[1])
+end
+return value
+`)
+
+var delIfEqualScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('DEL', KEYS[1])
+end
+return 0
+`)
+
+var expireIfEqualScript = goredis.NewScript(`
+if redis.call('GET', KEYS[1]) == ARGV[1] then
+	return redis.call('PEXPIRE', KEYS[1], ARGV[2])
+end
+return 0
+`)
+
+var incrScript = goredis.NewScript(`
+local value = redis.call('INCR', KEYS[1])
+if value == 1 and tonumber(ARGV[1]) > 0 then
+	redis.call('PEXPIRE', KEYS[1], ARGV[1])
+end
+return value
+`)
+
+var compareAndSwapScript = goredis.NewScript(`
+local current = redis.call('GET', KEYS[1])
+if (current == false and ARGV[1] == '') or current == ARGV[1] then
+	if tonumber(ARGV[3]) > 0 then
+		redis.call('SET', KEYS[1], ARGV[2], 'PX', ARGV[3])
+	else
+		redis.call('SET', KEYS[1], ARGV[2])
+	end
+	return 1
+end
+return 0
+`)
+
+// GoRedis implements the Adapter on go-redis. In cluster mode the keys of a Set and its tags,
+// and the keys of an Invalidate, are not in the same slot, so both are done in a pipeline
+// instead of a script and are not atomic.
+type GoRedis struct {
+	logger    log.Logger
+	universal goredis.UniversalClient
+	client    *goredis.Client
+	cluster   *goredis.ClusterClient
+}
+
+func New(cfg config.Redis, logger log.Logger) (*GoRedis, error) {
+	var tlsConfig *tls.Config
+	if cfg.TLS.Enabled {
+		tlsConfig = &tls.Config{
+			ServerName:         cfg.TLS.ServerName,
+			InsecureSkipVerify: cfg.TLS.InsecureSkipVerify,
+		}
+	}
+
+	adapter := &GoRedis{logger: logger}
+	switch cfg.Mode {
+	case ModeStandalone, "":
+		if len(cfg.Addrs) != 1 {
+			return nil, fmt.Errorf("redis standalone mode needs exactly one address, got %d", len(cfg.Addrs))
+		}
+		adapter.client = goredis.NewClient(&goredis.Options{
+			Addr:         cfg.Addrs[0],
+			Username:     cfg.Username,
+			Password:     cfg.Password,
+			DB:           cfg.DB,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			PoolTimeout:  cfg.PoolTimeout,
+			TLSConfig:    tlsConfig,
+		})
+		adapter.universal = adapter.client
+	case ModeSentinel:
+		adapter.client = goredis.NewFailoverClient(&goredis.FailoverOptions{
+			MasterName:    cfg.MasterName,
+			SentinelAddrs: cfg.Addrs,
+			Username:      cfg.Username,
+			Password:      cfg.Password,
+			DB:            cfg.DB,
+			PoolSize:      cfg.PoolSize,
+			MinIdleConns:  cfg.MinIdleConns,
+			DialTimeout:   cfg.DialTimeout,
+			ReadTimeout:   cfg.ReadTimeout,
+			WriteTimeout:  cfg.WriteTimeout,
+			PoolTimeout:   cfg.PoolTimeout,
+			TLSConfig:     tlsConfig,
+		})
+		adapter.universal = adapter.client
+	case ModeCluster:
+		adapter.cluster = goredis.NewClusterClient(&goredis.ClusterOptions{
+			Addrs:        cfg.Addrs,
+			Username:     cfg.Username,
+			Password:     cfg.Password,
+			PoolSize:     cfg.PoolSize,
+			MinIdleConns: cfg.MinIdleConns,
+			DialTimeout:  cfg.DialTimeout,
+			ReadTimeout:  cfg.ReadTimeout,
+			WriteTimeout: cfg.WriteTimeout,
+			PoolTimeout:  cfg.PoolTimeout,
+			TLSConfig:    tlsConfig,
+		})
+		adapter.universal = adapter.cluster
+	default:
+		return nil, fmt.Errorf("redis mode %q is not supported", cfg.Mode)
+	}
+
+	return adapter, nil
+}
+
+// Client returns the underlying client, it is nil in cluster mode.
+func (r *GoRedis) Client() *goredis.Client {
+	return r.client
+}
+
+func (r *GoRedis) Close() error {
+	return r.universal.Close()
+}
+
+func (r *GoRedis) Ping(ctx context.Context) (string, error) {
+	return r.universal.Ping(ctx).Result()
+}
+
+func (r *GoRedis) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	if len(tags) == 0 {
+		return r.universal.Set(ctx, key, value, expiration).Err()
+	}
+
+	tagKeys := make([]string, len(tags))
+	for i, tag := range tags {
+		tagKeys[i] = tagPrefix + tag
+	}
+
+	if r.cluster == nil {
+		return setScript.Run(ctx, r.universal, append([]string{key}, tagKeys...), value, milliseconds(expiration)).Err()
+	}
+
+	_, err := r.cluster.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, key, value, expiration)
+		for _, tagKey := range tagKeys {
+			pipe.SAdd(ctx, tagKey, key)
+			if expiration <= 0 {
+				pipe.Persist(ctx, tagKey)
+			}
+		}
+		return nil
+	})
+	if err != nil || expiration <= 0 {
+		return err
+	}
+	// a tag set can outlive some of its keys but never the other way around
+	for _, tagKey := range tagKeys {
+		if ttl, err := r.cluster.PTTL(ctx, tagKey).Result(); err == nil && ttl >= 0 && ttl < expiration {
+			r.cluster.PExpire(ctx, tagKey, expiration)
+		}
+	}
+	return nil
+}
+
+func (r *GoRedis) Forever(ctx context.Context, key string, value interface{}) error {
+	return r.Set(ctx, key, value, 0)
+}
+
+func (r *GoRedis) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	return r.universal.SetNX(ctx, key, value, expiration).Result()
+}
+
+func (r *GoRedis) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	return incrScript.Run(ctx, r.universal, []string{key}, milliseconds(expiration)).Int64()
+}
+
+func (r *GoRedis) CompareAndSwap(ctx context.Context, key string, old string, value string, expiration time.Duration) (bool, error) {
+	swapped, err := compareAndSwapScript.Run(ctx, r.universal, []string{key}, old, value, milliseconds(expiration)).Int64()
+	if err != nil {
+		return false, err
+	}
+	return swapped > 0, nil
+}
+
+func (r *GoRedis) Has(ctx context.Context, key string) bool {
+	count, err := r.universal.Exists(ctx, key).Result()
+	if err != nil {
+		r.logError(ctx, "redis has", key, err)
+		return false
+	}
+	return count > 0
+}
+
+func (r *GoRedis) Get(ctx context.Context, key string) (string, bool, error) {
+	value, err := r.universal.Get(ctx, key).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return value, true, nil
+}
+
+// TTL returns NoExpiration for the keys stored without expiration.
+func (r *GoRedis) TTL(ctx context.Context, key string) (time.Duration, bool) {
+	ttl, err := r.universal.PTTL(ctx, key).Result()
+	if err != nil {
+		r.logError(ctx, "redis ttl", key, err)
+		return 0, false
+	}
+	switch ttl {
+	case -2:
+		return 0, false
+	case -1:
+		return NoExpiration, true
+	}
+	return ttl, true
+}
+
+// Pull gets and deletes the key in a single round trip, the value is a string.
+func (r *GoRedis) Pull(ctx context.Context, key string) (interface{}, bool, error) {
+	value, err := pullScript.Run(ctx, r.universal, []string{key}).Result()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (r *GoRedis) Invalidate(ctx context.Context, tags ...string) {
+	if len(tags) == 0 {
+		return
+	}
+
+	tagKeys := make([]string, len(tags))
+	for i, tag := range tags {
+		tagKeys[i] = tagPrefix + tag
+	}
+
+	if r.cluster == nil {
+		if err := invalidateScript.Run(ctx, r.universal, tagKeys).Err(); err != nil && !errors.Is(err, goredis.Nil) {
+			r.logError(ctx, "redis invalidate", tagKeys, err)
+		}
+		return
+	}
+
+	for _, tagKey := range tagKeys {
+		keys, err := r.cluster.SMembers(ctx, tagKey).Result()
+		if err != nil {
+			r.logError(ctx, "redis invalidate", tagKey, err)
+			continue
+		}
+		_, err = r.cluster.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for _, key := range keys {
+				pipe.Del(ctx, key)
+			}
+			pipe.Del(ctx, tagKey)
+			return nil
+		})
+		if err != nil {
+			r.logError(ctx, "redis invalidate", tagKey, err)
+		}
+	}
+}
+
+func (r *GoRedis) Del(ctx context.Context, key string) bool {
+	count, err := r.universal.Del(ctx, key).Result()
+	if err != nil {
+		r.logError(ctx, "redis del", key, err)
+		return false
+	}
+	return count > 0
+}
+
+func (r *GoRedis) DelIfEqual(ctx context.Context, key string, value string) (bool, error) {
+	deleted, err := delIfEqualScript.Run(ctx, r.universal, []string{key}, value).Int64()
+	if err != nil {
+		return false, err
+	}
+	return deleted > 0, nil
+}
+
+func (r *GoRedis) ExpireIfEqual(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	updated, err := expireIfEqualScript.Run(ctx, r.universal, []string{key}, value, milliseconds(expiration)).Int64()
+	if err != nil {
+		return false, err
+	}
+	return updated > 0, nil
+}
+
+func (r *GoRedis) Flush(ctx context.Context) error {
+	if r.cluster != nil {
+		return r.cluster.ForEachMaster(ctx, func(ctx context.Context, client *goredis.Client) error {
+			return client.FlushDB(ctx).Err()
+		})
+	}
+	return r.universal.FlushDB(ctx).Err()
+}
+
+// logError logs everything but the missing keys
+func (r *GoRedis) logError(ctx context.Context, msg string, key interface{}, err error) {
+	if errors.Is(err, goredis.Nil) {
+		return
+	}
+	r.logger.ErrorWithContext(ctx, msg, map[string]interface{}{
+		"key":        key,
+		log.KeyError: err.Error(),
+	})
+}
+
+// milliseconds rounds a positive ttl up to a whole millisecond, the scripts take the 0 that
+// Milliseconds gives for a shorter one as no expiration.
+func milliseconds(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return ttl.Milliseconds()
+	}
+	return int64((ttl + time.Millisecond - 1) / time.Millisecond)
+}
@@ -0,0 +1,27 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMilliseconds(t *testing.T) {
+	tests := []struct {
+		name   string
+		ttl    time.Duration
+		should int64
+	}{
+		{name: "no expiration", ttl: 0, should: 0},
+		{name: "negative", ttl: -time.Second, should: -1000},
+		{name: "below a millisecond", ttl: time.Microsecond, should: 1},
+		{name: "whole milliseconds", ttl: 2 * time.Second, should: 2000},
+		{name: "partial millisecond", ttl: 1500 * time.Microsecond, should: 2},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.should, milliseconds(test.ttl))
+		})
+	}
+}
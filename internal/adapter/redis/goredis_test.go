@@ -0,0 +1,73 @@
+package redis_test
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/adapter/redis"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+// redisAddr returns REDIS_TEST_ADDR, which is flushed by the tests, or starts a throwaway
+// redis-server when one is installed.
+func redisAddr(t *testing.T) string {
+	if addr := os.Getenv("REDIS_TEST_ADDR"); addr != "" {
+		return addr
+	}
+
+	binary, err := exec.LookPath("redis-server")
+	if err != nil {
+		t.Skip("neither REDIS_TEST_ADDR nor redis-server is available")
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	cmd := exec.Command(binary, "--port", fmt.Sprint(port), "--save", "", "--appendonly", "no")
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	addr := fmt.Sprintf("127.0.0.1:%d", port)
+	for i := 0; i < 50; i++ {
+		if conn, err := net.Dial("tcp", addr); err == nil {
+			_ = conn.Close()
+			return addr
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatal("redis-server did not start")
+	return ""
+}
+
+func TestGoRedis(t *testing.T) {
+	adapter, err := redis.New(config.Redis{
+		Mode:  redis.ModeStandalone,
+		Addrs: []string{redisAddr(t)},
+	}, log.NewMock("redis"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = adapter.Close() }()
+
+	redistest.RunAdapterSuite(t, func(t *testing.T) redis.Adapter {
+		if err := adapter.Flush(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		return adapter
+	}, time.Sleep)
+}
@@ -0,0 +1,284 @@
+package redistest
+
+import (
+	"context"
+	"encoding"
+	"fmt"
+	"go-structure-demo/internal/adapter/redis"
+	"strconv"
+	"sync"
+	"time"
+
+	goredis "github.com/go-redis/redis/v8"
+)
+
+var _ redis.Adapter = (*Memory)(nil)
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// Memory is an in-memory Adapter with the semantics of the go-redis one, the clock is
+// injected so the expirations can be tested without waiting.
+type Memory struct {
+	mu   sync.Mutex
+	now  func() time.Time
+	data map[string]entry
+	tags map[string]map[string]struct{}
+}
+
+// NewMemory returns an empty adapter, now defaults to time.Now.
+func NewMemory(now func() time.Time) *Memory {
+	if now == nil {
+		now = time.Now
+	}
+	return &Memory{
+		now:  now,
+		data: make(map[string]entry),
+		tags: make(map[string]map[string]struct{}),
+	}
+}
+
+// Client returns nil, there is no server behind the adapter.
+func (m *Memory) Client() *goredis.Client {
+	return nil
+}
+
+func (m *Memory) Ping(ctx context.Context) (string, error) {
+	return "PONG", nil
+}
+
+func (m *Memory) Set(ctx context.Context, key string, value interface{}, expiration time.Duration, tags ...string) error {
+	str, err := stringify(value)
+	if err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := entry{value: str}
+	if expiration > 0 {
+		e.expiresAt = m.now().Add(expiration)
+	}
+	m.data[key] = e
+
+	for _, tag := range tags {
+		if _, ok := m.tags[tag]; !ok {
+			m.tags[tag] = make(map[string]struct{})
+		}
+		m.tags[tag][key] = struct{}{}
+	}
+	return nil
+}
+
+func (m *Memory) Forever(ctx context.Context, key string, value interface{}) error {
+	return m.Set(ctx, key, value, 0)
+}
+
+func (m *Memory) SetNX(ctx context.Context, key string, value interface{}, expiration time.Duration) (bool, error) {
+	str, err := stringify(value)
+	if err != nil {
+		return false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.get(key); ok {
+		return false, nil
+	}
+	e := entry{value: str}
+	if expiration > 0 {
+		e.expiresAt = m.now().Add(expiration)
+	}
+	m.data[key] = e
+	return true, nil
+}
+
+func (m *Memory) Incr(ctx context.Context, key string, expiration time.Duration) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key)
+	if !ok {
+		e = entry{value: "0"}
+		if expiration > 0 {
+			e.expiresAt = m.now().Add(expiration)
+		}
+	}
+	value, err := strconv.ParseInt(e.value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("ERR value is not an integer or out of range")
+	}
+	value++
+	e.value = strconv.FormatInt(value, 10)
+	m.data[key] = e
+	return value, nil
+}
+
+func (m *Memory) CompareAndSwap(ctx context.Context, key string, old string, value string, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key)
+	if (!ok && old != "") || (ok && e.value != old) {
+		return false, nil
+	}
+	e = entry{value: value}
+	if expiration > 0 {
+		e.expiresAt = m.now().Add(expiration)
+	}
+	m.data[key] = e
+	return true, nil
+}
+
+func (m *Memory) Has(ctx context.Context, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.get(key)
+	return ok
+}
+
+func (m *Memory) Get(ctx context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key)
+	return e.value, ok, nil
+}
+
+func (m *Memory) TTL(ctx context.Context, key string) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key)
+	if !ok {
+		return 0, false
+	}
+	if e.expiresAt.IsZero() {
+		return redis.NoExpiration, true
+	}
+	return e.expiresAt.Sub(m.now()), true
+}
+
+func (m *Memory) Pull(ctx context.Context, key string) (interface{}, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key)
+	if !ok {
+		return nil, false, nil
+	}
+	delete(m.data, key)
+	return e.value, true, nil
+}
+
+func (m *Memory) Invalidate(ctx context.Context, tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, tag := range tags {
+		for key := range m.tags[tag] {
+			delete(m.data, key)
+		}
+		delete(m.tags, tag)
+	}
+}
+
+func (m *Memory) Del(ctx context.Context, key string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	_, ok := m.get(key)
+	delete(m.data, key)
+	return ok
+}
+
+func (m *Memory) DelIfEqual(ctx context.Context, key string, value string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key)
+	if !ok || e.value != value {
+		return false, nil
+	}
+	delete(m.data, key)
+	return true, nil
+}
+
+func (m *Memory) ExpireIfEqual(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.get(key)
+	if !ok || e.value != value {
+		return false, nil
+	}
+	e.expiresAt = m.now().Add(expiration)
+	m.data[key] = e
+	return true, nil
+}
+
+func (m *Memory) Flush(ctx context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data = make(map[string]entry)
+	m.tags = make(map[string]map[string]struct{})
+	return nil
+}
+
+// get returns the live entry of the key and drops it when expired, the lock must be held.
+func (m *Memory) get(key string) (entry, bool) {
+	e, ok := m.data[key]
+	if !ok {
+		return entry{}, false
+	}
+	if !e.expiresAt.IsZero() && !m.now().Before(e.expiresAt) {
+		delete(m.data, key)
+		return entry{}, false
+	}
+	return e, true
+}
+
+// stringify encodes the value the way go-redis writes the command arguments.
+func stringify(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case []byte:
+		return string(v), nil
+	case int:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int8:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int16:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int32:
+		return strconv.FormatInt(int64(v), 10), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case uint:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint8:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint16:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint32:
+		return strconv.FormatUint(uint64(v), 10), nil
+	case uint64:
+		return strconv.FormatUint(v, 10), nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'f', -1, 64), nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case time.Time:
+		return v.Format(time.RFC3339Nano), nil
+	case encoding.BinaryMarshaler:
+		b, err := v.MarshalBinary()
+		if err != nil {
+			return "", err
+		}
+		return string(b), nil
+	default:
+		return "", fmt.Errorf("redis: can't marshal %T (implement encoding.BinaryMarshaler)", value)
+	}
+}
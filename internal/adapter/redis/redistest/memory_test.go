@@ -0,0 +1,14 @@
+package redistest
+
+import (
+	"go-structure-demo/internal/adapter/redis"
+	"testing"
+	"time"
+)
+
+func TestMemory(t *testing.T) {
+	clock := NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	RunAdapterSuite(t, func(t *testing.T) redis.Adapter {
+		return NewMemory(clock.Now)
+	}, clock.Advance)
+}
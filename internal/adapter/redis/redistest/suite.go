@@ -0,0 +1,236 @@
+package redistest
+
+import (
+	"context"
+	"go-structure-demo/internal/adapter/redis"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// RunAdapterSuite checks the behaviour every Adapter must share. newAdapter returns an empty
+// adapter for each test and advance moves its clock forward, it sleeps for a real server.
+func RunAdapterSuite(t *testing.T, newAdapter func(t *testing.T) redis.Adapter, advance func(d time.Duration)) {
+	ctx := context.Background()
+	ttl := 200 * time.Millisecond
+
+	t.Run("ping", func(t *testing.T) {
+		adapter := newAdapter(t)
+		pong, err := adapter.Ping(ctx)
+		assert.Nil(t, err)
+		assert.Equal(t, "PONG", pong)
+	})
+
+	t.Run("set_and_get", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", time.Minute))
+		assert.Nil(t, adapter.Set(ctx, "number", 15, time.Minute))
+		value, ok, err := adapter.Get(ctx, "key")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "value", value)
+		value, ok, err = adapter.Get(ctx, "number")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "15", value)
+	})
+
+	t.Run("set_unsupported_value", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.NotNil(t, adapter.Set(ctx, "key", struct{}{}, time.Minute))
+		assert.False(t, adapter.Has(ctx, "key"))
+	})
+
+	t.Run("missing_key", func(t *testing.T) {
+		adapter := newAdapter(t)
+		value, ok, err := adapter.Get(ctx, "missing")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Equal(t, "", value)
+		assert.False(t, adapter.Has(ctx, "missing"))
+		_, ok = adapter.TTL(ctx, "missing")
+		assert.False(t, ok)
+		assert.False(t, adapter.Del(ctx, "missing"))
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", time.Minute))
+		remaining, ok := adapter.TTL(ctx, "key")
+		assert.True(t, ok)
+		assert.True(t, remaining > 0 && remaining <= time.Minute)
+
+		assert.Nil(t, adapter.Forever(ctx, "forever", "value"))
+		remaining, ok = adapter.TTL(ctx, "forever")
+		assert.True(t, ok)
+		assert.Equal(t, redis.NoExpiration, remaining)
+	})
+
+	t.Run("expiration", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", ttl))
+		assert.Nil(t, adapter.Forever(ctx, "forever", "value"))
+		assert.True(t, adapter.Has(ctx, "key"))
+
+		advance(ttl + 100*time.Millisecond)
+
+		assert.False(t, adapter.Has(ctx, "key"))
+		_, ok, err := adapter.Get(ctx, "key")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		_, ok = adapter.TTL(ctx, "key")
+		assert.False(t, ok)
+		_, ok, err = adapter.Pull(ctx, "key")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.True(t, adapter.Has(ctx, "forever"))
+	})
+
+	t.Run("pull", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", time.Minute))
+		value, ok, err := adapter.Pull(ctx, "key")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, "value", value)
+		value, ok, err = adapter.Pull(ctx, "key")
+		assert.Nil(t, err)
+		assert.False(t, ok)
+		assert.Nil(t, value)
+	})
+
+	t.Run("del", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", time.Minute))
+		assert.True(t, adapter.Del(ctx, "key"))
+		assert.False(t, adapter.Has(ctx, "key"))
+	})
+
+	t.Run("set_nx", func(t *testing.T) {
+		adapter := newAdapter(t)
+		stored, err := adapter.SetNX(ctx, "key", "first", ttl)
+		assert.Nil(t, err)
+		assert.True(t, stored)
+		stored, err = adapter.SetNX(ctx, "key", "second", ttl)
+		assert.Nil(t, err)
+		assert.False(t, stored)
+		value, _, _ := adapter.Get(ctx, "key")
+		assert.Equal(t, "first", value)
+
+		advance(ttl + 100*time.Millisecond)
+
+		stored, err = adapter.SetNX(ctx, "key", "second", ttl)
+		assert.Nil(t, err)
+		assert.True(t, stored)
+	})
+
+	t.Run("del_if_equal", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", time.Minute))
+		deleted, err := adapter.DelIfEqual(ctx, "key", "other")
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+		assert.True(t, adapter.Has(ctx, "key"))
+		deleted, err = adapter.DelIfEqual(ctx, "key", "value")
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+		assert.False(t, adapter.Has(ctx, "key"))
+		deleted, err = adapter.DelIfEqual(ctx, "key", "value")
+		assert.Nil(t, err)
+		assert.False(t, deleted)
+	})
+
+	t.Run("expire_if_equal", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", ttl))
+		updated, err := adapter.ExpireIfEqual(ctx, "key", "other", time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, updated)
+		updated, err = adapter.ExpireIfEqual(ctx, "key", "value", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, updated)
+
+		advance(ttl + 100*time.Millisecond)
+
+		assert.True(t, adapter.Has(ctx, "key"))
+		updated, err = adapter.ExpireIfEqual(ctx, "missing", "value", time.Minute)
+		assert.Nil(t, err)
+		assert.False(t, updated)
+	})
+
+	t.Run("incr", func(t *testing.T) {
+		adapter := newAdapter(t)
+		for i := int64(1); i <= 3; i++ {
+			value, err := adapter.Incr(ctx, "counter", ttl)
+			assert.Nil(t, err)
+			assert.Equal(t, i, value)
+		}
+
+		advance(ttl + 100*time.Millisecond)
+
+		value, err := adapter.Incr(ctx, "counter", ttl)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), value)
+
+		assert.Nil(t, adapter.Set(ctx, "text", "value", time.Minute))
+		_, err = adapter.Incr(ctx, "text", ttl)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("compare_and_swap", func(t *testing.T) {
+		adapter := newAdapter(t)
+		swapped, err := adapter.CompareAndSwap(ctx, "key", "", "first", ttl)
+		assert.Nil(t, err)
+		assert.True(t, swapped)
+		swapped, err = adapter.CompareAndSwap(ctx, "key", "", "second", ttl)
+		assert.Nil(t, err)
+		assert.False(t, swapped)
+		swapped, err = adapter.CompareAndSwap(ctx, "key", "other", "second", ttl)
+		assert.Nil(t, err)
+		assert.False(t, swapped)
+		swapped, err = adapter.CompareAndSwap(ctx, "key", "first", "second", time.Minute)
+		assert.Nil(t, err)
+		assert.True(t, swapped)
+
+		advance(ttl + 100*time.Millisecond)
+
+		value, _, _ := adapter.Get(ctx, "key")
+		assert.Equal(t, "second", value)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "user:1", "1", time.Minute, "user:1", "users"))
+		assert.Nil(t, adapter.Set(ctx, "user:1:email", "1", time.Minute, "user:1"))
+		assert.Nil(t, adapter.Set(ctx, "user:2", "2", time.Minute, "user:2", "users"))
+		assert.Nil(t, adapter.Forever(ctx, "untagged", "value"))
+
+		adapter.Invalidate(ctx, "user:1")
+		assert.False(t, adapter.Has(ctx, "user:1"))
+		assert.False(t, adapter.Has(ctx, "user:1:email"))
+		assert.True(t, adapter.Has(ctx, "user:2"))
+
+		adapter.Invalidate(ctx, "users", "unknown")
+		assert.False(t, adapter.Has(ctx, "user:2"))
+		assert.True(t, adapter.Has(ctx, "untagged"))
+	})
+
+	t.Run("tags_outlive_keys", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "short", "value", ttl, "tag"))
+		assert.Nil(t, adapter.Set(ctx, "long", "value", time.Minute, "tag"))
+
+		advance(ttl + 100*time.Millisecond)
+
+		adapter.Invalidate(ctx, "tag")
+		assert.False(t, adapter.Has(ctx, "long"))
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		adapter := newAdapter(t)
+		assert.Nil(t, adapter.Set(ctx, "key", "value", time.Minute, "tag"))
+		assert.Nil(t, adapter.Flush(ctx))
+		assert.False(t, adapter.Has(ctx, "key"))
+	})
+}
@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// APIKeyPrefix marks the api keys, so a leaked one is easy to recognize.
+const APIKeyPrefix = "gsd_"
+
+// NewAPIKey returns a random api key, the prefix to show next to it and the hash to store.
+func NewAPIKey() (key string, prefix string, hash string, err error) {
+	token, err := NewToken()
+	if err != nil {
+		return "", "", "", err
+	}
+	key = APIKeyPrefix + token
+	return key, key[:len(APIKeyPrefix)+8], HashAPIKey(key), nil
+}
+
+// HashAPIKey is a plain sha256, the keys are random enough not to need a slow hash.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
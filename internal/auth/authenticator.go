@@ -0,0 +1,96 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"strings"
+	"time"
+)
+
+var ErrInvalidToken = errors.New("invalid token")
+
+// Authenticator resolves the bearer tokens, the signed JWTs are verified with the configured
+// keys and the opaque session tokens are looked up in the TokenStore. The role of a session
+// comes from its user, so a deleted user loses the sessions as well. The api keys are looked
+// up by their hash in the APIKeyStore.
+type Authenticator struct {
+	cfg         config.Auth
+	verifier    *JWTVerifier
+	tokenStore  contract.TokenStore
+	userStore   contract.UserStore
+	apiKeyStore contract.APIKeyStore
+	now         func() time.Time
+}
+
+func NewAuthenticator(
+	cfg config.Auth,
+	tokenStore contract.TokenStore,
+	userStore contract.UserStore,
+	apiKeyStore contract.APIKeyStore,
+) (*Authenticator, error) {
+	verifier, err := NewJWTVerifier(cfg.JWT)
+	if err != nil {
+		return nil, err
+	}
+	return &Authenticator{
+		cfg:         cfg,
+		verifier:    verifier,
+		tokenStore:  tokenStore,
+		userStore:   userStore,
+		apiKeyStore: apiKeyStore,
+		now:         time.Now,
+	}, nil
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, token string) (Principal, error) {
+	if token == "" {
+		return Principal{}, ErrInvalidToken
+	}
+	if strings.Count(token, ".") == 2 {
+		return a.verifier.Verify(token, a.now())
+	}
+
+	userID, ok, err := a.tokenStore.GetToken(ctx, entity.TokenPurposeSession, token)
+	if err != nil {
+		return Principal{}, err
+	}
+	if !ok {
+		return Principal{}, ErrInvalidToken
+	}
+	user, err := a.userStore.GetUserByID(ctx, userID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return Principal{}, ErrInvalidToken
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+	return NewUserPrincipal(user.ID, user.Role, a.cfg.SessionScopes, MethodSession), nil
+}
+
+// AuthenticateAPIKey rejects the revoked and expired keys. The last used time is written at
+// most once per APIKeyTouchInterval and is best effort, it never fails the request.
+func (a *Authenticator) AuthenticateAPIKey(ctx context.Context, key string) (Principal, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return Principal{}, ErrInvalidToken
+	}
+	apiKey, err := a.apiKeyStore.GetAPIKeyByHash(ctx, HashAPIKey(key))
+	if apperror.Is(err, apperror.KindNotFound) {
+		return Principal{}, ErrInvalidToken
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	now := a.now()
+	if !apiKey.IsActive(now) {
+		return Principal{}, ErrInvalidToken
+	}
+	if apiKey.LastUsedAt == nil || now.Sub(*apiKey.LastUsedAt) >= a.cfg.APIKeyTouchInterval {
+		_ = a.apiKeyStore.TouchAPIKey(ctx, apiKey.ID, now)
+	}
+	return NewAPIKeyPrincipal(apiKey), nil
+}
@@ -0,0 +1,159 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/repository/redisrepo"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type userStore map[uint]entity.User
+
+func (s userStore) CreateUser(ctx context.Context, createUserRequest *param.CreateUserRequest) (entity.User, error) {
+	return entity.User{}, nil
+}
+
+func (s userStore) GetUserByID(ctx context.Context, id uint) (entity.User, error) {
+	user, ok := s[id]
+	if !ok {
+		return entity.User{}, apperror.NotFound("user not found")
+	}
+	return user, nil
+}
+
+func (s userStore) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	return entity.User{}, apperror.NotFound("user not found")
+}
+
+func (s userStore) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	return user, nil
+}
+
+func (s userStore) DeleteUser(ctx context.Context, id uint) error {
+	return nil
+}
+
+type apiKeyStore map[string]*entity.APIKey
+
+func (s apiKeyStore) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	s[key.Hash] = &key
+	return key, nil
+}
+
+func (s apiKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (entity.APIKey, error) {
+	key, ok := s[hash]
+	if !ok {
+		return entity.APIKey{}, apperror.NotFound("api key not found")
+	}
+	return *key, nil
+}
+
+func (s apiKeyStore) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	return nil, nil
+}
+
+func (s apiKeyStore) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error {
+	return nil
+}
+
+func (s apiKeyStore) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	for _, key := range s {
+		if key.ID == id {
+			key.LastUsedAt = &usedAt
+		}
+	}
+	return nil
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	tokenStore := redisrepo.NewWithAdapter(redistest.NewMemory(nil))
+	assert.Nil(t, tokenStore.SetToken(ctx, entity.TokenPurposeSession, "session-token", 42, time.Hour))
+	assert.Nil(t, tokenStore.SetToken(ctx, entity.TokenPurposeSession, "deleted-user-token", 43, time.Hour))
+	assert.Nil(t, tokenStore.SetToken(ctx, entity.TokenPurposeMagicLogin, "login-token", 42, time.Hour))
+	users := userStore{42: {ID: 42, Role: entity.RoleAdmin}}
+
+	authenticator, err := NewAuthenticator(config.Auth{SessionScopes: []string{"user"}}, tokenStore, users, apiKeyStore{})
+	assert.Nil(t, err)
+
+	principal, err := authenticator.Authenticate(ctx, "session-token")
+	assert.Nil(t, err)
+	assert.Equal(t, NewUserPrincipal(42, entity.RoleAdmin, []string{"user"}, MethodSession), principal)
+
+	// the session stays valid, only one-time tokens are consumed
+	_, err = authenticator.Authenticate(ctx, "session-token")
+	assert.Nil(t, err)
+
+	for _, token := range []string{"", "login-token", "deleted-user-token", "unknown", "a.b.c"} {
+		_, err = authenticator.Authenticate(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidToken, token)
+	}
+}
+
+// downTokenStore fails like a token store whose redis is unreachable.
+type downTokenStore struct {
+	contract.TokenStore
+}
+
+func (s downTokenStore) GetToken(ctx context.Context, purpose entity.TokenPurpose, token string) (uint, bool, error) {
+	return 0, false, errors.New("connection refused")
+}
+
+func TestAuthenticator_Authenticate_StoreDown(t *testing.T) {
+	authenticator, err := NewAuthenticator(config.Auth{}, downTokenStore{}, userStore{}, apiKeyStore{})
+	assert.Nil(t, err)
+
+	// the outage is not taken for an invalid token, the middleware answers 503 for it
+	_, err = authenticator.Authenticate(context.Background(), "session-token")
+	assert.NotNil(t, err)
+	assert.False(t, errors.Is(err, ErrInvalidToken))
+}
+
+func TestAuthenticator_AuthenticateAPIKey(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	past, future := now.Add(-time.Hour), now.Add(time.Hour)
+
+	keys := apiKeyStore{}
+	newKey := func(apiKey entity.APIKey) string {
+		key, prefix, hash, err := NewAPIKey()
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(key, prefix))
+		apiKey.Prefix, apiKey.Hash = prefix, hash
+		_, _ = keys.CreateAPIKey(ctx, apiKey)
+		return key
+	}
+	active := newKey(entity.APIKey{ID: 1, Scopes: []string{"user:create"}, ExpiresAt: &future})
+	expired := newKey(entity.APIKey{ID: 2, ExpiresAt: &past})
+	revoked := newKey(entity.APIKey{ID: 3, RevokedAt: &past})
+
+	authenticator, err := NewAuthenticator(config.Auth{APIKeyTouchInterval: time.Minute}, nil, userStore{}, keys)
+	assert.Nil(t, err)
+	authenticator.now = func() time.Time { return now }
+
+	principal, err := authenticator.AuthenticateAPIKey(ctx, active)
+	assert.Nil(t, err)
+	assert.Equal(t, Principal{Subject: "api_key:1", Role: RoleService, Scopes: []string{"user:create"}, Method: MethodAPIKey}, principal)
+	assert.Equal(t, now, *keys[HashAPIKey(active)].LastUsedAt)
+
+	// the last used time is not written on every request
+	authenticator.now = func() time.Time { return now.Add(30 * time.Second) }
+	_, err = authenticator.AuthenticateAPIKey(ctx, active)
+	assert.Nil(t, err)
+	assert.Equal(t, now, *keys[HashAPIKey(active)].LastUsedAt)
+
+	for _, key := range []string{"", "gsd_unknown", expired, revoked, strings.TrimPrefix(active, APIKeyPrefix)} {
+		_, err = authenticator.AuthenticateAPIKey(ctx, key)
+		assert.ErrorIs(t, err, ErrInvalidToken, key)
+	}
+}
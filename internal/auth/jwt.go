@@ -0,0 +1,223 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"go-structure-demo/internal/config"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+type jwtKey struct {
+	algorithm string
+	secret    []byte
+	publicKey *rsa.PublicKey
+}
+
+type jwtHeader struct {
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string   `json:"sub"`
+	Issuer    string   `json:"iss"`
+	Audience  audience `json:"aud"`
+	ExpiresAt *int64   `json:"exp"`
+	NotBefore *int64   `json:"nbf"`
+	Scope     string   `json:"scope"`
+	Role      string   `json:"role"`
+}
+
+// audience is either a string or a list of strings
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*a = list
+	return nil
+}
+
+func (a audience) contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// JWTVerifier verifies the HS256 and RS256 signed tokens with the configured keys, the
+// key is picked by the kid header and its algorithm must match the alg header.
+type JWTVerifier struct {
+	cfg  config.JWT
+	keys map[string]jwtKey
+}
+
+func NewJWTVerifier(cfg config.JWT) (*JWTVerifier, error) {
+	keys := make(map[string]jwtKey, len(cfg.Keys))
+	for _, key := range cfg.Keys {
+		switch key.Algorithm {
+		case AlgorithmHS256:
+			if key.Secret == "" {
+				return nil, fmt.Errorf("jwt key %q has no secret", key.ID)
+			}
+			keys[key.ID] = jwtKey{algorithm: key.Algorithm, secret: []byte(key.Secret)}
+		case AlgorithmRS256:
+			publicKey, err := parseRSAPublicKey(key.PublicKey)
+			if err != nil {
+				return nil, fmt.Errorf("jwt key %q: %w", key.ID, err)
+			}
+			keys[key.ID] = jwtKey{algorithm: key.Algorithm, publicKey: publicKey}
+		default:
+			return nil, fmt.Errorf("jwt key %q has the unsupported algorithm %q", key.ID, key.Algorithm)
+		}
+	}
+	return &JWTVerifier{cfg: cfg, keys: keys}, nil
+}
+
+func (v *JWTVerifier) Verify(token string, now time.Time) (Principal, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return Principal{}, fmt.Errorf("%w: malformed jwt", ErrInvalidToken)
+	}
+
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed jwt header", ErrInvalidToken)
+	}
+	key, err := v.key(header.KeyID)
+	if err != nil {
+		return Principal{}, err
+	}
+	if header.Algorithm != key.algorithm {
+		return Principal{}, fmt.Errorf("%w: unexpected algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed jwt signature", ErrInvalidToken)
+	}
+	if !key.verify(parts[0]+"."+parts[1], signature) {
+		return Principal{}, fmt.Errorf("%w: invalid signature", ErrInvalidToken)
+	}
+
+	var claims jwtClaims
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return Principal{}, fmt.Errorf("%w: malformed jwt claims", ErrInvalidToken)
+	}
+	if err := v.validate(claims, now); err != nil {
+		return Principal{}, err
+	}
+
+	scopes := strings.Fields(claims.Scope)
+	if userID, err := strconv.ParseUint(claims.Subject, 10, 64); err == nil {
+		return NewUserPrincipal(uint(userID), claims.Role, scopes, MethodJWT), nil
+	}
+	return Principal{Subject: claims.Subject, Role: claims.Role, Scopes: scopes, Method: MethodJWT}, nil
+}
+
+// key returns the key of the kid, a token without kid is accepted when there is a single key.
+func (v *JWTVerifier) key(id string) (jwtKey, error) {
+	if id == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, nil
+		}
+	}
+	key, ok := v.keys[id]
+	if !ok {
+		return jwtKey{}, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, id)
+	}
+	return key, nil
+}
+
+func (v *JWTVerifier) validate(claims jwtClaims, now time.Time) error {
+	if claims.Subject == "" {
+		return fmt.Errorf("%w: missing subject", ErrInvalidToken)
+	}
+	if claims.ExpiresAt == nil || now.After(time.Unix(*claims.ExpiresAt, 0).Add(v.cfg.Leeway)) {
+		return fmt.Errorf("%w: expired", ErrInvalidToken)
+	}
+	if claims.NotBefore != nil && now.Add(v.cfg.Leeway).Before(time.Unix(*claims.NotBefore, 0)) {
+		return fmt.Errorf("%w: not valid yet", ErrInvalidToken)
+	}
+	if v.cfg.Issuer != "" && claims.Issuer != v.cfg.Issuer {
+		return fmt.Errorf("%w: unexpected issuer", ErrInvalidToken)
+	}
+	if v.cfg.Audience != "" && !claims.Audience.contains(v.cfg.Audience) {
+		return fmt.Errorf("%w: unexpected audience", ErrInvalidToken)
+	}
+	return nil
+}
+
+func (k jwtKey) verify(signingInput string, signature []byte) bool {
+	switch k.algorithm {
+	case AlgorithmHS256:
+		mac := hmac.New(sha256.New, k.secret)
+		mac.Write([]byte(signingInput))
+		return hmac.Equal(mac.Sum(nil), signature)
+	case AlgorithmRS256:
+		digest := sha256.Sum256([]byte(signingInput))
+		return rsa.VerifyPKCS1v15(k.publicKey, crypto.SHA256, digest[:], signature) == nil
+	}
+	return false
+}
+
+func decodeSegment(segment string, v interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, v)
+}
+
+func parseRSAPublicKey(data string) (*rsa.PublicKey, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("public key is not pem encoded")
+	}
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		certificate, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if publicKey, ok := certificate.PublicKey.(*rsa.PublicKey); ok {
+			return publicKey, nil
+		}
+	default:
+		parsed, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		if publicKey, ok := parsed.(*rsa.PublicKey); ok {
+			return publicKey, nil
+		}
+	}
+	return nil, errors.New("public key is not an rsa key")
+}
@@ -0,0 +1,195 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"go-structure-demo/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func signJWT(t *testing.T, header map[string]interface{}, claims map[string]interface{}, sign func(input string) []byte) string {
+	headerJSON, err := json.Marshal(header)
+	assert.Nil(t, err)
+	claimsJSON, err := json.Marshal(claims)
+	assert.Nil(t, err)
+	input := base64.RawURLEncoding.EncodeToString(headerJSON) + "." + base64.RawURLEncoding.EncodeToString(claimsJSON)
+	return input + "." + base64.RawURLEncoding.EncodeToString(sign(input))
+}
+
+func hs256(secret string) func(input string) []byte {
+	return func(input string) []byte {
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(input))
+		return mac.Sum(nil)
+	}
+}
+
+func rs256(key *rsa.PrivateKey) func(input string) []byte {
+	return func(input string) []byte {
+		digest := sha256.Sum256([]byte(input))
+		signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		return signature
+	}
+}
+
+func TestJWTVerifier_Verify(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.Nil(t, err)
+	publicKey, err := x509.MarshalPKIXPublicKey(&rsaKey.PublicKey)
+	assert.Nil(t, err)
+
+	verifier, err := NewJWTVerifier(config.JWT{
+		Issuer:   "issuer",
+		Audience: "audience",
+		Leeway:   time.Minute,
+		Keys: []config.JWTKey{
+			{ID: "old", Algorithm: AlgorithmHS256, Secret: "old-secret"},
+			{ID: "new", Algorithm: AlgorithmHS256, Secret: "new-secret"},
+			{ID: "rsa", Algorithm: AlgorithmRS256, PublicKey: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey}))},
+		},
+	})
+	assert.Nil(t, err)
+
+	claims := func(changes map[string]interface{}) map[string]interface{} {
+		c := map[string]interface{}{
+			"sub":   "42",
+			"iss":   "issuer",
+			"aud":   []string{"audience", "other"},
+			"exp":   now.Add(time.Hour).Unix(),
+			"scope": "user:read user:update",
+			"role":  "user",
+		}
+		for k, v := range changes {
+			if v == nil {
+				delete(c, k)
+				continue
+			}
+			c[k] = v
+		}
+		return c
+	}
+
+	testCases := []struct {
+		name            string
+		token           string
+		shouldPrincipal Principal
+		shouldError     bool
+	}{
+		{
+			name:            "hs256",
+			token:           signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(nil), hs256("new-secret")),
+			shouldPrincipal: Principal{Subject: "user:42", UserID: 42, Role: "user", Scopes: []string{"user:read", "user:update"}, Method: MethodJWT},
+		},
+		{
+			name:            "rotated_key",
+			token:           signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "old"}, claims(nil), hs256("old-secret")),
+			shouldPrincipal: Principal{Subject: "user:42", UserID: 42, Role: "user", Scopes: []string{"user:read", "user:update"}, Method: MethodJWT},
+		},
+		{
+			name:            "rs256_service",
+			token:           signJWT(t, map[string]interface{}{"alg": "RS256", "kid": "rsa"}, claims(map[string]interface{}{"sub": "billing", "aud": "audience", "scope": nil, "role": nil}), rs256(rsaKey)),
+			shouldPrincipal: Principal{Subject: "billing", Scopes: []string{}, Method: MethodJWT},
+		},
+		{
+			name:        "wrong_secret",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(nil), hs256("old-secret")),
+			shouldError: true,
+		},
+		{
+			name:        "unknown_kid",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "unknown"}, claims(nil), hs256("new-secret")),
+			shouldError: true,
+		},
+		{
+			name:        "missing_kid_with_many_keys",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256"}, claims(nil), hs256("new-secret")),
+			shouldError: true,
+		},
+		{
+			name:        "algorithm_confusion",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "rsa"}, claims(nil), hs256("")),
+			shouldError: true,
+		},
+		{
+			name:        "none_algorithm",
+			token:       signJWT(t, map[string]interface{}{"alg": "none", "kid": "new"}, claims(nil), func(string) []byte { return nil }),
+			shouldError: true,
+		},
+		{
+			name:        "expired",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(map[string]interface{}{"exp": now.Add(-2 * time.Minute).Unix()}), hs256("new-secret")),
+			shouldError: true,
+		},
+		{
+			name:            "expired_within_leeway",
+			token:           signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(map[string]interface{}{"exp": now.Add(-30 * time.Second).Unix()}), hs256("new-secret")),
+			shouldPrincipal: Principal{Subject: "user:42", UserID: 42, Role: "user", Scopes: []string{"user:read", "user:update"}, Method: MethodJWT},
+		},
+		{
+			name:        "without_expiration",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(map[string]interface{}{"exp": nil}), hs256("new-secret")),
+			shouldError: true,
+		},
+		{
+			name:        "not_valid_yet",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(map[string]interface{}{"nbf": now.Add(time.Hour).Unix()}), hs256("new-secret")),
+			shouldError: true,
+		},
+		{
+			name:        "wrong_issuer",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(map[string]interface{}{"iss": "other"}), hs256("new-secret")),
+			shouldError: true,
+		},
+		{
+			name:        "wrong_audience",
+			token:       signJWT(t, map[string]interface{}{"alg": "HS256", "kid": "new"}, claims(map[string]interface{}{"aud": "other"}), hs256("new-secret")),
+			shouldError: true,
+		},
+		{
+			name:        "malformed",
+			token:       "not.a-jwt",
+			shouldError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := verifier.Verify(tc.token, now)
+			if tc.shouldError {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.shouldPrincipal, principal)
+		})
+	}
+}
+
+func TestNewJWTVerifier(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  config.JWTKey
+	}{
+		{name: "empty_secret", key: config.JWTKey{ID: "key", Algorithm: AlgorithmHS256}},
+		{name: "invalid_public_key", key: config.JWTKey{ID: "key", Algorithm: AlgorithmRS256, PublicKey: "not a pem"}},
+		{name: "unsupported_algorithm", key: config.JWTKey{ID: "key", Algorithm: "none"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewJWTVerifier(config.JWT{Keys: []config.JWTKey{tc.key}})
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"go-structure-demo/internal/entity"
+	"strconv"
+)
+
+const (
+	MethodSession = "session"
+	MethodJWT     = "jwt"
+	MethodSystem  = "system"
+	MethodAPIKey  = "api_key"
+
+	// RoleSystem is the role of the service itself, like the pubsub handlers.
+	RoleSystem = "system"
+	// RoleService is the role of the other services calling with an api key.
+	RoleService = "service"
+)
+
+// Principal is the authenticated caller of a request.
+type Principal struct {
+	// Subject identifies the caller, it is user:{id} for the users and api_key:{id} for
+	// the api keys.
+	Subject string
+	// UserID is zero when the caller is not a user.
+	UserID uint
+	Role   string
+	Scopes []string
+	// Method is how the caller was authenticated.
+	Method string
+}
+
+func NewUserPrincipal(userID uint, role string, scopes []string, method string) Principal {
+	return Principal{
+		Subject: "user:" + strconv.FormatUint(uint64(userID), 10),
+		UserID:  userID,
+		Role:    role,
+		Scopes:  scopes,
+		Method:  method,
+	}
+}
+
+// NewAPIKeyPrincipal is granted the scopes of the key only.
+func NewAPIKeyPrincipal(key entity.APIKey) Principal {
+	return Principal{
+		Subject: "api_key:" + strconv.FormatUint(uint64(key.ID), 10),
+		Role:    RoleService,
+		Scopes:  key.Scopes,
+		Method:  MethodAPIKey,
+	}
+}
+
+// SystemPrincipal acts for the service itself, on the work that no caller is behind.
+func SystemPrincipal() Principal {
+	return Principal{Subject: "system", Role: RoleSystem, Method: MethodSystem}
+}
+
+func (p Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal of an authenticated request.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,16 @@
+package backoff
+
+import "time"
+
+// Exponential doubles initial for each attempt made so far, up to max. The first attempt
+// waits initial, attempts below one are taken for the first.
+func Exponential(initial, max time.Duration, attempts int) time.Duration {
+	backoff := initial
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= max {
+			return max
+		}
+	}
+	return backoff
+}
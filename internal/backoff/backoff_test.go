@@ -0,0 +1,29 @@
+package backoff
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExponential(t *testing.T) {
+	testCases := []struct {
+		name          string
+		attempts      int
+		shouldBackoff time.Duration
+	}{
+		{name: "no_attempt", attempts: 0, shouldBackoff: time.Second},
+		{name: "first_attempt", attempts: 1, shouldBackoff: time.Second},
+		{name: "second_attempt", attempts: 2, shouldBackoff: 2 * time.Second},
+		{name: "third_attempt", attempts: 3, shouldBackoff: 4 * time.Second},
+		{name: "capped", attempts: 4, shouldBackoff: 5 * time.Second},
+		{name: "no_overflow", attempts: 200, shouldBackoff: 5 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.shouldBackoff, Exponential(time.Second, 5*time.Second, tc.attempts))
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"go-structure-demo/internal/config"
+	"sync"
+	"time"
+)
+
+// ErrOpen is returned while the breaker is open, the call is not made at all.
+var ErrOpen = errors.New("circuit breaker is open")
+
+type State string
+
+// Outcome of a call, the ignored ones say nothing about the health of the callee, like the
+// calls cancelled by the caller.
+type Outcome int
+
+const (
+	Success Outcome = iota
+	Failure
+	Ignored
+)
+
+const (
+	StateClosed   State = "closed"
+	StateOpen     State = "open"
+	StateHalfOpen State = "half_open"
+)
+
+// Breaker opens after FailureThreshold consecutive failures and fails fast for OpenTimeout.
+// It is half open afterwards, HalfOpenRequests probes go through and the first result
+// closes or opens it again.
+type Breaker struct {
+	cfg           config.CircuitBreaker
+	onStateChange func(from, to State)
+	now           func() time.Time
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probes   int
+}
+
+// New returns a closed breaker, onStateChange is optional and called without the lock held.
+func New(cfg config.CircuitBreaker, onStateChange func(from, to State)) *Breaker {
+	if onStateChange == nil {
+		onStateChange = func(from, to State) {}
+	}
+	return &Breaker{cfg: cfg, onStateChange: onStateChange, now: time.Now, state: StateClosed}
+}
+
+// Allow returns ErrOpen when the call must not be made, otherwise done must be called with
+// the outcome of the call.
+func (b *Breaker) Allow() (done func(outcome Outcome), err error) {
+	b.mu.Lock()
+	from := b.state
+	if b.state == StateOpen && !b.now().Before(b.openedAt.Add(b.cfg.OpenTimeout)) {
+		b.state = StateHalfOpen
+		b.probes = 0
+	}
+	switch {
+	case b.state == StateOpen:
+		b.mu.Unlock()
+		return nil, ErrOpen
+	case b.state == StateHalfOpen && b.probes >= b.cfg.HalfOpenRequests:
+		to := b.state
+		b.mu.Unlock()
+		b.changed(from, to)
+		return nil, ErrOpen
+	case b.state == StateHalfOpen:
+		b.probes++
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.changed(from, to)
+
+	var once sync.Once
+	return func(outcome Outcome) {
+		once.Do(func() { b.done(to == StateHalfOpen, outcome) })
+	}, nil
+}
+
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// done only closes the breaker for the successes of the probes or of the calls made while
+// closed, a call that started before the breaker opened says nothing of the callee now.
+func (b *Breaker) done(probe bool, outcome Outcome) {
+	b.mu.Lock()
+	from := b.state
+	if probe && b.state == StateHalfOpen {
+		b.probes--
+	}
+	switch {
+	case outcome == Ignored:
+	case outcome == Success:
+		if b.state == StateClosed || probe && b.state == StateHalfOpen {
+			b.state = StateClosed
+			b.failures = 0
+		}
+	case b.state == StateHalfOpen:
+		b.open()
+	case b.state == StateClosed:
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.open()
+		}
+	}
+	to := b.state
+	b.mu.Unlock()
+	b.changed(from, to)
+}
+
+// open must be called with the lock held.
+func (b *Breaker) open() {
+	b.state = StateOpen
+	b.failures = 0
+	b.openedAt = b.now()
+}
+
+func (b *Breaker) changed(from, to State) {
+	if from != to {
+		b.onStateChange(from, to)
+	}
+}
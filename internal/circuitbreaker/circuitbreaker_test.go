@@ -0,0 +1,97 @@
+package circuitbreaker
+
+import (
+	"go-structure-demo/internal/config"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBreaker(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	var changes []State
+	breaker := New(config.CircuitBreaker{FailureThreshold: 3, OpenTimeout: time.Minute, HalfOpenRequests: 1}, func(from, to State) {
+		changes = append(changes, to)
+	})
+	breaker.now = func() time.Time { return now }
+
+	call := func(success bool) error {
+		done, err := breaker.Allow()
+		if err != nil {
+			return err
+		}
+		if success {
+			done(Success)
+		} else {
+			done(Failure)
+		}
+		return nil
+	}
+
+	// a success resets the consecutive failures
+	assert.Nil(t, call(false))
+	assert.Nil(t, call(false))
+	assert.Nil(t, call(true))
+	assert.Nil(t, call(false))
+	assert.Nil(t, call(false))
+	assert.Equal(t, StateClosed, breaker.State())
+
+	assert.Nil(t, call(false))
+	assert.Equal(t, StateOpen, breaker.State())
+	assert.ErrorIs(t, call(true), ErrOpen)
+
+	// a failed probe opens it again
+	now = now.Add(time.Minute)
+	assert.Nil(t, call(false))
+	assert.Equal(t, StateOpen, breaker.State())
+	assert.ErrorIs(t, call(true), ErrOpen)
+
+	// only one probe at a time, an ignored one lets the next through
+	now = now.Add(time.Minute)
+	done, err := breaker.Allow()
+	assert.Nil(t, err)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	_, err = breaker.Allow()
+	assert.ErrorIs(t, err, ErrOpen)
+	done(Ignored)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	done, err = breaker.Allow()
+	assert.Nil(t, err)
+	done(Success)
+	done(Failure)
+	assert.Equal(t, StateClosed, breaker.State())
+	assert.Nil(t, call(false))
+
+	assert.Equal(t, []State{StateOpen, StateHalfOpen, StateOpen, StateHalfOpen, StateClosed}, changes)
+}
+
+func TestBreaker_LateSuccess(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := New(config.CircuitBreaker{FailureThreshold: 1, OpenTimeout: time.Minute, HalfOpenRequests: 1}, nil)
+	breaker.now = func() time.Time { return now }
+
+	// slow calls started while closed succeed after the breaker opened
+	slow := make([]func(Outcome), 2)
+	for i := range slow {
+		done, err := breaker.Allow()
+		assert.Nil(t, err)
+		slow[i] = done
+	}
+	failed, err := breaker.Allow()
+	assert.Nil(t, err)
+	failed(Failure)
+	assert.Equal(t, StateOpen, breaker.State())
+	slow[0](Success)
+	assert.Equal(t, StateOpen, breaker.State())
+
+	// nor does one close a half open breaker, only the probe does
+	now = now.Add(time.Minute)
+	probe, err := breaker.Allow()
+	assert.Nil(t, err)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	slow[1](Success)
+	assert.Equal(t, StateHalfOpen, breaker.State())
+	probe(Success)
+	assert.Equal(t, StateClosed, breaker.State())
+}
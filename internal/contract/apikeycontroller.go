@@ -0,0 +1,12 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/param"
+)
+
+type APIKeyController interface {
+	CreateAPIKey(ctx context.Context, requestParam *param.CreateAPIKeyRequest) param.CreateAPIKeyResponse
+	ListAPIKeys(ctx context.Context, requestParam *param.ListAPIKeysRequest) param.ListAPIKeysResponse
+	RevokeAPIKey(ctx context.Context, requestParam *param.RevokeAPIKeyRequest) param.RevokeAPIKeyResponse
+}
@@ -0,0 +1,16 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+// APIKeyStore returns an apperror.KindNotFound error for the missing keys.
+type APIKeyStore interface {
+	CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error)
+	GetAPIKeyByHash(ctx context.Context, hash string) (entity.APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]entity.APIKey, error)
+	RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error
+	TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error
+}
@@ -0,0 +1,18 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/param"
+)
+
+type AuthController interface {
+	RequestEmailVerification(ctx context.Context, requestParam *param.EmailLinkRequest) param.EmailLinkResponse
+	VerifyEmail(ctx context.Context, requestParam *param.RedeemTokenRequest) param.SessionResponse
+	RequestMagicLink(ctx context.Context, requestParam *param.EmailLinkRequest) param.EmailLinkResponse
+	RedeemMagicLink(ctx context.Context, requestParam *param.RedeemTokenRequest) param.SessionResponse
+}
+
+type EmailVerifier interface {
+	SendEmailVerification(ctx context.Context, user entity.User) error
+}
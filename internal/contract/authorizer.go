@@ -0,0 +1,10 @@
+package contract
+
+import (
+	"context"
+)
+
+// Authorizer decides whether the principal in ctx may do the action on the resource.
+type Authorizer interface {
+	Authorize(ctx context.Context, action string, resource interface{}) error
+}
@@ -0,0 +1,14 @@
+package contract
+
+import (
+	"context"
+	"time"
+)
+
+// CheckpointStore keeps where a job stopped, so a partial run can resume from there.
+type CheckpointStore interface {
+	// GetCheckpoint returns false when the job has no checkpoint.
+	GetCheckpoint(ctx context.Context, job string) (string, bool, error)
+	SetCheckpoint(ctx context.Context, job string, checkpoint string, expiration time.Duration) error
+	DeleteCheckpoint(ctx context.Context, job string)
+}
@@ -0,0 +1,18 @@
+package contract
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotencyStore remembers the handled keys. A key is claimed before it is handled, so a
+// single handler runs for it at a time.
+type IdempotencyStore interface {
+	// ClaimProcessing takes the key for the lease. When the key is taken already, processed
+	// tells a handled key from one still being handled.
+	ClaimProcessing(ctx context.Context, key string, lease time.Duration) (claimed bool, processed bool, err error)
+	MarkProcessed(ctx context.Context, key string, expiration time.Duration) error
+	// ReleaseProcessing gives up the claim of a key that was not handled, a redelivery can
+	// claim it again.
+	ReleaseProcessing(ctx context.Context, key string) error
+}
@@ -0,0 +1,11 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/param"
+)
+
+type LogLevelController interface {
+	GetLogLevel(ctx context.Context, requestParam *param.GetLogLevelRequest) param.GetLogLevelResponse
+	SetLogLevel(ctx context.Context, requestParam *param.SetLogLevelRequest) param.SetLogLevelResponse
+}
@@ -0,0 +1,11 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/entity"
+)
+
+// Notifier delivers the notifications to the users, like the emails with the login links.
+type Notifier interface {
+	Notify(ctx context.Context, notification entity.Notification) error
+}
@@ -0,0 +1,19 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+type OutboxStore interface {
+	AddOutboxEvent(ctx context.Context, event entity.OutboxEvent) error
+	// DueOutboxEvents returns the pending events due by now that are the oldest pending event
+	// of their aggregate, in insertion order.
+	DueOutboxEvents(ctx context.Context, now time.Time, limit int) ([]entity.OutboxEvent, error)
+	MarkOutboxEventPublished(ctx context.Context, id uint64, publishedAt time.Time) error
+	MarkOutboxEventRetry(ctx context.Context, id uint64, lastError string, nextAttemptAt time.Time) error
+	MarkOutboxEventDead(ctx context.Context, id uint64, lastError string, deadAt time.Time) error
+	DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error)
+	OutboxStats(ctx context.Context) (entity.OutboxStats, error)
+}
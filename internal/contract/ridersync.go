@@ -0,0 +1,21 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+// RiderSyncer creates the rider profile of a user, the returned user carries the outcome.
+type RiderSyncer interface {
+	SyncRider(ctx context.Context, user entity.User) (entity.User, error)
+}
+
+// RiderSyncStore keeps the state of the rider sync on the users, attempts is the number
+// of attempts made so far including the one being recorded.
+type RiderSyncStore interface {
+	DueRiderSyncs(ctx context.Context, now time.Time, limit int) ([]entity.RiderSync, error)
+	MarkRiderSynced(ctx context.Context, userID uint, riderID string, attempts int) error
+	MarkRiderSyncRetry(ctx context.Context, userID uint, attempts int, lastError string, nextAttemptAt time.Time) error
+	MarkRiderSyncFailed(ctx context.Context, userID uint, attempts int, lastError string) error
+}
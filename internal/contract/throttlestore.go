@@ -0,0 +1,11 @@
+package contract
+
+import (
+	"context"
+	"time"
+)
+
+type ThrottleStore interface {
+	// Throttle reports whether the key is allowed now, an allowed key is blocked for the interval.
+	Throttle(ctx context.Context, key string, interval time.Duration) (bool, error)
+}
@@ -2,10 +2,15 @@ package contract
 
 import (
 	"context"
+	"go-structure-demo/internal/entity"
 	"time"
 )
 
+// TokenStore keeps the tokens of the users, a token is only valid for the purpose it was set
+// for. One-time tokens are pulled, the session tokens are read with GetToken until they expire.
+// Both return false for an unknown or an expired token, and an error when the store fails.
 type TokenStore interface {
-	SetToken(ctx context.Context, token string, userID uint, expiration time.Duration) error
-	PullToken(ctx context.Context, token string) (uint, bool)
+	SetToken(ctx context.Context, purpose entity.TokenPurpose, token string, userID uint, expiration time.Duration) error
+	GetToken(ctx context.Context, purpose entity.TokenPurpose, token string) (uint, bool, error)
+	PullToken(ctx context.Context, purpose entity.TokenPurpose, token string) (uint, bool, error)
 }
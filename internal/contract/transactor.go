@@ -0,0 +1,14 @@
+package contract
+
+import "context"
+
+// Transactor runs fn in a single transaction, the stores called with the ctx
+// passed to fn take part in it.
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+	// AfterCommit runs fn once the transaction bound to ctx is committed, it is dropped
+	// on a rollback. Without a transaction fn runs right away.
+	AfterCommit(ctx context.Context, fn func())
+	// InTransaction tells if a transaction is bound to ctx.
+	InTransaction(ctx context.Context) bool
+}
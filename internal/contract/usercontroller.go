@@ -7,4 +7,8 @@ import (
 
 type UserController interface {
 	CreateUser(ctx context.Context, requestParam *param.CreateUserRequest) param.CreateUserResponse
+	GetUser(ctx context.Context, requestParam *param.GetUserRequest) param.GetUserResponse
+	UpdateUser(ctx context.Context, requestParam *param.UpdateUserRequest) param.UpdateUserResponse
+	DeleteUser(ctx context.Context, requestParam *param.DeleteUserRequest) param.DeleteUserResponse
+	SyncRider(ctx context.Context, requestParam *param.SyncRiderRequest) param.SyncRiderResponse
 }
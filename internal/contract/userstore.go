@@ -6,6 +6,11 @@ import (
 	"go-structure-demo/internal/param"
 )
 
+// UserStore returns an apperror.KindNotFound error for the missing users.
 type UserStore interface {
 	CreateUser(ctx context.Context, createUserRequest *param.CreateUserRequest) (entity.User, error)
+	GetUserByID(ctx context.Context, id uint) (entity.User, error)
+	GetUserByEmail(ctx context.Context, email string) (entity.User, error)
+	UpdateUser(ctx context.Context, user entity.User) (entity.User, error)
+	DeleteUser(ctx context.Context, id uint) error
 }
@@ -0,0 +1,44 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+// WebhookPublisher queues an event for the webhooks subscribed to it. Called within a
+// transaction, the deliveries are committed together with the change they tell about.
+type WebhookPublisher interface {
+	PublishWebhookEvent(ctx context.Context, eventType string, payload interface{}) error
+}
+
+type WebhookStore interface {
+	CreateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (entity.Webhook, error)
+	ListWebhooks(ctx context.Context) ([]entity.Webhook, error)
+	// UpdateWebhook writes the url, the events and the active flag, enabling a webhook
+	// resets its failures.
+	UpdateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error)
+	DeleteWebhook(ctx context.Context, id uint) error
+	// ActiveWebhooks returns the active webhooks subscribed to the event.
+	ActiveWebhooks(ctx context.Context, eventType string) ([]entity.Webhook, error)
+	ResetWebhookFailures(ctx context.Context, id uint) error
+	// AddWebhookFailure counts a failed attempt and disables the webhook once it has
+	// disableAfter failures in a row, it reports whether the webhook got disabled.
+	AddWebhookFailure(ctx context.Context, id uint, disableAfter int, now time.Time) (bool, error)
+}
+
+// WebhookDeliveryStore keeps the delivery log, attempts is the number of attempts made so
+// far including the one being recorded.
+type WebhookDeliveryStore interface {
+	AddWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (entity.WebhookDelivery, error)
+	GetWebhookDelivery(ctx context.Context, webhookID uint, id uint64) (entity.WebhookDelivery, error)
+	// ListWebhookDeliveries returns the deliveries of the webhook, newest first.
+	ListWebhookDeliveries(ctx context.Context, webhookID uint, limit int) ([]entity.WebhookDelivery, error)
+	// DueWebhookDeliveries returns the pending deliveries due by now of the active webhooks
+	// that are the oldest pending delivery of their webhook.
+	DueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error)
+	MarkWebhookDeliverySucceeded(ctx context.Context, id uint64, attempts int, responseStatus int, deliveredAt time.Time) error
+	MarkWebhookDeliveryRetry(ctx context.Context, id uint64, attempts int, responseStatus int, lastError string, nextAttemptAt time.Time) error
+	MarkWebhookDeliveryFailed(ctx context.Context, id uint64, attempts int, responseStatus int, lastError string) error
+}
@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"context"
+	"go-structure-demo/internal/param"
+)
+
+type WebhookController interface {
+	CreateWebhook(ctx context.Context, requestParam *param.CreateWebhookRequest) param.CreateWebhookResponse
+	ListWebhooks(ctx context.Context, requestParam *param.ListWebhooksRequest) param.ListWebhooksResponse
+	UpdateWebhook(ctx context.Context, requestParam *param.UpdateWebhookRequest) param.UpdateWebhookResponse
+	DeleteWebhook(ctx context.Context, requestParam *param.DeleteWebhookRequest) param.DeleteWebhookResponse
+	ListWebhookDeliveries(ctx context.Context, requestParam *param.ListWebhookDeliveriesRequest) param.ListWebhookDeliveriesResponse
+	RedeliverWebhook(ctx context.Context, requestParam *param.RedeliverWebhookRequest) param.RedeliverWebhookResponse
+}
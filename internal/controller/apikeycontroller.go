@@ -0,0 +1,104 @@
+package controller
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
+	"net/http"
+	"time"
+)
+
+var _ contract.APIKeyController = (*APIKeyController)(nil)
+
+type APIKeyController struct {
+	logger      log.Logger
+	apiKeyStore contract.APIKeyStore
+	authorizer  contract.Authorizer
+	now         func() time.Time
+}
+
+func NewAPIKeyController(logger log.Logger, apiKeyStore contract.APIKeyStore, authorizer contract.Authorizer) *APIKeyController {
+	return &APIKeyController{
+		logger:      logger,
+		apiKeyStore: apiKeyStore,
+		authorizer:  authorizer,
+		now:         time.Now,
+	}
+}
+
+// CreateAPIKey stores the hash of a new key, the plain key is in the response only. The
+// callers holding api_key:create as a scope can't grant the scopes they don't hold, the key
+// would be a way around their own scopes otherwise.
+func (c *APIKeyController) CreateAPIKey(ctx context.Context, request *param.CreateAPIKeyRequest) param.CreateAPIKeyResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionAPIKeyCreate, nil); err != nil {
+		return param.CreateAPIKeyResponse{Message: "api key creation failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+	principal, _ := auth.PrincipalFrom(ctx)
+	for _, scope := range request.Scopes {
+		if !policy.CanGrant(principal, scope) {
+			err := apperror.Forbidden("not allowed to grant " + scope)
+			return param.CreateAPIKeyResponse{Message: "api key creation failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+		}
+	}
+
+	key, prefix, hash, err := auth.NewAPIKey()
+	if err != nil {
+		return param.CreateAPIKeyResponse{Message: "api key creation failed", Error: err, StatusCode: http.StatusInternalServerError}
+	}
+	apiKey, err := c.apiKeyStore.CreateAPIKey(ctx, entity.APIKey{
+		Name:      request.Name,
+		Owner:     request.Owner,
+		Prefix:    prefix,
+		Hash:      hash,
+		Scopes:    request.Scopes,
+		ExpiresAt: request.ExpiresAt,
+	})
+	if err != nil {
+		return param.CreateAPIKeyResponse{Message: "api key creation failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	c.logger.InfoWithContext(ctx, "api key created", map[string]interface{}{
+		"api_key_id": apiKey.ID,
+		"owner":      apiKey.Owner,
+		"scopes":     apiKey.Scopes,
+		"created_by": principal.Subject,
+	})
+
+	return param.CreateAPIKeyResponse{Message: "api key created!", Key: key, APIKey: apiKey, StatusCode: http.StatusCreated}
+}
+
+func (c *APIKeyController) ListAPIKeys(ctx context.Context, request *param.ListAPIKeysRequest) param.ListAPIKeysResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionAPIKeyRead, nil); err != nil {
+		return param.ListAPIKeysResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	apiKeys, err := c.apiKeyStore.ListAPIKeys(ctx)
+	if err != nil {
+		return param.ListAPIKeysResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.ListAPIKeysResponse{APIKeys: apiKeys, StatusCode: http.StatusOK}
+}
+
+func (c *APIKeyController) RevokeAPIKey(ctx context.Context, request *param.RevokeAPIKeyRequest) param.RevokeAPIKeyResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionAPIKeyRevoke, nil); err != nil {
+		return param.RevokeAPIKeyResponse{Message: "api key revocation failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	if err := c.apiKeyStore.RevokeAPIKey(ctx, request.ID, c.now()); err != nil {
+		return param.RevokeAPIKeyResponse{Message: "api key revocation failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	principal, _ := auth.PrincipalFrom(ctx)
+	c.logger.InfoWithContext(ctx, "api key revoked", map[string]interface{}{
+		"api_key_id": request.ID,
+		"revoked_by": principal.Subject,
+	})
+
+	return param.RevokeAPIKeyResponse{Message: "api key revoked!", StatusCode: http.StatusOK}
+}
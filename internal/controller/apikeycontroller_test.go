@@ -0,0 +1,74 @@
+package controller
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryAPIKeyStore struct {
+	keys []entity.APIKey
+}
+
+func (s *memoryAPIKeyStore) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	key.ID = uint(len(s.keys) + 1)
+	s.keys = append(s.keys, key)
+	return key, nil
+}
+
+func (s *memoryAPIKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (entity.APIKey, error) {
+	return entity.APIKey{}, apperror.NotFound("api key not found")
+}
+
+func (s *memoryAPIKeyStore) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	return s.keys, nil
+}
+
+func (s *memoryAPIKeyStore) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error {
+	return nil
+}
+
+func (s *memoryAPIKeyStore) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	return nil
+}
+
+func TestAPIKeyController_CreateAPIKey(t *testing.T) {
+	service := auth.NewAPIKeyPrincipal(entity.APIKey{ID: 9, Scopes: []string{policy.ActionAPIKeyCreate, policy.ActionUserRead}})
+	admin := auth.NewUserPrincipal(1, entity.RoleAdmin, nil, auth.MethodSession)
+
+	testCases := []struct {
+		name         string
+		principal    auth.Principal
+		scopes       []string
+		shouldStatus int
+	}{
+		{name: "service_grants_held_scope", principal: service, scopes: []string{policy.ActionUserRead}, shouldStatus: http.StatusCreated},
+		{name: "service_escalates", principal: service, scopes: []string{policy.ActionUserRead, policy.ActionUserDelete}, shouldStatus: http.StatusForbidden},
+		{name: "admin_grants_any", principal: admin, scopes: []string{policy.ActionUserDelete}, shouldStatus: http.StatusCreated},
+		{name: "system_grants_any", principal: auth.SystemPrincipal(), scopes: []string{policy.ActionLogLevelUpdate}, shouldStatus: http.StatusCreated},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			store := &memoryAPIKeyStore{}
+			c := NewAPIKeyController(log.NewMock("api-key"), store, policy.New())
+			ctx := auth.WithPrincipal(context.Background(), tc.principal)
+
+			response := c.CreateAPIKey(ctx, &param.CreateAPIKeyRequest{Name: "ci", Owner: "platform", Scopes: tc.scopes})
+			assert.Equal(t, tc.shouldStatus, response.StatusCode)
+			if tc.shouldStatus != http.StatusCreated {
+				assert.Empty(t, store.keys)
+				assert.Empty(t, response.Key)
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package controller
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	_ contract.AuthController = (*AuthController)(nil)
+	_ contract.EmailVerifier  = (*AuthController)(nil)
+)
+
+// linkSent is the answer whether the email is known or not, so the endpoints can't be used
+// to find out who has an account.
+const linkSent = "if the email belongs to an account, a link is on its way"
+
+// AuthController runs the passwordless flows, the links carry one-time tokens and redeeming
+// one issues a session token.
+type AuthController struct {
+	cfg           config.Auth
+	logger        log.Logger
+	userStore     contract.UserStore
+	tokenStore    contract.TokenStore
+	throttleStore contract.ThrottleStore
+	notifier      contract.Notifier
+	now           func() time.Time
+}
+
+func NewAuthController(
+	cfg config.Auth,
+	logger log.Logger,
+	userStore contract.UserStore,
+	tokenStore contract.TokenStore,
+	throttleStore contract.ThrottleStore,
+	notifier contract.Notifier,
+) *AuthController {
+	return &AuthController{
+		cfg:           cfg,
+		logger:        logger,
+		userStore:     userStore,
+		tokenStore:    tokenStore,
+		throttleStore: throttleStore,
+		notifier:      notifier,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (c *AuthController) SendEmailVerification(ctx context.Context, user entity.User) error {
+	return c.sendLink(ctx, entity.TokenPurposeEmailVerification, user, c.cfg.EmailVerificationTTL,
+		"Verify your email", "verify-email")
+}
+
+func (c *AuthController) RequestEmailVerification(ctx context.Context, request *param.EmailLinkRequest) param.EmailLinkResponse {
+	if err := c.throttle(ctx, entity.TokenPurposeEmailVerification, request.Email); err != nil {
+		return param.EmailLinkResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	user, err := c.userStore.GetUserByEmail(ctx, request.Email)
+	if apperror.Is(err, apperror.KindNotFound) || (err == nil && user.IsEmailVerified()) {
+		return param.EmailLinkResponse{Message: linkSent, StatusCode: http.StatusAccepted}
+	}
+	if err == nil {
+		err = c.issueLink(ctx, entity.TokenPurposeEmailVerification, user, c.cfg.EmailVerificationTTL,
+			"Verify your email", "verify-email")
+	}
+	if err != nil {
+		return param.EmailLinkResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.EmailLinkResponse{Message: linkSent, StatusCode: http.StatusAccepted}
+}
+
+func (c *AuthController) VerifyEmail(ctx context.Context, request *param.RedeemTokenRequest) param.SessionResponse {
+	return c.redeem(ctx, entity.TokenPurposeEmailVerification, request.Token)
+}
+
+func (c *AuthController) RequestMagicLink(ctx context.Context, request *param.EmailLinkRequest) param.EmailLinkResponse {
+	if err := c.throttle(ctx, entity.TokenPurposeMagicLogin, request.Email); err != nil {
+		return param.EmailLinkResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	user, err := c.userStore.GetUserByEmail(ctx, request.Email)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return param.EmailLinkResponse{Message: linkSent, StatusCode: http.StatusAccepted}
+	}
+	if err == nil {
+		err = c.issueLink(ctx, entity.TokenPurposeMagicLogin, user, c.cfg.MagicLinkTTL, "Your login link", "login")
+	}
+	if err != nil {
+		return param.EmailLinkResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.EmailLinkResponse{Message: linkSent, StatusCode: http.StatusAccepted}
+}
+
+// RedeemMagicLink verifies the email as well, opening the link proves owning it.
+func (c *AuthController) RedeemMagicLink(ctx context.Context, request *param.RedeemTokenRequest) param.SessionResponse {
+	return c.redeem(ctx, entity.TokenPurposeMagicLogin, request.Token)
+}
+
+// redeem pulls the token, so a reused or an expired one is rejected the same way, and
+// starts a session for its user.
+func (c *AuthController) redeem(ctx context.Context, purpose entity.TokenPurpose, token string) param.SessionResponse {
+	invalid := apperror.Unauthorized("invalid or expired token")
+
+	userID, ok, err := c.tokenStore.PullToken(ctx, purpose, token)
+	if err != nil {
+		return param.SessionResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+	if !ok {
+		return param.SessionResponse{Error: invalid, StatusCode: apperror.HTTPStatus(invalid)}
+	}
+
+	user, err := c.userStore.GetUserByID(ctx, userID)
+	if apperror.Is(err, apperror.KindNotFound) {
+		return param.SessionResponse{Error: invalid, StatusCode: apperror.HTTPStatus(invalid)}
+	}
+	if err != nil {
+		return param.SessionResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	if !user.IsEmailVerified() {
+		now := c.now()
+		user.EmailVerifiedAt = &now
+		if user, err = c.userStore.UpdateUser(ctx, user); err != nil {
+			return param.SessionResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+		}
+	}
+
+	session, err := auth.NewToken()
+	if err != nil {
+		return param.SessionResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+	if err = c.tokenStore.SetToken(ctx, entity.TokenPurposeSession, session, user.ID, c.cfg.SessionTTL); err != nil {
+		return param.SessionResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.SessionResponse{
+		Token:      session,
+		ExpiresIn:  int(c.cfg.SessionTTL.Seconds()),
+		User:       user,
+		StatusCode: http.StatusOK,
+	}
+}
+
+func (c *AuthController) sendLink(ctx context.Context, purpose entity.TokenPurpose, user entity.User, ttl time.Duration, subject, path string) error {
+	if err := c.throttle(ctx, purpose, user.Email); err != nil {
+		return err
+	}
+	return c.issueLink(ctx, purpose, user, ttl, subject, path)
+}
+
+func (c *AuthController) issueLink(ctx context.Context, purpose entity.TokenPurpose, user entity.User, ttl time.Duration, subject, path string) error {
+	token, err := auth.NewToken()
+	if err != nil {
+		return err
+	}
+	if err = c.tokenStore.SetToken(ctx, purpose, token, user.ID, ttl); err != nil {
+		return err
+	}
+
+	return c.notifier.Notify(ctx, entity.Notification{
+		To:      user.Email,
+		Subject: subject,
+		Body: fmt.Sprintf("Open the link below, it expires in %s.\n\n%s/%s?token=%s",
+			ttl, strings.TrimRight(c.cfg.PublicURL, "/"), path, token),
+	})
+}
+
+// throttle allows a link per purpose and email every EmailThrottle, the email is hashed so
+// it doesn't end up in redis.
+func (c *AuthController) throttle(ctx context.Context, purpose entity.TokenPurpose, email string) error {
+	hash := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	allowed, err := c.throttleStore.Throttle(ctx, fmt.Sprintf("email:%s:%s", purpose, hex.EncodeToString(hash[:])), c.cfg.EmailThrottle)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return apperror.TooManyRequests("a link was sent recently, try again later")
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"context"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/repository/redisrepo"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryUserStore struct {
+	users map[uint]entity.User
+}
+
+func (s *memoryUserStore) CreateUser(ctx context.Context, createUserRequest *param.CreateUserRequest) (entity.User, error) {
+	user := entity.User{ID: uint(len(s.users) + 1), Email: createUserRequest.Email, Role: entity.RoleUser}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *memoryUserStore) GetUserByID(ctx context.Context, id uint) (entity.User, error) {
+	user, ok := s.users[id]
+	if !ok {
+		return entity.User{}, apperror.NotFound("user not found")
+	}
+	return user, nil
+}
+
+func (s *memoryUserStore) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return entity.User{}, apperror.NotFound("user not found")
+}
+
+func (s *memoryUserStore) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *memoryUserStore) DeleteUser(ctx context.Context, id uint) error {
+	delete(s.users, id)
+	return nil
+}
+
+type recordingNotifier struct {
+	notifications []entity.Notification
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification entity.Notification) error {
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+// lastToken returns the token of the link in the last notification.
+func (n *recordingNotifier) lastToken(t *testing.T) string {
+	if !assert.NotEmpty(t, n.notifications) {
+		return ""
+	}
+	body := n.notifications[len(n.notifications)-1].Body
+	return body[strings.LastIndex(body, "token=")+len("token="):]
+}
+
+type authControllerTest struct {
+	controller *AuthController
+	users      *memoryUserStore
+	tokens     *redisrepo.RedisRepo
+	notifier   *recordingNotifier
+	clock      *redistest.Clock
+}
+
+func newAuthControllerTest() authControllerTest {
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo := redisrepo.NewWithAdapter(redistest.NewMemory(clock.Now))
+	users := &memoryUserStore{users: map[uint]entity.User{
+		1: {ID: 1, Email: "john@doe.com", Role: entity.RoleUser},
+	}}
+	notifier := new(recordingNotifier)
+	cfg := config.Auth{
+		SessionTTL:           time.Hour,
+		EmailVerificationTTL: time.Hour,
+		MagicLinkTTL:         15 * time.Minute,
+		EmailThrottle:        time.Minute,
+		PublicURL:            "https://example.com/",
+	}
+	controller := NewAuthController(cfg, log.NewMock("auth"), users, repo, repo, notifier)
+	controller.now = clock.Now
+	return authControllerTest{controller: controller, users: users, tokens: repo, notifier: notifier, clock: clock}
+}
+
+func TestAuthController_MagicLink(t *testing.T) {
+	ctx := context.Background()
+	test := newAuthControllerTest()
+
+	response := test.controller.RequestMagicLink(ctx, &param.EmailLinkRequest{Email: "John@Doe.com"})
+	assert.Nil(t, response.Error)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Equal(t, "john@doe.com", test.notifier.notifications[0].To)
+	assert.Contains(t, test.notifier.notifications[0].Body, "https://example.com/login?token=")
+
+	token := test.notifier.lastToken(t)
+	session := test.controller.RedeemMagicLink(ctx, &param.RedeemTokenRequest{Token: token})
+	assert.Nil(t, session.Error)
+	assert.Equal(t, 3600, session.ExpiresIn)
+	assert.True(t, session.User.IsEmailVerified())
+	userID, ok, err := test.tokens.GetToken(ctx, entity.TokenPurposeSession, session.Token)
+	assert.Nil(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, uint(1), userID)
+
+	// the link works once
+	session = test.controller.RedeemMagicLink(ctx, &param.RedeemTokenRequest{Token: token})
+	assert.Equal(t, http.StatusUnauthorized, session.StatusCode)
+}
+
+func TestAuthController_RequestMagicLink(t *testing.T) {
+	ctx := context.Background()
+	test := newAuthControllerTest()
+
+	// the unknown emails get the same answer
+	response := test.controller.RequestMagicLink(ctx, &param.EmailLinkRequest{Email: "jane@doe.com"})
+	assert.Nil(t, response.Error)
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Empty(t, test.notifier.notifications)
+
+	response = test.controller.RequestMagicLink(ctx, &param.EmailLinkRequest{Email: "john@doe.com"})
+	assert.Nil(t, response.Error)
+	response = test.controller.RequestMagicLink(ctx, &param.EmailLinkRequest{Email: "john@doe.com"})
+	assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+	assert.Len(t, test.notifier.notifications, 1)
+
+	test.clock.Advance(time.Minute)
+	response = test.controller.RequestMagicLink(ctx, &param.EmailLinkRequest{Email: "john@doe.com"})
+	assert.Nil(t, response.Error)
+	assert.Len(t, test.notifier.notifications, 2)
+
+	// the link expires
+	test.clock.Advance(15 * time.Minute)
+	session := test.controller.RedeemMagicLink(ctx, &param.RedeemTokenRequest{Token: test.notifier.lastToken(t)})
+	assert.Equal(t, http.StatusUnauthorized, session.StatusCode)
+}
+
+func TestAuthController_EmailVerification(t *testing.T) {
+	ctx := context.Background()
+	test := newAuthControllerTest()
+	user, _ := test.users.GetUserByID(ctx, 1)
+
+	assert.Nil(t, test.controller.SendEmailVerification(ctx, user))
+	assert.Contains(t, test.notifier.notifications[0].Body, "https://example.com/verify-email?token=")
+	token := test.notifier.lastToken(t)
+
+	// a verification token is no login token
+	session := test.controller.RedeemMagicLink(ctx, &param.RedeemTokenRequest{Token: token})
+	assert.Equal(t, http.StatusUnauthorized, session.StatusCode)
+
+	session = test.controller.VerifyEmail(ctx, &param.RedeemTokenRequest{Token: token})
+	assert.Nil(t, session.Error)
+	user, _ = test.users.GetUserByID(ctx, 1)
+	assert.True(t, user.IsEmailVerified())
+
+	// the verified users don't get another link
+	test.clock.Advance(time.Minute)
+	response := test.controller.RequestEmailVerification(ctx, &param.EmailLinkRequest{Email: "john@doe.com"})
+	assert.Equal(t, http.StatusAccepted, response.StatusCode)
+	assert.Len(t, test.notifier.notifications, 1)
+}
@@ -0,0 +1,111 @@
+package controller
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
+	"net/http"
+	"sync"
+	"time"
+)
+
+var _ contract.LogLevelController = (*LogLevelController)(nil)
+
+// LogLevelController changes the level of the replica serving the request only. A change with
+// a revert brings back the level from before the first change still pending a revert, so a
+// few changes in a row can't leave DEBUG on.
+type LogLevelController struct {
+	logger     log.Logger
+	authorizer contract.Authorizer
+	now        func() time.Time
+
+	mu       sync.Mutex
+	timer    *time.Timer
+	revertAt *time.Time
+	revertTo log.Level
+}
+
+func NewLogLevelController(logger log.Logger, authorizer contract.Authorizer) *LogLevelController {
+	return &LogLevelController{
+		logger:     logger,
+		authorizer: authorizer,
+		now:        func() time.Time { return time.Now().UTC() },
+	}
+}
+
+func (c *LogLevelController) GetLogLevel(ctx context.Context, request *param.GetLogLevelRequest) param.GetLogLevelResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionLogLevelRead, nil); err != nil {
+		return param.GetLogLevelResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return param.GetLogLevelResponse{LogLevel: c.logLevel(), StatusCode: http.StatusOK}
+}
+
+func (c *LogLevelController) SetLogLevel(ctx context.Context, request *param.SetLogLevelRequest) param.SetLogLevelResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionLogLevelUpdate, nil); err != nil {
+		return param.SetLogLevelResponse{Message: "log level change failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+	// the level is validated already, an unknown one would be info
+	level, _ := log.ParseLevel(request.Level)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	previous := c.logger.GetLevel()
+	revertTo := previous
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+		revertTo = c.revertTo
+	}
+	c.revertAt = nil
+	c.revertTo = ""
+	if request.RevertAfter > 0 {
+		revertAt := c.now().Add(request.RevertAfter)
+		c.revertAt = &revertAt
+		c.revertTo = revertTo
+		var timer *time.Timer
+		timer = time.AfterFunc(request.RevertAfter, func() { c.revert(timer) })
+		c.timer = timer
+	}
+	c.logger.SetLevel(level)
+
+	principal, _ := auth.PrincipalFrom(ctx)
+	c.logger.WarnWithContext(ctx, "log level changed",
+		log.String("level", string(level)),
+		log.String("previous", string(previous)),
+		log.Duration("revert_after", request.RevertAfter),
+		log.String("changed_by", principal.Subject),
+	)
+
+	return param.SetLogLevelResponse{Message: "log level changed!", LogLevel: c.logLevel(), StatusCode: http.StatusOK}
+}
+
+// revert is the callback of timer, it does nothing once the timer is replaced.
+func (c *LogLevelController) revert(timer *time.Timer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.timer != timer {
+		return
+	}
+	level := c.revertTo
+	c.timer = nil
+	c.revertAt = nil
+	c.revertTo = ""
+	c.logger.SetLevel(level)
+	c.logger.Warn("log level reverted", log.String("level", string(level)))
+}
+
+// logLevel returns the live level, the lock must be held.
+func (c *LogLevelController) logLevel() param.LogLevel {
+	return param.LogLevel{
+		Level:    c.logger.GetLevel(),
+		RevertAt: c.revertAt,
+		RevertTo: c.revertTo,
+	}
+}
@@ -0,0 +1,61 @@
+package controller
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogLevelController_SetLogLevel(t *testing.T) {
+	logger := log.NewMock("log-level")
+	logger.SetLevel(log.LevelInfo)
+	c := NewLogLevelController(logger, policy.New())
+	admin := auth.WithPrincipal(context.Background(), auth.NewUserPrincipal(1, entity.RoleAdmin, nil, auth.MethodSession))
+	user := auth.WithPrincipal(context.Background(), auth.NewUserPrincipal(2, entity.RoleUser, nil, auth.MethodSession))
+
+	response := c.SetLogLevel(user, &param.SetLogLevelRequest{Level: "debug"})
+	assert.True(t, apperror.Is(response.Error, apperror.KindForbidden))
+
+	// a change without a revert stays
+	response = c.SetLogLevel(admin, &param.SetLogLevelRequest{Level: "warn"})
+	assert.Nil(t, response.Error)
+	assert.Equal(t, http.StatusOK, response.StatusCode)
+	assert.Equal(t, param.LogLevel{Level: log.LevelWarn}, response.LogLevel)
+
+	// the second change pending a revert keeps the level to revert to of the first one
+	response = c.SetLogLevel(admin, &param.SetLogLevelRequest{Level: "error", RevertAfter: time.Hour})
+	assert.Equal(t, log.LevelError, response.Level)
+	assert.Equal(t, log.LevelWarn, response.RevertTo)
+	response = c.SetLogLevel(admin, &param.SetLogLevelRequest{Level: "DEBUG", RevertAfter: 20 * time.Millisecond})
+	assert.Equal(t, log.LevelDebug, response.Level)
+	assert.Equal(t, log.LevelWarn, response.RevertTo)
+	assert.NotNil(t, response.RevertAt)
+
+	assert.Eventually(t, func() bool {
+		return c.GetLogLevel(admin, &param.GetLogLevelRequest{}).LogLevel == param.LogLevel{Level: log.LevelWarn}
+	}, time.Second, 5*time.Millisecond)
+	assert.Equal(t, log.LevelWarn, logger.GetLevel())
+}
+
+func TestLogLevelController_SetLogLevel_CancelsRevert(t *testing.T) {
+	logger := log.NewMock("log-level")
+	logger.SetLevel(log.LevelInfo)
+	c := NewLogLevelController(logger, policy.New())
+	admin := auth.WithPrincipal(context.Background(), auth.NewUserPrincipal(1, entity.RoleAdmin, nil, auth.MethodSession))
+
+	c.SetLogLevel(admin, &param.SetLogLevelRequest{Level: "debug", RevertAfter: 10 * time.Millisecond})
+	response := c.SetLogLevel(admin, &param.SetLogLevelRequest{Level: "error"})
+	assert.Equal(t, param.LogLevel{Level: log.LevelError}, response.LogLevel)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, param.LogLevel{Level: log.LevelError}, c.GetLogLevel(admin, &param.GetLogLevelRequest{}).LogLevel)
+}
@@ -2,26 +2,196 @@ package controller
 
 import (
 	"context"
+	"go-structure-demo/internal/apperror"
 	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
 	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
 	"net/http"
+	"strings"
 )
 
+var _ contract.UserController = (*UserController)(nil)
+
 type UserController struct {
-	userStore contract.UserStore
+	logger           log.Logger
+	transactor       contract.Transactor
+	userStore        contract.UserStore
+	outboxStore      contract.OutboxStore
+	authorizer       contract.Authorizer
+	emailVerifier    contract.EmailVerifier
+	riderSyncer      contract.RiderSyncer
+	webhookPublisher contract.WebhookPublisher
 }
 
-func NewUserController(userStore contract.UserStore) *UserController {
+func NewUserController(
+	logger log.Logger,
+	transactor contract.Transactor,
+	userStore contract.UserStore,
+	outboxStore contract.OutboxStore,
+	authorizer contract.Authorizer,
+	emailVerifier contract.EmailVerifier,
+	riderSyncer contract.RiderSyncer,
+	webhookPublisher contract.WebhookPublisher,
+) *UserController {
 	return &UserController{
-		userStore: userStore,
+		logger:           logger,
+		transactor:       transactor,
+		userStore:        userStore,
+		outboxStore:      outboxStore,
+		authorizer:       authorizer,
+		emailVerifier:    emailVerifier,
+		riderSyncer:      riderSyncer,
+		webhookPublisher: webhookPublisher,
 	}
 }
 
 func (c *UserController) CreateUser(ctx context.Context, request *param.CreateUserRequest) param.CreateUserResponse {
-	//call everything needed, validation, authorization, creation,...
+	if err := c.authorizer.Authorize(ctx, policy.ActionUserCreate, nil); err != nil {
+		return param.CreateUserResponse{
+			Message:    "user creation failed",
+			Error:      err,
+			StatusCode: apperror.HTTPStatus(err),
+		}
+	}
+
+	var user entity.User
+	err := c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = c.userStore.CreateUser(ctx, request)
+		if err != nil {
+			return err
+		}
+
+		// the event is committed together with the user, the outbox relay publishes it
+		event, err := entity.NewUserCreatedEvent(user)
+		if err != nil {
+			return err
+		}
+		if err := c.outboxStore.AddOutboxEvent(ctx, event); err != nil {
+			return err
+		}
+		return c.webhookPublisher.PublishWebhookEvent(ctx, entity.EventUserCreated, user.ToMap())
+	})
+	if err != nil {
+		return param.CreateUserResponse{
+			Message:    "user creation failed",
+			Error:      err,
+			StatusCode: http.StatusInternalServerError,
+		}
+	}
+
+	c.sendEmailVerification(ctx, user)
+	// the sync gets a single short attempt, a failed one is retried in the background and the
+	// user exists either way
+	user, _ = c.riderSyncer.SyncRider(ctx, user)
+
 	return param.CreateUserResponse{
 		Message:    "user created!",
+		User:       user,
 		Error:      nil,
 		StatusCode: http.StatusCreated,
 	}
 }
+
+// GetUser authorizes on the id before loading the user, so the callers without access can't
+// tell the missing users from the existing ones.
+func (c *UserController) GetUser(ctx context.Context, request *param.GetUserRequest) param.GetUserResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionUserRead, entity.User{ID: request.ID}); err != nil {
+		return param.GetUserResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	user, err := c.userStore.GetUserByID(ctx, request.ID)
+	if err != nil {
+		return param.GetUserResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.GetUserResponse{User: user, StatusCode: http.StatusOK}
+}
+
+func (c *UserController) UpdateUser(ctx context.Context, request *param.UpdateUserRequest) param.UpdateUserResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionUserUpdate, entity.User{ID: request.ID}); err != nil {
+		return param.UpdateUserResponse{Message: "user update failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	user, err := c.userStore.GetUserByID(ctx, request.ID)
+	if err != nil {
+		return param.UpdateUserResponse{Message: "user update failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	// the role is not part of the request, nobody can promote themselves
+	emailChanged := !strings.EqualFold(user.Email, request.Email)
+	if emailChanged {
+		user.EmailVerifiedAt = nil
+	}
+	user.Email = request.Email
+	user.FirstName = request.FirstName
+	user.LastName = request.LastName
+	user.Gender = request.Gender
+	err = c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		user, err = c.userStore.UpdateUser(ctx, user)
+		if err != nil {
+			return err
+		}
+		return c.webhookPublisher.PublishWebhookEvent(ctx, entity.EventUserUpdated, user.ToMap())
+	})
+	if err != nil {
+		return param.UpdateUserResponse{Message: "user update failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+	if emailChanged {
+		c.sendEmailVerification(ctx, user)
+	}
+
+	return param.UpdateUserResponse{Message: "user updated!", User: user, StatusCode: http.StatusOK}
+}
+
+func (c *UserController) DeleteUser(ctx context.Context, request *param.DeleteUserRequest) param.DeleteUserResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionUserDelete, entity.User{ID: request.ID}); err != nil {
+		return param.DeleteUserResponse{Message: "user deletion failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	err := c.transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+		if err := c.userStore.DeleteUser(ctx, request.ID); err != nil {
+			return err
+		}
+		return c.webhookPublisher.PublishWebhookEvent(ctx, entity.EventUserDeleted, map[string]interface{}{"id": request.ID})
+	})
+	if err != nil {
+		return param.DeleteUserResponse{Message: "user deletion failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.DeleteUserResponse{Message: "user deleted!", StatusCode: http.StatusOK}
+}
+
+// SyncRider lets the admins trigger the rider sync of a user again, like after fixing the
+// data the rider profile service rejected.
+func (c *UserController) SyncRider(ctx context.Context, request *param.SyncRiderRequest) param.SyncRiderResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionUserSyncRider, entity.User{ID: request.ID}); err != nil {
+		return param.SyncRiderResponse{Message: "rider sync failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	user, err := c.userStore.GetUserByID(ctx, request.ID)
+	if err != nil {
+		return param.SyncRiderResponse{Message: "rider sync failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	user, err = c.riderSyncer.SyncRider(ctx, user)
+	if err != nil {
+		err = apperror.Wrap(apperror.KindBadGateway, err, "rider profile sync failed, it is "+user.RiderSyncStatus)
+		return param.SyncRiderResponse{Message: "rider sync failed", User: user, Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.SyncRiderResponse{Message: "rider synced!", User: user, StatusCode: http.StatusOK}
+}
+
+// sendEmailVerification doesn't fail the caller, the user can ask for another link.
+func (c *UserController) sendEmailVerification(ctx context.Context, user entity.User) {
+	if err := c.emailVerifier.SendEmailVerification(ctx, user); err != nil {
+		c.logger.ErrorWithContext(ctx, "sending email verification failed", map[string]interface{}{
+			"user_id":    user.ID,
+			log.KeyError: err.Error(),
+		})
+	}
+}
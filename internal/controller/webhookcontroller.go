@@ -0,0 +1,196 @@
+package controller
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
+	"go-structure-demo/internal/webhook"
+	"net/http"
+	"time"
+)
+
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 500
+)
+
+var _ contract.WebhookController = (*WebhookController)(nil)
+
+type WebhookController struct {
+	logger        log.Logger
+	webhookStore  contract.WebhookStore
+	deliveryStore contract.WebhookDeliveryStore
+	authorizer    contract.Authorizer
+	now           func() time.Time
+}
+
+func NewWebhookController(
+	logger log.Logger,
+	webhookStore contract.WebhookStore,
+	deliveryStore contract.WebhookDeliveryStore,
+	authorizer contract.Authorizer,
+) *WebhookController {
+	return &WebhookController{
+		logger:        logger,
+		webhookStore:  webhookStore,
+		deliveryStore: deliveryStore,
+		authorizer:    authorizer,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// CreateWebhook generates the secret when the request has none, either way it is in the
+// response only.
+func (c *WebhookController) CreateWebhook(ctx context.Context, request *param.CreateWebhookRequest) param.CreateWebhookResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionWebhookCreate, nil); err != nil {
+		return param.CreateWebhookResponse{Message: "webhook creation failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	secret := request.Secret
+	if secret == "" {
+		var err error
+		if secret, err = webhook.NewSecret(); err != nil {
+			return param.CreateWebhookResponse{Message: "webhook creation failed", Error: err, StatusCode: http.StatusInternalServerError}
+		}
+	}
+	active := request.Active == nil || *request.Active
+	created, err := c.webhookStore.CreateWebhook(ctx, entity.Webhook{
+		URL:    request.URL,
+		Secret: secret,
+		Events: request.Events,
+		Active: active,
+	})
+	if err != nil {
+		return param.CreateWebhookResponse{Message: "webhook creation failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	principal, _ := auth.PrincipalFrom(ctx)
+	c.logger.InfoWithContext(ctx, "webhook created", map[string]interface{}{
+		"webhook_id": created.ID,
+		"events":     created.Events,
+		"created_by": principal.Subject,
+	})
+
+	return param.CreateWebhookResponse{Message: "webhook created!", Secret: secret, Webhook: created, StatusCode: http.StatusCreated}
+}
+
+func (c *WebhookController) ListWebhooks(ctx context.Context, request *param.ListWebhooksRequest) param.ListWebhooksResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionWebhookRead, nil); err != nil {
+		return param.ListWebhooksResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	webhooks, err := c.webhookStore.ListWebhooks(ctx)
+	if err != nil {
+		return param.ListWebhooksResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.ListWebhooksResponse{Webhooks: webhooks, StatusCode: http.StatusOK}
+}
+
+// UpdateWebhook is how a disabled webhook is enabled again, its failures start over.
+func (c *WebhookController) UpdateWebhook(ctx context.Context, request *param.UpdateWebhookRequest) param.UpdateWebhookResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionWebhookUpdate, nil); err != nil {
+		return param.UpdateWebhookResponse{Message: "webhook update failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	updated, err := c.webhookStore.UpdateWebhook(ctx, entity.Webhook{
+		ID:     request.ID,
+		URL:    request.URL,
+		Events: request.Events,
+		Active: request.Active,
+	})
+	if err != nil {
+		return param.UpdateWebhookResponse{Message: "webhook update failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.UpdateWebhookResponse{Message: "webhook updated!", Webhook: updated, StatusCode: http.StatusOK}
+}
+
+// DeleteWebhook deletes the deliveries of the webhook as well.
+func (c *WebhookController) DeleteWebhook(ctx context.Context, request *param.DeleteWebhookRequest) param.DeleteWebhookResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionWebhookDelete, nil); err != nil {
+		return param.DeleteWebhookResponse{Message: "webhook deletion failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	if err := c.webhookStore.DeleteWebhook(ctx, request.ID); err != nil {
+		return param.DeleteWebhookResponse{Message: "webhook deletion failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	principal, _ := auth.PrincipalFrom(ctx)
+	c.logger.InfoWithContext(ctx, "webhook deleted", map[string]interface{}{
+		"webhook_id": request.ID,
+		"deleted_by": principal.Subject,
+	})
+
+	return param.DeleteWebhookResponse{Message: "webhook deleted!", StatusCode: http.StatusOK}
+}
+
+// ListWebhookDeliveries returns the delivery log of the webhook, newest first.
+func (c *WebhookController) ListWebhookDeliveries(ctx context.Context, request *param.ListWebhookDeliveriesRequest) param.ListWebhookDeliveriesResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionWebhookRead, nil); err != nil {
+		return param.ListWebhookDeliveriesResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	if _, err := c.webhookStore.GetWebhook(ctx, request.WebhookID); err != nil {
+		return param.ListWebhookDeliveriesResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+	limit := request.Limit
+	if limit <= 0 {
+		limit = defaultWebhookDeliveriesLimit
+	}
+	if limit > maxWebhookDeliveriesLimit {
+		limit = maxWebhookDeliveriesLimit
+	}
+	deliveries, err := c.deliveryStore.ListWebhookDeliveries(ctx, request.WebhookID, limit)
+	if err != nil {
+		return param.ListWebhookDeliveriesResponse{Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	return param.ListWebhookDeliveriesResponse{Deliveries: deliveries, StatusCode: http.StatusOK}
+}
+
+// RedeliverWebhook queues a new delivery of the same event, the one redelivered stays in the
+// log as it is. The redeliveries of a disabled webhook wait until it is enabled again.
+func (c *WebhookController) RedeliverWebhook(ctx context.Context, request *param.RedeliverWebhookRequest) param.RedeliverWebhookResponse {
+	if err := c.authorizer.Authorize(ctx, policy.ActionWebhookRedeliver, nil); err != nil {
+		return param.RedeliverWebhookResponse{Message: "webhook redelivery failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	delivery, err := c.deliveryStore.GetWebhookDelivery(ctx, request.WebhookID, request.DeliveryID)
+	if err != nil {
+		return param.RedeliverWebhookResponse{Message: "webhook redelivery failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	// a redelivery of a redelivery repeats the first delivery
+	redeliveryOf := delivery.ID
+	if delivery.RedeliveryOf != nil {
+		redeliveryOf = *delivery.RedeliveryOf
+	}
+	now := c.now()
+	redelivery, err := c.deliveryStore.AddWebhookDelivery(ctx, entity.WebhookDelivery{
+		WebhookID:     delivery.WebhookID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		RedeliveryOf:  &redeliveryOf,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	})
+	if err != nil {
+		return param.RedeliverWebhookResponse{Message: "webhook redelivery failed", Error: err, StatusCode: apperror.HTTPStatus(err)}
+	}
+
+	principal, _ := auth.PrincipalFrom(ctx)
+	c.logger.InfoWithContext(ctx, "webhook redelivery queued", map[string]interface{}{
+		"webhook_id":     delivery.WebhookID,
+		"delivery_id":    redelivery.ID,
+		"redelivery_of":  redeliveryOf,
+		"redelivered_by": principal.Subject,
+	})
+
+	return param.RedeliverWebhookResponse{Message: "webhook redelivery queued!", Delivery: redelivery, StatusCode: http.StatusAccepted}
+}
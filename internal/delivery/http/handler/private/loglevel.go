@@ -0,0 +1,50 @@
+package private
+
+import (
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/http/response"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/validator"
+	"net/http"
+)
+
+func GetLogLevel(logLevelController contract.LogLevelController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestDTO := new(param.GetLogLevelRequest)
+		if err := requestDTO.BindFromChi(r); err != nil {
+			response.WriteProblem(w, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		responseDTO := logLevelController.GetLogLevel(r.Context(), requestDTO)
+		if responseDTO.Error != nil {
+			response.WriteError(w, responseDTO.Error)
+			return
+		}
+
+		response.WriteJSON(w, responseDTO.StatusCode, responseDTO)
+	}
+}
+
+func SetLogLevel(logLevelController contract.LogLevelController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestDTO := new(param.SetLogLevelRequest)
+		if err := requestDTO.BindFromChi(r); err != nil {
+			response.WriteProblem(w, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		if err := validator.SetLogLevelRequest(r.Context(), requestDTO); err != nil {
+			response.WriteProblem(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		responseDTO := logLevelController.SetLogLevel(r.Context(), requestDTO)
+		if responseDTO.Error != nil {
+			response.WriteError(w, responseDTO.Error)
+			return
+		}
+
+		response.WriteJSON(w, responseDTO.StatusCode, responseDTO)
+	}
+}
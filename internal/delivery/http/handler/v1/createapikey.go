@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/http/response"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/validator"
+	"net/http"
+)
+
+func CreateAPIKey(apiKeyController contract.APIKeyController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestDTO := new(param.CreateAPIKeyRequest)
+		if err := requestDTO.BindFromChi(r); err != nil {
+			response.WriteProblem(w, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		if err := validator.CreateAPIKeyRequest(r.Context(), requestDTO); err != nil {
+			response.WriteProblem(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		responseDTO := apiKeyController.CreateAPIKey(r.Context(), requestDTO)
+		if responseDTO.Error != nil {
+			response.WriteError(w, responseDTO.Error)
+			return
+		}
+
+		response.WriteJSON(w, responseDTO.StatusCode, responseDTO)
+	}
+}
@@ -1,14 +1,13 @@
 package v1
 
 import (
-	"go-structure-demo/internal/controller"
+	"go-structure-demo/internal/contract"
 	"go-structure-demo/internal/param"
-	"go-structure-demo/internal/repository/postgresrepo"
 	"go-structure-demo/internal/validator"
 	"net/http"
 )
 
-func CreateUser(postgresRepo *postgresrepo.PostgresRepo) http.HandlerFunc {
+func CreateUser(userController contract.UserController, validatorStore contract.ValidatorStore) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		requestDTO := new(param.CreateUserRequest)
 
@@ -18,13 +17,13 @@ func CreateUser(postgresRepo *postgresrepo.PostgresRepo) http.HandlerFunc {
 			return
 		}
 
-		err = validator.CreateUserRequest(r.Context(), requestDTO, postgresRepo)
+		err = validator.CreateUserRequest(r.Context(), requestDTO, validatorStore)
 		if err != nil {
 			w.WriteHeader(http.StatusUnprocessableEntity)
 			return
 		}
 
-		responseDTO := controller.NewUserController(postgresRepo).CreateUser(r.Context(), requestDTO)
+		responseDTO := userController.CreateUser(r.Context(), requestDTO)
 		if responseDTO.Error != nil {
 			w.WriteHeader(responseDTO.StatusCode)
 			_, _ = w.Write([]byte("the error is" + responseDTO.Error.Error()))
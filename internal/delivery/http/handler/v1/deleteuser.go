@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/http/response"
+	"go-structure-demo/internal/param"
+	"net/http"
+)
+
+func DeleteUser(userController contract.UserController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestDTO := new(param.DeleteUserRequest)
+		if err := requestDTO.BindFromChi(r); err != nil {
+			response.WriteProblem(w, http.StatusBadRequest, "invalid user id")
+			return
+		}
+
+		responseDTO := userController.DeleteUser(r.Context(), requestDTO)
+		if responseDTO.Error != nil {
+			response.WriteError(w, responseDTO.Error)
+			return
+		}
+
+		response.WriteJSON(w, responseDTO.StatusCode, responseDTO)
+	}
+}
@@ -0,0 +1,26 @@
+package v1
+
+import (
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/http/response"
+	"go-structure-demo/internal/param"
+	"net/http"
+)
+
+func DeleteWebhook(webhookController contract.WebhookController) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestDTO := new(param.DeleteWebhookRequest)
+		if err := requestDTO.BindFromChi(r); err != nil {
+			response.WriteProblem(w, http.StatusBadRequest, "invalid webhook id")
+			return
+		}
+
+		responseDTO := webhookController.DeleteWebhook(r.Context(), requestDTO)
+		if responseDTO.Error != nil {
+			response.WriteError(w, responseDTO.Error)
+			return
+		}
+
+		response.WriteJSON(w, responseDTO.StatusCode, responseDTO)
+	}
+}
@@ -0,0 +1,32 @@
+package v1
+
+import (
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/http/response"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/validator"
+	"net/http"
+)
+
+func UpdateUser(userController contract.UserController, validatorStore contract.ValidatorStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		requestDTO := new(param.UpdateUserRequest)
+		if err := requestDTO.BindFromChi(r); err != nil {
+			response.WriteProblem(w, http.StatusBadRequest, "invalid request")
+			return
+		}
+
+		if err := validator.UpdateUserRequest(r.Context(), requestDTO, validatorStore); err != nil {
+			response.WriteProblem(w, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
+		responseDTO := userController.UpdateUser(r.Context(), requestDTO)
+		if responseDTO.Error != nil {
+			response.WriteError(w, responseDTO.Error)
+			return
+		}
+
+		response.WriteJSON(w, responseDTO.StatusCode, responseDTO)
+	}
+}
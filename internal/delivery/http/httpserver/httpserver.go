@@ -3,29 +3,88 @@ package httpserver
 import (
 	"context"
 	"fmt"
+	"go-structure-demo/internal/auth"
 	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
 	"go-structure-demo/internal/delivery/http/handler/private"
 	v1 "go-structure-demo/internal/delivery/http/handler/v1"
+	"go-structure-demo/internal/delivery/http/middleware"
 	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
 	"go-structure-demo/internal/pubsub"
 	"go-structure-demo/internal/repository/postgresrepo"
 	"go-structure-demo/internal/repository/redisrepo"
 	"net/http"
 	"os"
+
+	"github.com/go-chi/chi/v5"
 )
 
 func New(
 	cfg *config.Config,
 	logger log.Logger,
+	metricsClient metrics.Metrics,
 	redisRepo *redisrepo.RedisRepo,
 	postgresRepo *postgresrepo.PostgresRepo,
+	userStore contract.UserStore,
+	userController contract.UserController,
+	authController contract.AuthController,
+	apiKeyController contract.APIKeyController,
+	webhookController contract.WebhookController,
+	logLevelController contract.LogLevelController,
 	pubsubClientA *pubsub.GCPClient,
 	pubsubClientB *pubsub.GCPClient,
 ) *Server {
+	authenticator, err := auth.NewAuthenticator(cfg.Auth, redisRepo, userStore, postgresRepo)
+	if err != nil {
+		logger.Fatal("initializing authentication", err)
+	}
+
+	trustedProxies, err := middleware.ParseCIDRs(cfg.HTTP.TrustedProxies)
+	if err != nil {
+		logger.Fatal("parsing the trusted proxies", err)
+	}
+
 	router := newRouter(cfg, logger)
+	rateLimit := rateLimiter(cfg, logger, metricsClient, redisRepo.Adapter())
+	// the clients are limited by their address before the credentials are checked, so the
+	// invalid ones are limited as well
+	router.Use(middleware.RealIP(trustedProxies), rateLimit("global"))
+	router.Use(middleware.Authenticate(authenticator, logger))
 
+	// public routes
 	router.Get("/health", private.Health(redisRepo, postgresRepo, pubsubClientA, pubsubClientB))
-	router.Post("/v1/user", v1.CreateUser(postgresRepo))
+	router.Group(func(router chi.Router) {
+		router.Use(rateLimit("auth"))
+		router.Post("/v1/auth/verify-email/request", v1.RequestEmailVerification(authController))
+		router.Post("/v1/auth/verify-email", v1.VerifyEmail(authController))
+		router.Post("/v1/auth/magic-link", v1.RequestMagicLink(authController))
+		router.Post("/v1/auth/magic-link/redeem", v1.RedeemMagicLink(authController))
+	})
+
+	// authenticated routes
+	router.Group(func(router chi.Router) {
+		router.Use(middleware.RequireAuth())
+		router.With(rateLimit("create_user")).Post("/v1/user", v1.CreateUser(userController, postgresRepo))
+		router.Get("/v1/user/{id}", v1.GetUser(userController))
+		router.Put("/v1/user/{id}", v1.UpdateUser(userController, postgresRepo))
+		router.Delete("/v1/user/{id}", v1.DeleteUser(userController))
+
+		router.Post("/v1/admin/api-keys", v1.CreateAPIKey(apiKeyController))
+		router.Get("/v1/admin/api-keys", v1.ListAPIKeys(apiKeyController))
+		router.Delete("/v1/admin/api-keys/{id}", v1.RevokeAPIKey(apiKeyController))
+		router.Post("/v1/admin/users/{id}/rider-sync", v1.SyncRider(userController))
+
+		router.Post("/v1/admin/webhooks", v1.CreateWebhook(webhookController))
+		router.Get("/v1/admin/webhooks", v1.ListWebhooks(webhookController))
+		router.Put("/v1/admin/webhooks/{id}", v1.UpdateWebhook(webhookController))
+		router.Delete("/v1/admin/webhooks/{id}", v1.DeleteWebhook(webhookController))
+		router.Get("/v1/admin/webhooks/{id}/deliveries", v1.ListWebhookDeliveries(webhookController))
+		router.Post("/v1/admin/webhooks/{id}/deliveries/{delivery_id}/redeliver", v1.RedeliverWebhook(webhookController))
+
+		router.Get("/admin/log-level", private.GetLogLevel(logLevelController))
+		router.Put("/admin/log-level", private.SetLogLevel(logLevelController))
+	})
 
 	return &Server{
 		logger: logger,
@@ -0,0 +1,49 @@
+package httpserver
+
+import (
+	"go-structure-demo/internal/adapter/redis"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/delivery/http/middleware"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/ratelimit"
+	"net/http"
+)
+
+// rateLimiter returns the rate limit middleware of a route name, the routes without a rule
+// are not limited.
+func rateLimiter(
+	cfg *config.Config,
+	logger log.Logger,
+	metricsClient metrics.Metrics,
+	adapter redis.Adapter,
+) func(route string) func(http.Handler) http.Handler {
+	keyFuncs := map[string]middleware.KeyFunc{
+		middleware.KeyIP:     middleware.KeyByIP,
+		middleware.KeyAPIKey: middleware.KeyByAPIKey,
+		middleware.KeyUser: middleware.KeyByUser(func(r *http.Request) (string, bool) {
+			principal, ok := auth.PrincipalFrom(r.Context())
+			return principal.Subject, ok
+		}),
+	}
+
+	return func(route string) func(http.Handler) http.Handler {
+		rule, ok := cfg.RateLimit.Routes[route]
+		if !cfg.RateLimit.Enabled || !ok {
+			return func(next http.Handler) http.Handler {
+				return next
+			}
+		}
+
+		limiter, err := ratelimit.New(rule.Algorithm, adapter)
+		if err != nil {
+			logger.Fatal("rate limit of "+route, err)
+		}
+		keyFunc, ok := keyFuncs[rule.Key]
+		if !ok {
+			logger.Fatal("rate limit of "+route+" has an unknown key", rule.Key)
+		}
+		return middleware.RateLimit(cfg.RateLimit, route, logger, metricsClient, limiter, keyFunc)
+	}
+}
@@ -0,0 +1,91 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/delivery/http/response"
+	"go-structure-demo/internal/log"
+	"net/http"
+	"strings"
+)
+
+// Authenticator resolves the credentials of a request to its principal.
+type Authenticator interface {
+	Authenticate(ctx context.Context, token string) (auth.Principal, error)
+	AuthenticateAPIKey(ctx context.Context, key string) (auth.Principal, error)
+}
+
+// Authenticate puts the principal of the bearer token or of the X-API-Key header in the
+// request context, the bearer token wins when both are sent. The requests without
+// credentials go through anonymously, the ones with invalid credentials get a 401. When the
+// credentials can't be checked, like when a store is down, the request gets a 503 so the
+// clients don't drop valid credentials.
+func Authenticate(authenticator Authenticator, logger log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var principal auth.Principal
+			var err error
+			if token, ok := bearerToken(r); ok {
+				principal, err = authenticator.Authenticate(r.Context(), token)
+			} else if key := r.Header.Get(HeaderAPIKey); key != "" {
+				principal, err = authenticator.AuthenticateAPIKey(r.Context(), key)
+			} else {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if errors.Is(err, auth.ErrInvalidToken) {
+				logger.DebugWithContext(r.Context(), "authentication failed", map[string]interface{}{
+					log.KeyError: err.Error(),
+				})
+				w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+				response.WriteProblem(w, http.StatusUnauthorized, "invalid credentials")
+				return
+			}
+			if err != nil {
+				logger.ErrorWithContext(r.Context(), "authentication is not available", map[string]interface{}{
+					log.KeyError: err.Error(),
+				})
+				response.WriteProblem(w, http.StatusServiceUnavailable, "authentication is not available")
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(r.Context(), principal)))
+		})
+	}
+}
+
+// RequireAuth rejects the anonymous requests with a 401 and the ones missing any of the
+// scopes with a 403, the routes without it are public.
+func RequireAuth(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.PrincipalFrom(r.Context())
+			if !ok {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				response.WriteProblem(w, http.StatusUnauthorized, "authentication required")
+				return
+			}
+			for _, scope := range scopes {
+				if !principal.HasScope(scope) {
+					response.WriteProblem(w, http.StatusForbidden, "missing scope "+scope)
+					return
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", false
+	}
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", true
+	}
+	return strings.TrimSpace(token), true
+}
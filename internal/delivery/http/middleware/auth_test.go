@@ -0,0 +1,95 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tokenAuthenticator map[string]auth.Principal
+
+func (a tokenAuthenticator) Authenticate(ctx context.Context, token string) (auth.Principal, error) {
+	if token == "unavailable" {
+		return auth.Principal{}, errors.New("connection refused")
+	}
+	principal, ok := a[token]
+	if !ok {
+		return auth.Principal{}, auth.ErrInvalidToken
+	}
+	return principal, nil
+}
+
+func (a tokenAuthenticator) AuthenticateAPIKey(ctx context.Context, key string) (auth.Principal, error) {
+	return a.Authenticate(ctx, key)
+}
+
+func TestAuthenticate(t *testing.T) {
+	authenticator := tokenAuthenticator{
+		"user-token":  auth.NewUserPrincipal(42, "user", []string{"user"}, auth.MethodSession),
+		"admin-token": auth.NewUserPrincipal(1, "admin", []string{"user", "admin"}, auth.MethodJWT),
+		"api-key":     {Subject: "api_key:1", Role: auth.RoleService, Scopes: []string{"admin"}, Method: auth.MethodAPIKey},
+	}
+
+	testCases := []struct {
+		name          string
+		authorization string
+		apiKey        string
+		scopes        []string
+		shouldStatus  int
+		shouldHeader  string
+	}{
+		{name: "anonymous", shouldStatus: http.StatusUnauthorized, shouldHeader: "Bearer"},
+		{name: "invalid_token", authorization: "Bearer unknown", shouldStatus: http.StatusUnauthorized, shouldHeader: `Bearer error="invalid_token"`},
+		{name: "other_scheme", authorization: "Basic dXNlcjpwYXNz", shouldStatus: http.StatusUnauthorized, shouldHeader: `Bearer error="invalid_token"`},
+		{name: "authenticated", authorization: "Bearer user-token", shouldStatus: http.StatusOK},
+		{name: "lowercase_scheme", authorization: "bearer user-token", shouldStatus: http.StatusOK},
+		{name: "missing_scope", authorization: "Bearer user-token", scopes: []string{"admin"}, shouldStatus: http.StatusForbidden},
+		{name: "with_scope", authorization: "Bearer admin-token", scopes: []string{"admin"}, shouldStatus: http.StatusOK},
+		{name: "api_key", apiKey: "api-key", scopes: []string{"admin"}, shouldStatus: http.StatusOK},
+		{name: "invalid_api_key", apiKey: "unknown", shouldStatus: http.StatusUnauthorized, shouldHeader: `Bearer error="invalid_token"`},
+		{name: "store_down", authorization: "Bearer unavailable", shouldStatus: http.StatusServiceUnavailable},
+		{name: "store_down_api_key", apiKey: "unavailable", shouldStatus: http.StatusServiceUnavailable},
+		{name: "bearer_wins", authorization: "Bearer user-token", apiKey: "api-key", scopes: []string{"admin"}, shouldStatus: http.StatusForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_, ok := auth.PrincipalFrom(r.Context())
+				assert.True(t, ok)
+			})
+			handler := Authenticate(authenticator, log.NewMock("http"))(RequireAuth(tc.scopes...)(next))
+
+			recorder := httptest.NewRecorder()
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tc.authorization != "" {
+				request.Header.Set("Authorization", tc.authorization)
+			}
+			if tc.apiKey != "" {
+				request.Header.Set(HeaderAPIKey, tc.apiKey)
+			}
+			handler.ServeHTTP(recorder, request)
+
+			assert.Equal(t, tc.shouldStatus, recorder.Code)
+			assert.Equal(t, tc.shouldHeader, recorder.Header().Get("WWW-Authenticate"))
+		})
+	}
+}
+
+func TestAuthenticate_Public(t *testing.T) {
+	authenticated := true
+	handler := Authenticate(tokenAuthenticator{}, log.NewMock("http"))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, authenticated = auth.PrincipalFrom(r.Context())
+	}))
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/health", nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.False(t, authenticated)
+}
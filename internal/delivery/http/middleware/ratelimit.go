@@ -0,0 +1,109 @@
+package middleware
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/delivery/http/response"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/ratelimit"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	KeyIP     = "ip"
+	KeyUser   = "user"
+	KeyAPIKey = "api_key"
+
+	HeaderAPIKey = "X-API-Key"
+
+	metricRateLimited   = "http.ratelimit.limited"
+	metricRateLimitFail = "http.ratelimit.failed"
+)
+
+// KeyFunc returns the key the request is limited by, false skips the limit for it.
+type KeyFunc func(r *http.Request) (string, bool)
+
+func KeyByIP(r *http.Request) (string, bool) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr, r.RemoteAddr != ""
+	}
+	return host, true
+}
+
+// KeyByAPIKey limits by a hash of the api key, the key itself never reaches redis.
+func KeyByAPIKey(r *http.Request) (string, bool) {
+	apiKey := r.Header.Get(HeaderAPIKey)
+	if apiKey == "" {
+		return "", false
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:]), true
+}
+
+// KeyByUser limits by the user the extractor finds in the request.
+func KeyByUser(userID func(r *http.Request) (string, bool)) KeyFunc {
+	return userID
+}
+
+// RateLimit rejects the requests above the limit of the route with a 429. When the limiter
+// fails the request goes through if FailOpen is set and gets a 503 otherwise.
+func RateLimit(
+	cfg config.RateLimit,
+	route string,
+	logger log.Logger,
+	metricsClient metrics.Metrics,
+	limiter ratelimit.Limiter,
+	keyFunc KeyFunc,
+) func(http.Handler) http.Handler {
+	rule := cfg.Routes[route]
+	limit := ratelimit.Limit{Rate: rule.Rate, Period: rule.Period, Burst: rule.Burst}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, ok := keyFunc(r)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			result, err := limiter.Allow(r.Context(), route+":"+rule.Key+":"+key, limit)
+			if err != nil {
+				metricsClient.Count(metricRateLimitFail, 1, metrics.Tag("route", route))
+				logger.ErrorWithContext(r.Context(), "rate limiting failed", map[string]interface{}{
+					"route":      route,
+					log.KeyError: err.Error(),
+				})
+				if cfg.FailOpen {
+					next.ServeHTTP(w, r)
+					return
+				}
+				response.WriteProblem(w, http.StatusServiceUnavailable, "rate limiting is not available")
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			w.Header().Set("RateLimit-Reset", seconds(result.ResetAfter))
+			if !result.Allowed {
+				metricsClient.Count(metricRateLimited, 1, metrics.Tag("route", route))
+				w.Header().Set("Retry-After", seconds(result.RetryAfter))
+				response.WriteProblem(w, http.StatusTooManyRequests, "rate limit exceeded, retry after "+seconds(result.RetryAfter)+" seconds")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds rounds up, so a client waiting for the value never comes back too early.
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
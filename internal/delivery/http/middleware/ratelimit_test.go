@@ -0,0 +1,105 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/ratelimit"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type failingLimiter struct{}
+
+func (failingLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("connection refused")
+}
+
+func newRateLimitHandler(cfg config.RateLimit, limiter ratelimit.Limiter) (http.Handler, *metrics.Mock) {
+	metricsMock := metrics.NewMock()
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+	return RateLimit(cfg, "create_user", log.NewMock("http"), metricsMock, limiter, KeyByIP)(next), metricsMock
+}
+
+func TestRateLimit(t *testing.T) {
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := ratelimit.NewFixedWindow(redistest.NewMemory(clock.Now), clock.Now)
+	cfg := config.RateLimit{
+		Enabled: true,
+		Routes: map[string]config.RateLimitRule{
+			"create_user": {Algorithm: ratelimit.AlgorithmFixedWindow, Key: KeyIP, Rate: 2, Period: time.Minute},
+		},
+	}
+	handler, metricsMock := newRateLimitHandler(cfg, limiter)
+
+	for i, shouldStatus := range []int{http.StatusCreated, http.StatusCreated, http.StatusTooManyRequests} {
+		recorder := httptest.NewRecorder()
+		request := httptest.NewRequest(http.MethodPost, "/v1/user", nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		handler.ServeHTTP(recorder, request)
+
+		assert.Equal(t, shouldStatus, recorder.Code, i)
+		assert.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "60", recorder.Header().Get("RateLimit-Reset"))
+	}
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodPost, "/v1/user", nil)
+	request.RemoteAddr = "10.0.0.1:4321"
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "60", recorder.Header().Get("Retry-After"))
+	assert.Equal(t, "application/problem+json", recorder.Header().Get("Content-Type"))
+	assert.Contains(t, recorder.Body.String(), `"status":429`)
+	assert.Equal(t, int64(2), metricsMock.Counter(metricRateLimited))
+
+	// another client has its own limit
+	recorder = httptest.NewRecorder()
+	request = httptest.NewRequest(http.MethodPost, "/v1/user", nil)
+	request.RemoteAddr = "10.0.0.2:1234"
+	handler.ServeHTTP(recorder, request)
+	assert.Equal(t, http.StatusCreated, recorder.Code)
+}
+
+func TestRateLimit_LimiterFailure(t *testing.T) {
+	testCases := []struct {
+		name         string
+		failOpen     bool
+		shouldStatus int
+	}{
+		{name: "fail_open", failOpen: true, shouldStatus: http.StatusCreated},
+		{name: "fail_closed", failOpen: false, shouldStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := config.RateLimit{Enabled: true, FailOpen: tc.failOpen}
+			handler, metricsMock := newRateLimitHandler(cfg, failingLimiter{})
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/v1/user", nil))
+			assert.Equal(t, tc.shouldStatus, recorder.Code)
+			assert.Equal(t, int64(1), metricsMock.Counter(metricRateLimitFail))
+		})
+	}
+}
+
+func TestKeyByAPIKey(t *testing.T) {
+	request := httptest.NewRequest(http.MethodPost, "/v1/user", nil)
+	_, ok := KeyByAPIKey(request)
+	assert.False(t, ok)
+
+	request.Header.Set(HeaderAPIKey, "secret")
+	key, ok := KeyByAPIKey(request)
+	assert.True(t, ok)
+	assert.NotContains(t, key, "secret")
+}
@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRealIP(t *testing.T) {
+	trustedProxies, err := ParseCIDRs([]string{"10.0.0.0/8", "35.191.0.1"})
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		shouldIP     string
+	}{
+		{
+			name:       "direct",
+			remoteAddr: "203.0.113.7:1234",
+			shouldIP:   "203.0.113.7",
+		},
+		{
+			name:         "untrusted_peer",
+			remoteAddr:   "203.0.113.7:1234",
+			forwardedFor: []string{"198.51.100.1"},
+			shouldIP:     "203.0.113.7",
+		},
+		{
+			name:         "trusted_proxy",
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"198.51.100.1"},
+			shouldIP:     "198.51.100.1",
+		},
+		{
+			name:         "spoofed_hops",
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"1.2.3.4, 198.51.100.1, 35.191.0.1"},
+			shouldIP:     "198.51.100.1",
+		},
+		{
+			name:         "several_headers",
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"1.2.3.4", "198.51.100.1"},
+			shouldIP:     "198.51.100.1",
+		},
+		{
+			name:         "garbage",
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"198.51.100.1, not-an-ip"},
+			shouldIP:     "10.0.0.2",
+		},
+		{
+			name:         "only_proxies",
+			remoteAddr:   "10.0.0.2:1234",
+			forwardedFor: []string{"10.0.0.3"},
+			shouldIP:     "10.0.0.3",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var ip string
+			handler := RealIP(trustedProxies)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ip, _ = KeyByIP(r)
+			}))
+
+			request := httptest.NewRequest(http.MethodGet, "/", nil)
+			request.RemoteAddr = tc.remoteAddr
+			for _, value := range tc.forwardedFor {
+				request.Header.Add(HeaderForwardedFor, value)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			assert.Equal(t, tc.shouldIP, ip)
+		})
+	}
+}
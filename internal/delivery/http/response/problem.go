@@ -0,0 +1,31 @@
+package response
+
+import (
+	"encoding/json"
+	"go-structure-demo/internal/apperror"
+	"net/http"
+)
+
+// Problem is the error body of every endpoint, see RFC 7807.
+type Problem struct {
+	Type   string `json:"type"`
+	Title  string `json:"title"`
+	Status int    `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+func WriteProblem(w http.ResponseWriter, status int, detail string) {
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+// WriteError writes the problem of an app error, the other errors become a 500 without details.
+func WriteError(w http.ResponseWriter, err error) {
+	WriteProblem(w, apperror.HTTPStatus(err), apperror.Message(err))
+}
@@ -2,22 +2,18 @@ package v1
 
 import (
 	"context"
-	"go-structure-demo/internal/config"
-	"go-structure-demo/internal/controller"
-	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/contract"
 	"go-structure-demo/internal/param"
 	"go-structure-demo/internal/pubsub"
-	"go-structure-demo/internal/repository/postgresrepo"
-	"go-structure-demo/internal/repository/redisrepo"
 	"go-structure-demo/internal/validator"
 )
 
-func CreateUser(cfg *config.Config, logger log.Logger, redisRepo *redisrepo.RedisRepo, postgresRepo *postgresrepo.PostgresRepo) pubsub.MessageHandler {
-	return func(ctx context.Context, bytes []byte) (bool, error) {
-		event := new(UserCreatedEvent)
+func CreateUser(userController contract.UserController, validatorStore contract.ValidatorStore) pubsub.MessageHandler {
+	return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		event := new(param.UserCreatedEvent)
 
 		// un marshall the event
-		err := unmarshalPubSubEvent(bytes, event)
+		err := unmarshalPubSubEvent(msg.Data, event)
 		if err != nil {
 			return true, nil
 		}
@@ -28,12 +24,12 @@ func CreateUser(cfg *config.Config, logger log.Logger, redisRepo *redisrepo.Redi
 			return false, err
 		}
 
-		err = validator.CreateUserRequest(ctx, requestDTO, postgresRepo)
+		err = validator.CreateUserRequest(ctx, requestDTO, validatorStore)
 		if err != nil {
 			return false, err
 		}
 
-		userCreateResponse := controller.NewUserController(postgresRepo).CreateUser(ctx, requestDTO)
+		userCreateResponse := userController.CreateUser(ctx, requestDTO)
 		if userCreateResponse.Error != nil {
 			return false, userCreateResponse.Error
 		}
@@ -42,13 +38,6 @@ func CreateUser(cfg *config.Config, logger log.Logger, redisRepo *redisrepo.Redi
 	}
 }
 
-type UserCreatedEvent struct {
-	Email     string `json:"email,omitempty"`
-	FirstName string `json:"first_name,omitempty"`
-	LastName  string `json:"last_name,omitempty"`
-	Phone     string `json:"phone,omitempty"`
-}
-
-func unmarshalPubSubEvent([]byte, *UserCreatedEvent) error {
+func unmarshalPubSubEvent([]byte, *param.UserCreatedEvent) error {
 	return nil
 }
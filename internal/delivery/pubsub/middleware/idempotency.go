@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/pubsub"
+	"time"
+)
+
+// AttributeIdempotencyKey overrides the message id as the idempotency key, publishers set it
+// when the same event can be published more than once.
+const AttributeIdempotencyKey = "idempotency_key"
+
+const (
+	// defaultLease is the lease of the claims when the handlers have no timeout
+	defaultLease = 5 * time.Minute
+	// storeTimeout bounds the writes made once the handler is done, its context may be
+	// done already
+	storeTimeout = 5 * time.Second
+)
+
+// Idempotency claims the key of the message before handling it, so the concurrent
+// redeliveries of a message don't run the handler twice. The messages already handled with
+// an ack are acked, the ones being handled are nacked and come back once the claim is
+// settled. A handled message is remembered for the ttl, a failed one gives the claim up. The
+// lease must outlast the handler, a claim left by a crashed replica expires after it.
+func Idempotency(store contract.IdempotencyStore, ttl time.Duration, lease time.Duration) router.Middleware {
+	if lease <= 0 {
+		lease = defaultLease
+	}
+	return func(next pubsub.MessageHandler) pubsub.MessageHandler {
+		return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+			key := msg.Attributes[AttributeIdempotencyKey]
+			if key == "" {
+				key = msg.ID
+			}
+
+			claimed, processed, err := store.ClaimProcessing(ctx, key, lease)
+			if err != nil {
+				return false, err
+			}
+			if !claimed {
+				return processed, nil
+			}
+
+			ack, err := next(ctx, msg)
+
+			storeCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+			defer cancel()
+			if ack && err == nil {
+				if err := store.MarkProcessed(storeCtx, key, ttl); err != nil {
+					return true, err
+				}
+				return true, nil
+			}
+			if releaseErr := store.ReleaseProcessing(storeCtx, key); releaseErr != nil && err == nil {
+				err = releaseErr
+			}
+			return ack, err
+		}
+	}
+}
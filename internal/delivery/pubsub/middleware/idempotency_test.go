@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/pubsub"
+	"go-structure-demo/internal/repository/redisrepo"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestIdempotencyStore() (*redisrepo.RedisRepo, *redistest.Clock) {
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	return redisrepo.NewWithAdapter(redistest.NewMemory(clock.Now)), clock
+}
+
+func TestIdempotency(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestIdempotencyStore()
+
+	var calls int32
+	results := []error{errors.New("failed"), nil}
+	handler := Idempotency(store, time.Hour, time.Minute)(func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		err := results[atomic.AddInt32(&calls, 1)-1]
+		return err == nil, err
+	})
+	msg := &pubsub.Message{ID: "1"}
+
+	// a failed message gives its claim up, the redelivery runs the handler again
+	ack, err := handler(ctx, msg)
+	assert.False(t, ack)
+	assert.EqualError(t, err, "failed")
+	ack, err = handler(ctx, msg)
+	assert.True(t, ack)
+	assert.Nil(t, err)
+
+	// a handled message is acked without running the handler
+	ack, err = handler(ctx, msg)
+	assert.True(t, ack)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(2), calls)
+
+	// the key attribute wins over the message id
+	ack, _ = handler(ctx, &pubsub.Message{ID: "2", Attributes: map[string]string{AttributeIdempotencyKey: "1"}})
+	assert.True(t, ack)
+	assert.Equal(t, int32(2), calls)
+}
+
+func TestIdempotency_ConcurrentRedeliveries(t *testing.T) {
+	ctx := context.Background()
+	store, _ := newTestIdempotencyStore()
+
+	var calls int32
+	started := make(chan struct{})
+	release := make(chan struct{})
+	handler := Idempotency(store, time.Hour, time.Minute)(func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		atomic.AddInt32(&calls, 1)
+		close(started)
+		<-release
+		return true, nil
+	})
+	msg := &pubsub.Message{ID: "1"}
+
+	wg := new(sync.WaitGroup)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ack, err := handler(ctx, msg)
+		assert.True(t, ack)
+		assert.Nil(t, err)
+	}()
+	<-started
+
+	// the message being handled is nacked, it comes back later
+	for i := 0; i < 3; i++ {
+		ack, err := handler(ctx, msg)
+		assert.False(t, ack)
+		assert.Nil(t, err)
+	}
+	close(release)
+	wg.Wait()
+
+	ack, err := handler(ctx, msg)
+	assert.True(t, ack)
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestIdempotency_LeaseExpires(t *testing.T) {
+	ctx := context.Background()
+	store, clock := newTestIdempotencyStore()
+
+	// a claim left behind by a crashed handler
+	claimed, _, err := store.ClaimProcessing(ctx, "1", time.Minute)
+	assert.True(t, claimed)
+	assert.Nil(t, err)
+
+	var calls int32
+	handler := Idempotency(store, time.Hour, time.Minute)(func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		atomic.AddInt32(&calls, 1)
+		return true, nil
+	})
+	ack, _ := handler(ctx, &pubsub.Message{ID: "1"})
+	assert.False(t, ack)
+
+	clock.Advance(2 * time.Minute)
+	ack, _ = handler(ctx, &pubsub.Message{ID: "1"})
+	assert.True(t, ack)
+	assert.Equal(t, int32(1), calls)
+}
@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/pubsub"
+	"time"
+)
+
+func Logger(logger log.Logger) router.Middleware {
+	return func(next pubsub.MessageHandler) pubsub.MessageHandler {
+		return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+			start := time.Now()
+			ack, err := next(ctx, msg)
+			fields := map[string]interface{}{
+				"message_id":    msg.ID,
+				"event_type":    msg.Attributes[router.AttributeEventType],
+				"event_version": msg.Attributes[router.AttributeEventVersion],
+				"elapsed":       time.Since(start).Milliseconds(),
+				"ack":           ack,
+			}
+			if err != nil {
+				fields[log.KeyError] = err.Error()
+				logger.ErrorWithContext(ctx, "pubsub message handled", fields)
+			} else {
+				logger.DebugWithContext(ctx, "pubsub message handled", fields)
+			}
+			return ack, err
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/pubsub"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	msg := &pubsub.Message{ID: "1", Attributes: map[string]string{
+		router.AttributeEventType:    "user.created",
+		router.AttributeEventVersion: "1",
+	}}
+
+	testCases := []struct {
+		name        string
+		err         error
+		shouldLevel log.Level
+	}{
+		{
+			name:        "handled",
+			shouldLevel: log.LevelDebug,
+		},
+		{
+			name:        "failed",
+			err:         errors.New("failed"),
+			shouldLevel: log.LevelError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger := log.NewMock("pubsub")
+			logger.SetLevel(log.LevelDebug)
+			handler := Logger(logger)(func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+				return tc.err == nil, tc.err
+			})
+
+			ack, err := handler(context.Background(), msg)
+			assert.Equal(t, tc.err == nil, ack)
+			assert.Equal(t, tc.err, err)
+
+			mock := logger.(*log.MockLogger)
+			assert.Equal(t, tc.shouldLevel, mock.Level)
+			assert.Equal(t, "pubsub message handled", mock.LastMessage)
+			assert.Equal(t, "1", mock.LastItems["message_id"])
+			assert.Equal(t, "user.created", mock.LastItems["event_type"])
+			assert.Equal(t, tc.err == nil, mock.LastItems["ack"])
+			if tc.err != nil {
+				assert.Equal(t, "failed", mock.LastItems[log.KeyError])
+			}
+		})
+	}
+}
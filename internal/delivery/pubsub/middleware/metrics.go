@@ -0,0 +1,27 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/pubsub"
+	"strconv"
+	"time"
+)
+
+func Metrics(m metrics.Metrics) router.Middleware {
+	return func(next pubsub.MessageHandler) pubsub.MessageHandler {
+		return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+			start := time.Now()
+			ack, err := next(ctx, msg)
+			tags := []string{
+				metrics.Tag("event_type", msg.Attributes[router.AttributeEventType]),
+				metrics.Tag("ack", strconv.FormatBool(ack)),
+				metrics.Tag("error", strconv.FormatBool(err != nil)),
+			}
+			m.Count("pubsub.message.handled", 1, tags...)
+			m.Timing("pubsub.message.duration", time.Since(start), tags...)
+			return ack, err
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/pubsub"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	metricsMock := metrics.NewMock()
+	handler := Metrics(metricsMock)(func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		return true, nil
+	})
+
+	for i := 0; i < 2; i++ {
+		ack, err := handler(context.Background(), &pubsub.Message{ID: "1"})
+		assert.True(t, ack)
+		assert.Nil(t, err)
+	}
+
+	assert.Equal(t, int64(2), metricsMock.Counter("pubsub.message.handled"))
+	assert.Len(t, metricsMock.Timings["pubsub.message.duration"], 2)
+}
@@ -0,0 +1,30 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/pubsub"
+	"runtime/debug"
+)
+
+// Recoverer nacks the message when its handler panics.
+func Recoverer(logger log.Logger) router.Middleware {
+	return func(next pubsub.MessageHandler) pubsub.MessageHandler {
+		return func(ctx context.Context, msg *pubsub.Message) (ack bool, err error) {
+			defer func() {
+				if r := recover(); r != nil {
+					logger.ErrorWithContext(ctx, "panic recovered", map[string]interface{}{
+						log.KeyError: r,
+						"message_id": msg.ID,
+						"stacktrace": string(debug.Stack()),
+					})
+					ack, err = false, fmt.Errorf("panic recovered: %v", r)
+				}
+			}()
+
+			return next(ctx, msg)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/pubsub"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoverer(t *testing.T) {
+	logger := log.NewMock("pubsub")
+	handler := Recoverer(logger)(func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		panic("boom")
+	})
+
+	ack, err := handler(context.Background(), &pubsub.Message{ID: "1"})
+	assert.False(t, ack)
+	assert.EqualError(t, err, "panic recovered: boom")
+
+	mock := logger.(*log.MockLogger)
+	assert.Equal(t, "panic recovered", mock.LastMessage)
+	assert.Equal(t, "1", mock.LastItems["message_id"])
+	assert.NotEmpty(t, mock.LastItems["stacktrace"])
+}
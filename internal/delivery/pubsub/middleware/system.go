@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/pubsub"
+)
+
+// System runs the handlers as the system principal, the messages have no caller to
+// authorize but the controllers still check a principal.
+func System() router.Middleware {
+	return func(next pubsub.MessageHandler) pubsub.MessageHandler {
+		return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+			return next(auth.WithPrincipal(ctx, auth.SystemPrincipal()), msg)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/pubsub"
+	"time"
+)
+
+// Timeout cancels the context of the handler after the duration, the handlers have no
+// timeout when it is zero.
+func Timeout(timeout time.Duration) router.Middleware {
+	return func(next pubsub.MessageHandler) pubsub.MessageHandler {
+		if timeout <= 0 {
+			return next
+		}
+		return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, msg)
+		}
+	}
+}
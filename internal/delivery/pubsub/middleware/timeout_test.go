@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"context"
+	"go-structure-demo/internal/pubsub"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTimeout(t *testing.T) {
+	testCases := []struct {
+		name           string
+		timeout        time.Duration
+		shouldDeadline bool
+	}{
+		{
+			name:           "timeout",
+			timeout:        time.Minute,
+			shouldDeadline: true,
+		},
+		{
+			name:           "no_timeout",
+			timeout:        0,
+			shouldDeadline: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := Timeout(tc.timeout)(func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+				_, ok := ctx.Deadline()
+				assert.Equal(t, tc.shouldDeadline, ok)
+				return ctx.Err() == nil, ctx.Err()
+			})
+
+			ack, err := handler(context.Background(), &pubsub.Message{ID: "1"})
+			assert.True(t, ack)
+			assert.Nil(t, err)
+		})
+	}
+}
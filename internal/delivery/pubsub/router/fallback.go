@@ -0,0 +1,39 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/pubsub"
+)
+
+const (
+	FallbackAck        = "ack"
+	FallbackNack       = "nack"
+	FallbackDeadLetter = "dead_letter"
+)
+
+// Ack drops the messages without a route.
+func Ack() pubsub.MessageHandler {
+	return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		return true, noRouteError(msg)
+	}
+}
+
+// Nack asks for the redelivery of the messages without a route.
+func Nack() pubsub.MessageHandler {
+	return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		return false, noRouteError(msg)
+	}
+}
+
+// DeadLetter moves the messages without a route to the topic with their attributes, they are
+// nacked if the publishing fails.
+func DeadLetter(client pubsub.Client, topicID string) pubsub.MessageHandler {
+	return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		result := client.PublishBatch(ctx, topicID, []pubsub.Message{{Data: msg.Data, Attributes: msg.Attributes}})[0]
+		if err := result.Err; err != nil {
+			return false, fmt.Errorf("dead lettering: %v: %w", err, noRouteError(msg))
+		}
+		return true, noRouteError(msg)
+	}
+}
@@ -0,0 +1,86 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go-structure-demo/internal/pubsub"
+)
+
+const (
+	// AttributeEventType is the message attribute the route is matched on
+	AttributeEventType = "event_type"
+	// AttributeEventVersion is the message attribute holding the event version, DefaultVersion when missing
+	AttributeEventVersion = "event_version"
+
+	DefaultVersion = "1"
+)
+
+var ErrNoRoute = errors.New("no route for the message")
+
+// Middleware wraps a handler the same way a chi middleware wraps an http.Handler
+type Middleware func(next pubsub.MessageHandler) pubsub.MessageHandler
+
+type route struct {
+	eventType string
+	version   string
+}
+
+// Router dispatches the messages of a subscription to the handler registered for their
+// event type and version.
+type Router struct {
+	routes      map[route]pubsub.MessageHandler
+	middlewares []Middleware
+	fallback    pubsub.MessageHandler
+}
+
+func New() *Router {
+	return &Router{
+		routes:   make(map[route]pubsub.MessageHandler),
+		fallback: Nack(),
+	}
+}
+
+// Use appends middlewares applied to every route and to the fallback.
+func (r *Router) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// Handle registers the handler for an event type and version, the given middlewares only
+// wrap this handler and run after the router ones.
+func (r *Router) Handle(eventType, version string, handler pubsub.MessageHandler, middlewares ...Middleware) {
+	r.routes[route{eventType: eventType, version: version}] = chain(middlewares, handler)
+}
+
+// Fallback sets the handler of the messages without a route, Nack by default.
+func (r *Router) Fallback(handler pubsub.MessageHandler) {
+	r.fallback = handler
+}
+
+func (r *Router) HandleMessage(ctx context.Context, msg *pubsub.Message) (bool, error) {
+	handler, ok := r.routes[routeOf(msg)]
+	if !ok {
+		handler = r.fallback
+	}
+	return chain(r.middlewares, handler)(ctx, msg)
+}
+
+func routeOf(msg *pubsub.Message) route {
+	version := msg.Attributes[AttributeEventVersion]
+	if version == "" {
+		version = DefaultVersion
+	}
+	return route{eventType: msg.Attributes[AttributeEventType], version: version}
+}
+
+func chain(middlewares []Middleware, handler pubsub.MessageHandler) pubsub.MessageHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+func noRouteError(msg *pubsub.Message) error {
+	r := routeOf(msg)
+	return fmt.Errorf("%w: event type %q version %q", ErrNoRoute, r.eventType, r.version)
+}
@@ -0,0 +1,103 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/pubsub"
+	"go-structure-demo/internal/pubsub/pubsubtest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next pubsub.MessageHandler) pubsub.MessageHandler {
+		return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+			*calls = append(*calls, name)
+			return next(ctx, msg)
+		}
+	}
+}
+
+func handlerNamed(name string, calls *[]string) pubsub.MessageHandler {
+	return func(ctx context.Context, msg *pubsub.Message) (bool, error) {
+		*calls = append(*calls, name)
+		return true, nil
+	}
+}
+
+func TestRouter_HandleMessage(t *testing.T) {
+	calls := make([]string, 0)
+	r := New()
+	r.Use(recordingMiddleware("router", &calls))
+	r.Handle("user.created", "1", handlerNamed("v1", &calls), recordingMiddleware("route", &calls))
+	r.Handle("user.created", "2", handlerNamed("v2", &calls))
+
+	testCases := []struct {
+		name        string
+		attributes  map[string]string
+		shouldAck   bool
+		shouldErr   error
+		shouldCalls []string
+	}{
+		{
+			name:        "matching_version",
+			attributes:  map[string]string{AttributeEventType: "user.created", AttributeEventVersion: "2"},
+			shouldAck:   true,
+			shouldCalls: []string{"router", "v2"},
+		},
+		{
+			name:        "default_version_with_route_middleware",
+			attributes:  map[string]string{AttributeEventType: "user.created"},
+			shouldAck:   true,
+			shouldCalls: []string{"router", "route", "v1"},
+		},
+		{
+			name:        "unknown_type_falls_back",
+			attributes:  map[string]string{AttributeEventType: "user.deleted"},
+			shouldAck:   false,
+			shouldErr:   ErrNoRoute,
+			shouldCalls: []string{"router"},
+		},
+		{
+			name:        "unknown_version_falls_back",
+			attributes:  map[string]string{AttributeEventType: "user.created", AttributeEventVersion: "3"},
+			shouldAck:   false,
+			shouldErr:   ErrNoRoute,
+			shouldCalls: []string{"router"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			calls = calls[:0]
+			ack, err := r.HandleMessage(context.Background(), &pubsub.Message{ID: "1", Attributes: tc.attributes})
+			assert.Equal(t, tc.shouldAck, ack)
+			assert.True(t, errors.Is(err, tc.shouldErr))
+			assert.Equal(t, tc.shouldCalls, calls)
+		})
+	}
+}
+
+func TestFallbacks(t *testing.T) {
+	msg := &pubsub.Message{ID: "1", Data: []byte("data"), Attributes: map[string]string{AttributeEventType: "unknown"}}
+
+	ack, err := Ack()(context.Background(), msg)
+	assert.True(t, ack)
+	assert.True(t, errors.Is(err, ErrNoRoute))
+
+	ack, err = Nack()(context.Background(), msg)
+	assert.False(t, ack)
+	assert.True(t, errors.Is(err, ErrNoRoute))
+
+	client := new(pubsubtest.Client)
+	ack, err = DeadLetter(client, "dead-letter")(context.Background(), msg)
+	assert.True(t, ack)
+	assert.True(t, errors.Is(err, ErrNoRoute))
+	assert.Equal(t, []pubsubtest.Published{{TopicID: "dead-letter", Message: []byte("data"), Attributes: msg.Attributes}}, client.Published)
+
+	client.PublishErr = errors.New("unavailable")
+	ack, err = DeadLetter(client, "dead-letter")(context.Background(), msg)
+	assert.False(t, ack)
+	assert.True(t, errors.Is(err, ErrNoRoute))
+}
@@ -0,0 +1,24 @@
+package subscriber
+
+import (
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	v1 "go-structure-demo/internal/delivery/pubsub/handler/v1"
+	"go-structure-demo/internal/delivery/pubsub/middleware"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/repository/postgresrepo"
+	"go-structure-demo/internal/repository/redisrepo"
+)
+
+const (
+	EventEmployeeHired = "employee.hired"
+)
+
+// routes returns the route registrations of every router by name
+func routes(redisRepo *redisrepo.RedisRepo, postgresRepo *postgresrepo.PostgresRepo, userController contract.UserController) map[string]func(*router.Router, config.Router) {
+	return map[string]func(*router.Router, config.Router){
+		"employee": func(r *router.Router, cfg config.Router) {
+			r.Handle(EventEmployeeHired, "1", v1.CreateUser(userController, postgresRepo), middleware.Idempotency(redisRepo, cfg.IdempotencyTTL, cfg.HandlerTimeout))
+		},
+	}
+}
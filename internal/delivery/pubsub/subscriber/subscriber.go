@@ -3,21 +3,122 @@ package subscriber
 import (
 	"context"
 	"go-structure-demo/internal/config"
-	v1 "go-structure-demo/internal/delivery/pubsub/handler/v1"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/pubsub/middleware"
+	"go-structure-demo/internal/delivery/pubsub/router"
 	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
 	"go-structure-demo/internal/pubsub"
 	"go-structure-demo/internal/repository/postgresrepo"
 	"go-structure-demo/internal/repository/redisrepo"
+	"sync"
 )
 
-func Subscribe(ctx context.Context, cfg *config.Config, logger log.Logger, redisRepo *redisrepo.RedisRepo, postgresRepo *postgresrepo.PostgresRepo, pubsubClientA *pubsub.GCPClient, pubsubClientB *pubsub.GCPClient) func() {
+// Subscribe starts consuming every configured subscription in parallel, the returned
+// function stops them and waits for their drain.
+func Subscribe(ctx context.Context, cfg *config.Config, logger log.Logger, metrics metrics.Metrics, redisRepo *redisrepo.RedisRepo, postgresRepo *postgresrepo.PostgresRepo, userController contract.UserController, pubsubClientA *pubsub.GCPClient, pubsubClientB *pubsub.GCPClient) func() {
 
-	//create the clients
-	pubsubClientA.Consume(ctx, cfg.PubSub.EmployeeHiredSubscriptionID, v1.CreateUser(cfg, logger, redisRepo, postgresRepo))
+	clientOf := func(project string) (pubsub.Client, bool) {
+		switch project {
+		case cfg.PubSub.ProjectA:
+			return pubsubClientA, true
+		case cfg.PubSub.ProjectB:
+			return pubsubClientB, true
+		}
+		return nil, false
+	}
+
+	registrations := routes(redisRepo, postgresRepo, userController)
+	routers := make(map[string]*router.Router)
+	for _, routerCfg := range cfg.PubSub.Routers {
+		fields := map[string]interface{}{
+			"router":   routerCfg.Name,
+			"fallback": routerCfg.Fallback,
+		}
+
+		register, ok := registrations[routerCfg.Name]
+		if !ok {
+			logger.Error("router has no routes", fields)
+			continue
+		}
+
+		r := router.New()
+		r.Use(
+			middleware.Recoverer(logger),
+			middleware.Logger(logger),
+			middleware.Metrics(metrics),
+			middleware.Timeout(routerCfg.HandlerTimeout),
+			middleware.System(),
+		)
+
+		switch routerCfg.Fallback {
+		case router.FallbackAck:
+			r.Fallback(router.Ack())
+		case router.FallbackNack:
+			r.Fallback(router.Nack())
+		case router.FallbackDeadLetter:
+			client, ok := clientOf(routerCfg.DeadLetterProject)
+			if !ok {
+				logger.Error("router dead letter project is not known", fields)
+				continue
+			}
+			r.Fallback(router.DeadLetter(client, routerCfg.DeadLetterTopicID))
+		default:
+			logger.Error("router fallback is not known", fields)
+			continue
+		}
+
+		register(r, routerCfg)
+		routers[routerCfg.Name] = r
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	wg := new(sync.WaitGroup)
+	for _, subscription := range cfg.PubSub.Subscriptions {
+		subscriptionLogger := logger.With(
+			log.String("project_id", subscription.Project),
+			log.String("subscription_id", subscription.ID),
+			log.String("router", subscription.Router),
+		)
+
+		client, ok := clientOf(subscription.Project)
+		if !ok {
+			subscriptionLogger.Error("subscription project is not known")
+			continue
+		}
+
+		r, ok := routers[subscription.Router]
+		if !ok {
+			subscriptionLogger.Error("subscription router is not registered")
+			continue
+		}
+
+		wg.Add(1)
+		go func(subscription config.Subscription) {
+			defer wg.Done()
+			drained, err := client.Consume(ctx, subscription.ID, pubsub.ReceiveSettings{
+				MaxOutstandingMessages: subscription.MaxOutstandingMessages,
+				MaxOutstandingBytes:    subscription.MaxOutstandingBytes,
+				NumGoroutines:          subscription.NumGoroutines,
+				MaxExtension:           subscription.MaxExtension,
+				Synchronous:            subscription.Synchronous,
+				DrainTimeout:           subscription.DrainTimeout,
+			}, r.HandleMessage)
+			if err != nil {
+				subscriptionLogger.Error("subscription stopped", log.Err(err), log.Any("drained", drained))
+				return
+			}
+			// the messages still in flight are redelivered once their ack deadline is over
+			if !drained {
+				subscriptionLogger.Warn("subscription stopped", log.Any("drained", drained))
+				return
+			}
+			subscriptionLogger.Info("subscription stopped", log.Any("drained", drained))
+		}(subscription)
+	}
 
 	return func() {
-		//close pubsub client
-		//pubsubClientA.Close()
-		//pubsubClientB.Close()
+		cancel()
+		wg.Wait()
 	}
 }
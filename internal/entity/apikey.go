@@ -0,0 +1,23 @@
+package entity
+
+import "time"
+
+// APIKey authenticates a service calling the api, only the hash of the key is stored and
+// the prefix is kept to tell the keys apart.
+type APIKey struct {
+	ID     uint     `json:"id"`
+	Name   string   `json:"name"`
+	Owner  string   `json:"owner"`
+	Prefix string   `json:"prefix"`
+	Hash   string   `json:"-"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is nil for the keys that don't expire
+	ExpiresAt  *time.Time `json:"expires_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (key *APIKey) IsActive(now time.Time) bool {
+	return key.RevokedAt == nil && (key.ExpiresAt == nil || now.Before(*key.ExpiresAt))
+}
@@ -0,0 +1,58 @@
+package entity
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+const (
+	AggregateUser = "user"
+)
+
+const (
+	EventUserCreated = "user.created"
+	EventUserUpdated = "user.updated"
+	EventUserDeleted = "user.deleted"
+)
+
+type OutboxEvent struct {
+	ID            uint64          `json:"id"`
+	AggregateType string          `json:"aggregate_type"`
+	AggregateID   string          `json:"aggregate_id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Attempts      int             `json:"attempts"`
+	LastError     string          `json:"last_error"`
+	CreatedAt     time.Time       `json:"created_at"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	PublishedAt   *time.Time      `json:"published_at"`
+	DeadAt        *time.Time      `json:"dead_at"`
+}
+
+type OutboxStats struct {
+	Pending         int64
+	Dead            int64
+	OldestPendingAt *time.Time
+}
+
+func NewOutboxEvent(aggregateType, aggregateID, eventType string, payload interface{}) (OutboxEvent, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return OutboxEvent{}, err
+	}
+
+	now := time.Now().UTC()
+	return OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateID,
+		EventType:     eventType,
+		Payload:       data,
+		CreatedAt:     now,
+		NextAttemptAt: now,
+	}, nil
+}
+
+func NewUserCreatedEvent(user User) (OutboxEvent, error) {
+	return NewOutboxEvent(AggregateUser, strconv.FormatUint(uint64(user.ID), 10), EventUserCreated, user.ToMap())
+}
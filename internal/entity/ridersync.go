@@ -0,0 +1,11 @@
+package entity
+
+import "time"
+
+// RiderSync is a user waiting for its rider profile, Attempts counts the failed ones.
+type RiderSync struct {
+	User          User
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+}
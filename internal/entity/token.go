@@ -0,0 +1,10 @@
+package entity
+
+type TokenPurpose string
+
+const (
+	TokenPurposeEmailVerification TokenPurpose = "email_verification"
+	TokenPurposePasswordReset     TokenPurpose = "password_reset"
+	TokenPurposeMagicLogin        TokenPurpose = "magic_login"
+	TokenPurposeSession           TokenPurpose = "session"
+)
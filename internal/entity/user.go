@@ -3,6 +3,7 @@ package entity
 import (
 	"fmt"
 	"strings"
+	"time"
 )
 
 const (
@@ -11,6 +12,18 @@ const (
 	UserEntityEmail = "email"
 )
 
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// the rider profile of a user is created in the rider profile service after the user
+const (
+	RiderSyncPending = "sync_pending"
+	RiderSyncSynced  = "synced"
+	RiderSyncFailed  = "sync_failed"
+)
+
 const (
 	GenderMale   = "male"
 	GenderFemale = "female"
@@ -22,6 +35,16 @@ type User struct {
 	FirstName string  `json:"first_name"`
 	LastName  string  `json:"last_name"`
 	Gender    *string `json:"gender"`
+	Role      string  `json:"role"`
+	// EmailVerifiedAt is nil until the user proves owning the email
+	EmailVerifiedAt *time.Time `json:"email_verified_at"`
+	// RiderID is the id in the rider profile service, nil until the sync succeeds
+	RiderID         *string `json:"rider_id"`
+	RiderSyncStatus string  `json:"rider_sync_status"`
+}
+
+func (user *User) IsEmailVerified() bool {
+	return user.EmailVerifiedAt != nil
 }
 
 func (user *User) GetFullName() string {
@@ -35,5 +58,6 @@ func (user *User) ToMap() map[string]interface{} {
 		"first_name": user.FirstName,
 		"last_name":  user.LastName,
 		"gender":     user.Gender,
+		"role":       user.Role,
 	}
 }
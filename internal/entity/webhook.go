@@ -0,0 +1,59 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookEventAll subscribes a webhook to every event.
+const WebhookEventAll = "*"
+
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliverySucceeded = "succeeded"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is a subscription of a partner to the user lifecycle events. Failures counts the
+// failed attempts since the last successful one, the webhook is disabled when it gets too
+// high and enabled again by hand.
+type Webhook struct {
+	ID  uint   `json:"id"`
+	URL string `json:"url"`
+	// Secret signs the deliveries, it is shown on creation only
+	Secret     string     `json:"-"`
+	Events     []string   `json:"events"`
+	Active     bool       `json:"active"`
+	Failures   int        `json:"failures"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Subscribes tells whether the webhook wants the event, whether it is active or not.
+func (w Webhook) Subscribes(eventType string) bool {
+	for _, event := range w.Events {
+		if event == WebhookEventAll || event == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is an event sent to a webhook and the outcome of its last attempt, the
+// deliveries are the delivery log of the webhook. A redelivery is a new delivery of the same
+// event, RedeliveryOf is the delivery it repeats.
+type WebhookDelivery struct {
+	ID             uint64          `json:"id"`
+	WebhookID      uint            `json:"webhook_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status"`
+	LastError      string          `json:"last_error"`
+	RedeliveryOf   *uint64         `json:"redelivery_of"`
+	CreatedAt      time.Time       `json:"created_at"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at"`
+}
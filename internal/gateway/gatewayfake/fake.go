@@ -0,0 +1,121 @@
+package gatewayfake
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Call is a call made to a fake, Args are the arguments that follow the context.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Step is the scripted outcome of a call. A step without Result nor Err waits its latency
+// and lets the fake answer with its default result.
+type Step struct {
+	// Result has the result type of the method, like Rider for CreateRider.
+	Result  interface{}
+	Err     error
+	Latency time.Duration
+	// Times is how many calls the step answers, once when it is zero and every remaining
+	// call when it is negative.
+	Times int
+}
+
+// Fake records the calls of a gateway fake and answers each of them with the next step
+// scripted for its method. The gateway fakes embed it and keep their default result for the
+// calls nothing is scripted for.
+type Fake struct {
+	mu      sync.Mutex
+	results map[string]interface{}
+	errors  map[string]error
+	latency time.Duration
+	steps   map[string][]Step
+	calls   []Call
+}
+
+// New returns a fake that succeeds with the default results. results holds the zero value of
+// the result of every method and errors the errors a scenario can name, both are used to
+// load the scenarios.
+func New(results map[string]interface{}, errors map[string]error) *Fake {
+	return &Fake{
+		results: results,
+		errors:  errors,
+		steps:   make(map[string][]Step),
+	}
+}
+
+// Script appends the steps to the ones of the method.
+func (f *Fake) Script(method string, steps ...Step) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.steps[method] = append(f.steps[method], steps...)
+}
+
+// SetLatency delays every call by d, on top of the latency of its step.
+func (f *Fake) SetLatency(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.latency = d
+}
+
+// Calls returns the calls made so far, of every method when method is empty.
+func (f *Fake) Calls(method string) []Call {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	calls := make([]Call, 0, len(f.calls))
+	for _, call := range f.calls {
+		if method == "" || call.Method == method {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Reset forgets the calls and the steps left.
+func (f *Fake) Reset() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.calls = nil
+	f.steps = make(map[string][]Step)
+}
+
+// Call records the call and returns its step once the latency is over. The step has the error
+// of ctx when ctx is done before that, and is the zero Step when nothing is scripted.
+func (f *Fake) Call(ctx context.Context, method string, args ...interface{}) Step {
+	f.mu.Lock()
+	f.calls = append(f.calls, Call{Method: method, Args: args})
+	step := f.next(method)
+	latency := f.latency + step.Latency
+	f.mu.Unlock()
+
+	if latency > 0 {
+		timer := time.NewTimer(latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return Step{Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+	return step
+}
+
+// next pops the step of the method, the lock must be held.
+func (f *Fake) next(method string) Step {
+	steps := f.steps[method]
+	if len(steps) == 0 {
+		return Step{}
+	}
+	step := steps[0]
+	switch {
+	case step.Times < 0:
+	case step.Times > 1:
+		steps[0].Times--
+	default:
+		f.steps[method] = steps[1:]
+	}
+	return step
+}
@@ -0,0 +1,125 @@
+package gatewayfake
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errUnavailable = errors.New("unavailable")
+
+type result struct {
+	ID   string `yaml:"id"`
+	Name string `yaml:"name"`
+}
+
+func newFake() *Fake {
+	return New(
+		map[string]interface{}{"Get": result{}, "List": []result{}},
+		map[string]error{"unavailable": errUnavailable},
+	)
+}
+
+func TestFake_Call(t *testing.T) {
+	ctx := context.Background()
+	fake := newFake()
+	fake.Script("Get",
+		Step{Err: errUnavailable, Times: 2},
+		Step{Result: result{ID: "1"}},
+		Step{Err: errUnavailable, Times: -1},
+	)
+
+	shouldSteps := []Step{
+		{Err: errUnavailable, Times: 2},
+		{Err: errUnavailable, Times: 1},
+		{Result: result{ID: "1"}},
+		{Err: errUnavailable, Times: -1},
+		{Err: errUnavailable, Times: -1},
+	}
+	for i, shouldStep := range shouldSteps {
+		assert.Equal(t, shouldStep, fake.Call(ctx, "Get", i), "call %d", i)
+	}
+	assert.Equal(t, Step{}, fake.Call(ctx, "List"))
+
+	assert.Len(t, fake.Calls(""), 6)
+	calls := fake.Calls("Get")
+	assert.Len(t, calls, 5)
+	assert.Equal(t, Call{Method: "Get", Args: []interface{}{2}}, calls[2])
+
+	fake.Reset()
+	assert.Empty(t, fake.Calls(""))
+	assert.Equal(t, Step{}, fake.Call(ctx, "Get"))
+}
+
+func TestFake_Call_Latency(t *testing.T) {
+	fake := newFake()
+	fake.SetLatency(20 * time.Millisecond)
+	fake.Script("Get", Step{Latency: time.Hour})
+
+	start := time.Now()
+	assert.Equal(t, Step{}, fake.Call(context.Background(), "List"))
+	assert.True(t, time.Since(start) >= 20*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	step := fake.Call(ctx, "Get")
+	assert.True(t, errors.Is(step.Err, context.DeadlineExceeded))
+	assert.Len(t, fake.Calls("Get"), 1)
+}
+
+func TestFake_LoadScenario(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scenario      string
+		shouldErr     string
+		shouldSteps   []Step
+		shouldList    []Step
+		shouldLatency time.Duration
+	}{
+		{
+			name: "steps",
+			scenario: `
+latency: 15ms
+methods:
+  Get:
+    - error: unavailable
+      latency: 1s
+      times: 2
+    - result: {id: "1", name: John}
+  List:
+    - result: [{id: "1"}, {id: "2"}]
+`,
+			shouldLatency: 15 * time.Millisecond,
+			shouldSteps: []Step{
+				{Err: errUnavailable, Latency: time.Second, Times: 2},
+				{Result: result{ID: "1", Name: "John"}},
+			},
+			shouldList: []Step{{Result: []result{{ID: "1"}, {ID: "2"}}}},
+		},
+		{name: "empty", scenario: ``},
+		{name: "unknown_method", scenario: "methods:\n  Delete:\n    - error: unavailable", shouldErr: `unknown method "Delete"`},
+		{name: "unknown_error", scenario: "methods:\n  Get:\n    - error: down", shouldErr: `unknown error "down"`},
+		{name: "unknown_field", scenario: "methods:\n  Get:\n    - eror: unavailable", shouldErr: "field eror not found"},
+		{name: "invalid_result", scenario: "methods:\n  Get:\n    - result: [1, 2]", shouldErr: "Get step 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := newFake()
+			err := fake.LoadScenario(strings.NewReader(tc.scenario))
+			if tc.shouldErr != "" {
+				assert.NotNil(t, err)
+				assert.Contains(t, err.Error(), tc.shouldErr)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.shouldLatency, fake.latency)
+			assert.Equal(t, tc.shouldSteps, fake.steps["Get"])
+			assert.Equal(t, tc.shouldList, fake.steps["List"])
+		})
+	}
+}
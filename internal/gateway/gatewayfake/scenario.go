@@ -0,0 +1,85 @@
+package gatewayfake
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scenario is the YAML form of the steps, like:
+//
+//	latency: 20ms
+//	methods:
+//	  CreateRider:
+//	    - error: unavailable
+//	      times: 2
+//	    - latency: 3s
+//	      result: {id: rider-42, name: Jane Roe}
+//	    - error: rate_limited
+//	      times: -1
+type scenario struct {
+	Latency time.Duration             `yaml:"latency"`
+	Methods map[string][]scenarioStep `yaml:"methods"`
+}
+
+type scenarioStep struct {
+	Result  yaml.Node     `yaml:"result"`
+	Error   string        `yaml:"error"`
+	Latency time.Duration `yaml:"latency"`
+	Times   int           `yaml:"times"`
+}
+
+// LoadScenarioFile loads the scenario of the YAML file.
+func (f *Fake) LoadScenarioFile(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return f.LoadScenario(file)
+}
+
+// LoadScenario sets the latency of the scenario and appends its steps, the methods and the
+// errors are checked so a typo doesn't go unnoticed.
+func (f *Fake) LoadScenario(r io.Reader) error {
+	decoder := yaml.NewDecoder(r)
+	decoder.KnownFields(true)
+	var s scenario
+	if err := decoder.Decode(&s); err != nil && err != io.EOF {
+		return fmt.Errorf("decoding scenario: %w", err)
+	}
+
+	steps := make(map[string][]Step, len(s.Methods))
+	for method, scenarioSteps := range s.Methods {
+		zero, ok := f.results[method]
+		if !ok {
+			return fmt.Errorf("scenario: unknown method %q", method)
+		}
+		for i, scenarioStep := range scenarioSteps {
+			step := Step{Latency: scenarioStep.Latency, Times: scenarioStep.Times}
+			if scenarioStep.Error != "" {
+				if step.Err, ok = f.errors[scenarioStep.Error]; !ok {
+					return fmt.Errorf("scenario: %s step %d: unknown error %q", method, i+1, scenarioStep.Error)
+				}
+			}
+			if !scenarioStep.Result.IsZero() {
+				result := reflect.New(reflect.TypeOf(zero))
+				if err := scenarioStep.Result.Decode(result.Interface()); err != nil {
+					return fmt.Errorf("scenario: %s step %d: %w", method, i+1, err)
+				}
+				step.Result = result.Elem().Interface()
+			}
+			steps[method] = append(steps[method], step)
+		}
+	}
+
+	f.SetLatency(s.Latency)
+	for method, methodSteps := range steps {
+		f.Script(method, methodSteps...)
+	}
+	return nil
+}
@@ -0,0 +1,176 @@
+package quinyxgateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+)
+
+const (
+	OperationGetEmployee   = "GetEmployee"
+	OperationListEmployees = "ListEmployees"
+	OperationSaveEmployee  = "SaveEmployee"
+	OperationListShifts    = "ListShifts"
+
+	dateLayout      = "2006-01-02"
+	maxResponseSize = 10 << 20
+)
+
+var _ Client = (*Concrete)(nil)
+
+// Concrete calls the Quinyx SOAP service, every request carries a WS-Security UsernameToken
+// and the faults are returned as *Fault.
+type Concrete struct {
+	cfg     config.Quinyx
+	logger  log.Logger
+	metrics metrics.Metrics
+	client  *http.Client
+	now     func() time.Time
+}
+
+func New(cfg config.Quinyx, logger log.Logger, metricsClient metrics.Metrics) *Concrete {
+	return &Concrete{
+		cfg:     cfg,
+		logger:  logger,
+		metrics: metricsClient,
+		client:  httptrace.WrapClient(&http.Client{Timeout: cfg.Timeout}),
+		now:     time.Now,
+	}
+}
+
+type getEmployeeRequest struct {
+	XMLName    xml.Name `xml:"GetEmployeeRequest"`
+	XMLNS      string   `xml:"xmlns,attr"`
+	EmployeeID string   `xml:"EmployeeId"`
+}
+
+type employeeResponse struct {
+	Employee Employee `xml:"Employee"`
+}
+
+func (c *Concrete) GetEmployee(ctx context.Context, id string) (Employee, error) {
+	var response employeeResponse
+	err := c.call(ctx, OperationGetEmployee, getEmployeeRequest{XMLNS: c.cfg.Namespace, EmployeeID: id}, &response)
+	return response.Employee, err
+}
+
+type listEmployeesRequest struct {
+	XMLName   xml.Name `xml:"ListEmployeesRequest"`
+	XMLNS     string   `xml:"xmlns,attr"`
+	PageToken string   `xml:"PageToken,omitempty"`
+	PageSize  int      `xml:"PageSize"`
+}
+
+type listEmployeesResponse struct {
+	Page EmployeePage `xml:"EmployeePage"`
+}
+
+func (c *Concrete) ListEmployees(ctx context.Context, pageToken string, pageSize int) (EmployeePage, error) {
+	var response listEmployeesResponse
+	err := c.call(ctx, OperationListEmployees, listEmployeesRequest{XMLNS: c.cfg.Namespace, PageToken: pageToken, PageSize: pageSize}, &response)
+	return response.Page, err
+}
+
+type saveEmployeeRequest struct {
+	XMLName  xml.Name `xml:"SaveEmployeeRequest"`
+	XMLNS    string   `xml:"xmlns,attr"`
+	Employee Employee `xml:"Employee"`
+}
+
+func (c *Concrete) SaveEmployee(ctx context.Context, employee Employee) (Employee, error) {
+	var response employeeResponse
+	err := c.call(ctx, OperationSaveEmployee, saveEmployeeRequest{XMLNS: c.cfg.Namespace, Employee: employee}, &response)
+	return response.Employee, err
+}
+
+type listShiftsRequest struct {
+	XMLName xml.Name `xml:"ListShiftsRequest"`
+	XMLNS   string   `xml:"xmlns,attr"`
+	From    string   `xml:"From"`
+	To      string   `xml:"To"`
+}
+
+type listShiftsResponse struct {
+	Shifts []Shift `xml:"Shifts>Shift"`
+}
+
+// ListShifts sends the dates only, the range is whole days in the time zone of the arguments.
+func (c *Concrete) ListShifts(ctx context.Context, from, to time.Time) ([]Shift, error) {
+	var response listShiftsResponse
+	err := c.call(ctx, OperationListShifts, listShiftsRequest{
+		XMLNS: c.cfg.Namespace,
+		From:  from.Format(dateLayout),
+		To:    to.Format(dateLayout),
+	}, &response)
+	return response.Shifts, err
+}
+
+// call posts the envelope of the request and decodes the body of the response into out.
+func (c *Concrete) call(ctx context.Context, operation string, request interface{}, out interface{}) error {
+	envelope, err := newEnvelope(request, c.cfg.Username, c.cfg.Password, c.cfg.PasswordDigest, c.now())
+	if err != nil {
+		return err
+	}
+	payload, err := xml.Marshal(envelope)
+	if err != nil {
+		return err
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.Endpoint, bytes.NewReader(append([]byte(xml.Header), payload...)))
+	if err != nil {
+		return err
+	}
+	httpRequest.Header.Set("Content-Type", "text/xml; charset=utf-8")
+	httpRequest.Header.Set("SOAPAction", `"`+strings.TrimRight(c.cfg.Namespace, "/")+"/"+operation+`"`)
+
+	start := time.Now()
+	httpResponse, err := c.client.Do(httpRequest)
+	if err != nil {
+		return fmt.Errorf("quinyx %s: %w", operation, err)
+	}
+	defer func() { _ = httpResponse.Body.Close() }()
+
+	body, err := io.ReadAll(io.LimitReader(httpResponse.Body, maxResponseSize))
+	c.metrics.Timing("quinyx.request", time.Since(start),
+		metrics.Tag("operation", operation),
+		metrics.Tag("status", strconv.Itoa(httpResponse.StatusCode)),
+	)
+	if err != nil {
+		return fmt.Errorf("quinyx %s: reading response: %w", operation, err)
+	}
+
+	// the faults come with a 500 in SOAP 1.1, anything else that doesn't parse is unexpected
+	var response responseEnvelope
+	if err := xml.Unmarshal(body, &response); err != nil {
+		if httpResponse.StatusCode >= 500 {
+			return fmt.Errorf("quinyx %s: status %d: %w", operation, httpResponse.StatusCode, ErrUnavailable)
+		}
+		return fmt.Errorf("quinyx %s: decoding response: %w", operation, err)
+	}
+	if response.Body.Fault != nil {
+		c.logger.DebugWithContext(ctx, "quinyx fault", map[string]interface{}{
+			"operation":  operation,
+			"fault_code": response.Body.Fault.Code,
+			"error_code": response.Body.Fault.Detail.ErrorCode,
+		})
+		return response.Body.Fault
+	}
+	if httpResponse.StatusCode != http.StatusOK {
+		return fmt.Errorf("quinyx %s: status %d: %w", operation, httpResponse.StatusCode, ErrUnavailable)
+	}
+	if err := xml.Unmarshal(response.Body.Content, out); err != nil {
+		return fmt.Errorf("quinyx %s: decoding response: %w", operation, err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package quinyxgateway_test
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/gateway/quinyxgateway"
+	"go-structure-demo/internal/gateway/quinyxgateway/quinyxtest"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var john = quinyxgateway.Employee{
+	ID:         "10042",
+	ExternalID: "42",
+	FirstName:  "John",
+	LastName:   "Doe",
+	Email:      "john@doe.com",
+	Phone:      "+31600000000",
+	Active:     true,
+}
+
+func TestConcrete_GetEmployee(t *testing.T) {
+	server := quinyxtest.NewServer()
+	defer server.Close()
+
+	for _, digest := range []bool{true, false} {
+		cfg := server.Config()
+		cfg.PasswordDigest = digest
+		employee, err := quinyxgateway.New(cfg, log.NewMock("quinyx"), metrics.NewNoop()).GetEmployee(context.Background(), "10042")
+		assert.Nil(t, err)
+		assert.Equal(t, john, employee)
+	}
+
+	requests := server.Requests()
+	assert.Len(t, requests, 2)
+	assert.Equal(t, quinyxgateway.OperationGetEmployee, requests[0].Operation)
+	assert.Equal(t, quinyxtest.Username, requests[0].Username)
+	assert.Equal(t, `<GetEmployeeRequest xmlns="urn:quinyx:wfm"><EmployeeId>10042</EmployeeId></GetEmployeeRequest>`, requests[0].Body)
+}
+
+func TestConcrete_ListEmployees(t *testing.T) {
+	server := quinyxtest.NewServer()
+	defer server.Close()
+	client := quinyxgateway.New(server.Config(), log.NewMock("quinyx"), metrics.NewNoop())
+
+	page, err := client.ListEmployees(context.Background(), "", 2)
+	assert.Nil(t, err)
+	assert.Equal(t, []quinyxgateway.Employee{john, {ID: "10043", FirstName: "Jane", LastName: "Roe", Email: "jane@roe.com", Phone: "+31600000001", Active: true}}, page.Employees)
+	assert.Equal(t, "page-2", page.NextPageToken)
+
+	page, err = client.ListEmployees(context.Background(), page.NextPageToken, 2)
+	assert.Nil(t, err)
+	assert.Len(t, page.Employees, 3)
+	assert.Equal(t, "", page.NextPageToken)
+
+	requests := server.Requests()
+	assert.Equal(t, `<ListEmployeesRequest xmlns="urn:quinyx:wfm"><PageSize>2</PageSize></ListEmployeesRequest>`, requests[0].Body)
+	assert.Equal(t, `<ListEmployeesRequest xmlns="urn:quinyx:wfm"><PageToken>page-2</PageToken><PageSize>2</PageSize></ListEmployeesRequest>`, requests[1].Body)
+}
+
+func TestConcrete_SaveEmployee(t *testing.T) {
+	server := quinyxtest.NewServer()
+	defer server.Close()
+
+	employee := john
+	employee.ID = ""
+	saved, err := quinyxgateway.New(server.Config(), log.NewMock("quinyx"), metrics.NewNoop()).SaveEmployee(context.Background(), employee)
+	assert.Nil(t, err)
+	assert.Equal(t, john, saved)
+	assert.Contains(t, server.Requests()[0].Body, "<Employee><Id></Id><ExternalId>42</ExternalId>")
+}
+
+func TestConcrete_ListShifts(t *testing.T) {
+	server := quinyxtest.NewServer()
+	defer server.Close()
+
+	from := time.Date(2022, 1, 3, 0, 0, 0, 0, time.UTC)
+	shifts, err := quinyxgateway.New(server.Config(), log.NewMock("quinyx"), metrics.NewNoop()).ListShifts(context.Background(), from, from.AddDate(0, 0, 7))
+	assert.Nil(t, err)
+	assert.Len(t, shifts, 2)
+	assert.Equal(t, "900001", shifts[0].ID)
+	assert.Equal(t, "10042", shifts[0].EmployeeID)
+	assert.Equal(t, "Amsterdam Centrum", shifts[0].Section)
+	assert.True(t, shifts[0].Start.Equal(time.Date(2022, 1, 3, 7, 0, 0, 0, time.UTC)))
+	assert.Equal(t, 8*time.Hour, shifts[0].End.Sub(shifts[0].Start))
+	assert.Equal(t, `<ListShiftsRequest xmlns="urn:quinyx:wfm"><From>2022-01-03</From><To>2022-01-10</To></ListShiftsRequest>`, server.Requests()[0].Body)
+}
+
+func TestConcrete_Faults(t *testing.T) {
+	testCases := []struct {
+		name            string
+		fixture         string
+		password        string
+		shouldErr       error
+		shouldFaultCode string
+	}{
+		{name: "employee_not_found", fixture: quinyxtest.FixtureEmployeeNotFound, shouldErr: quinyxgateway.ErrNotFound, shouldFaultCode: "SOAP-ENV:Client"},
+		{name: "validation_failed", fixture: quinyxtest.FixtureValidationFailed, shouldErr: quinyxgateway.ErrInvalidRequest, shouldFaultCode: "SOAP-ENV:Client"},
+		{name: "server_fault", fixture: quinyxtest.FixtureServerFault, shouldErr: quinyxgateway.ErrUnavailable, shouldFaultCode: "SOAP-ENV:Server"},
+		{name: "wrong_password", fixture: quinyxtest.FixtureGetEmployee, password: "wrong", shouldErr: quinyxgateway.ErrUnauthorized, shouldFaultCode: "wsse:FailedAuthentication"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			server := quinyxtest.NewServer()
+			defer server.Close()
+			server.Respond(quinyxgateway.OperationGetEmployee, tc.fixture)
+
+			cfg := server.Config()
+			if tc.password != "" {
+				cfg.Password = tc.password
+			}
+			_, err := quinyxgateway.New(cfg, log.NewMock("quinyx"), metrics.NewNoop()).GetEmployee(context.Background(), "10042")
+			assert.ErrorIs(t, err, tc.shouldErr)
+			var fault *quinyxgateway.Fault
+			assert.True(t, errors.As(err, &fault))
+			assert.Equal(t, tc.shouldFaultCode, fault.Code)
+		})
+	}
+}
+
+func TestConcrete_Unavailable(t *testing.T) {
+	server := quinyxtest.NewServer()
+	server.Close()
+
+	_, err := quinyxgateway.New(server.Config(), log.NewMock("quinyx"), metrics.NewNoop()).GetEmployee(context.Background(), "10042")
+	assert.NotNil(t, err)
+	var fault *quinyxgateway.Fault
+	assert.False(t, errors.As(err, &fault))
+}
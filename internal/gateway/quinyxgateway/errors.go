@@ -0,0 +1,59 @@
+package quinyxgateway
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// the errors of the faults, match them with errors.Is
+var (
+	ErrInvalidRequest = errors.New("quinyx: invalid request")
+	ErrUnauthorized   = errors.New("quinyx: unauthorized")
+	ErrNotFound       = errors.New("quinyx: not found")
+	ErrUnavailable    = errors.New("quinyx: unavailable")
+)
+
+// Fault is a SOAP 1.1 fault, the ErrorCode of the detail is set by Quinyx on its own faults.
+type Fault struct {
+	Code   string      `xml:"faultcode"`
+	String string      `xml:"faultstring"`
+	Actor  string      `xml:"faultactor"`
+	Detail FaultDetail `xml:"detail"`
+}
+
+type FaultDetail struct {
+	ErrorCode string `xml:"ErrorCode"`
+	Message   string `xml:"Message"`
+}
+
+func (f *Fault) Error() string {
+	if f.Detail.ErrorCode != "" {
+		return fmt.Sprintf("quinyx: %s: %s (%s)", f.Code, f.String, f.Detail.ErrorCode)
+	}
+	return fmt.Sprintf("quinyx: %s: %s", f.Code, f.String)
+}
+
+func (f *Fault) Unwrap() error {
+	switch f.Detail.ErrorCode {
+	case "EmployeeNotFound", "NotFound":
+		return ErrNotFound
+	case "ValidationFailed":
+		return ErrInvalidRequest
+	}
+
+	// the codes are qualified names like soap:Client or wsse:FailedAuthentication
+	code := f.Code
+	if i := strings.LastIndex(code, ":"); i >= 0 {
+		code = code[i+1:]
+	}
+	switch code {
+	case "FailedAuthentication", "InvalidSecurity", "InvalidSecurityToken":
+		return ErrUnauthorized
+	case "Client":
+		return ErrInvalidRequest
+	case "Server":
+		return ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package quinyxgateway
+
+import (
+	"context"
+	"go-structure-demo/internal/gateway/gatewayfake"
+	"time"
+)
+
+var _ Client = (*Fake)(nil)
+
+// Fake is a programmable Client, see gatewayfake.Fake, its methods are scripted by their
+// operation. It has no employees nor shifts by default and saves the employees as they are.
+// The scenarios name the errors in snake case, like not_found.
+type Fake struct {
+	*gatewayfake.Fake
+}
+
+func NewFake() *Fake {
+	return &Fake{Fake: gatewayfake.New(
+		map[string]interface{}{
+			OperationGetEmployee:   Employee{},
+			OperationListEmployees: EmployeePage{},
+			OperationSaveEmployee:  Employee{},
+			OperationListShifts:    []Shift{},
+		},
+		map[string]error{
+			"invalid_request": ErrInvalidRequest,
+			"unauthorized":    ErrUnauthorized,
+			"not_found":       ErrNotFound,
+			"unavailable":     ErrUnavailable,
+		},
+	)}
+}
+
+// LoadFake returns a Fake scripted with the scenario file.
+func LoadFake(path string) (*Fake, error) {
+	fake := NewFake()
+	if err := fake.LoadScenarioFile(path); err != nil {
+		return nil, err
+	}
+	return fake, nil
+}
+
+func (f *Fake) GetEmployee(ctx context.Context, id string) (Employee, error) {
+	step := f.Call(ctx, OperationGetEmployee, id)
+	if step.Err != nil {
+		return Employee{}, step.Err
+	}
+	if employee, ok := step.Result.(Employee); ok {
+		return employee, nil
+	}
+	return Employee{}, ErrNotFound
+}
+
+func (f *Fake) ListEmployees(ctx context.Context, pageToken string, pageSize int) (EmployeePage, error) {
+	step := f.Call(ctx, OperationListEmployees, pageToken, pageSize)
+	if step.Err != nil {
+		return EmployeePage{}, step.Err
+	}
+	if page, ok := step.Result.(EmployeePage); ok {
+		return page, nil
+	}
+	return EmployeePage{}, nil
+}
+
+func (f *Fake) SaveEmployee(ctx context.Context, employee Employee) (Employee, error) {
+	step := f.Call(ctx, OperationSaveEmployee, employee)
+	if step.Err != nil {
+		return Employee{}, step.Err
+	}
+	if saved, ok := step.Result.(Employee); ok {
+		return saved, nil
+	}
+	if employee.ID == "" {
+		employee.ID = "fake-" + employee.Email
+	}
+	return employee, nil
+}
+
+func (f *Fake) ListShifts(ctx context.Context, from, to time.Time) ([]Shift, error) {
+	step := f.Call(ctx, OperationListShifts, from, to)
+	if step.Err != nil {
+		return nil, step.Err
+	}
+	if shifts, ok := step.Result.([]Shift); ok {
+		return shifts, nil
+	}
+	return nil, nil
+}
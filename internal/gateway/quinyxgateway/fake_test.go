@@ -0,0 +1,56 @@
+package quinyxgateway_test
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/gateway/gatewayfake"
+	"go-structure-demo/internal/gateway/quinyxgateway"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	fake := quinyxgateway.NewFake()
+	fake.Script(quinyxgateway.OperationGetEmployee, gatewayfake.Step{Result: john})
+
+	employee, err := fake.GetEmployee(ctx, "10042")
+	assert.Nil(t, err)
+	assert.Equal(t, john, employee)
+	_, err = fake.GetEmployee(ctx, "10042")
+	assert.True(t, errors.Is(err, quinyxgateway.ErrNotFound))
+
+	saved, err := fake.SaveEmployee(ctx, quinyxgateway.Employee{Email: "jane@roe.com"})
+	assert.Nil(t, err)
+	assert.Equal(t, "fake-jane@roe.com", saved.ID)
+	assert.Equal(t, []interface{}{"10042"}, fake.Calls(quinyxgateway.OperationGetEmployee)[0].Args)
+}
+
+func TestLoadFake(t *testing.T) {
+	fake, err := quinyxgateway.LoadFake("testdata/two_pages.yaml")
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	page, err := fake.ListEmployees(ctx, "", 100)
+	assert.Nil(t, err)
+	assert.Equal(t, []quinyxgateway.Employee{{ID: "10042", FirstName: "John", LastName: "Doe", Email: "john@doe.com", Active: true}}, page.Employees)
+	assert.Equal(t, "page-2", page.NextPageToken)
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = fake.ListEmployees(timeoutCtx, page.NextPageToken, 100)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	page, err = fake.ListEmployees(ctx, page.NextPageToken, 100)
+	assert.Nil(t, err)
+	assert.Equal(t, "jane@roe.com", page.Employees[0].Email)
+	assert.Equal(t, "", page.NextPageToken)
+
+	shifts, err := fake.ListShifts(ctx, time.Time{}, time.Time{})
+	assert.Nil(t, err)
+	assert.Len(t, shifts, 1)
+	assert.Equal(t, 8*time.Hour, shifts[0].End.Sub(shifts[0].Start))
+	assert.Equal(t, []interface{}{"page-2", 100}, fake.Calls(quinyxgateway.OperationListEmployees)[2].Args)
+}
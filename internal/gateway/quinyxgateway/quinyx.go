@@ -1,21 +1,41 @@
 package quinyxgateway
 
-type Client interface {
-	CallWSDL() error
-}
+import (
+	"context"
+	"time"
+)
 
-type Concrete struct {
+type Client interface {
+	GetEmployee(ctx context.Context, id string) (Employee, error)
+	// ListEmployees returns a page of the employees, the first one for an empty page token.
+	ListEmployees(ctx context.Context, pageToken string, pageSize int) (EmployeePage, error)
+	// SaveEmployee creates the employee when the ID is empty and updates it otherwise.
+	SaveEmployee(ctx context.Context, employee Employee) (Employee, error)
+	// ListShifts returns the shifts starting in [from, to).
+	ListShifts(ctx context.Context, from, to time.Time) ([]Shift, error)
 }
 
-func (c *Concrete) CallWSDL() error {
-	// some logics
-	return nil
+type Employee struct {
+	ID string `xml:"Id" yaml:"id"`
+	// ExternalID is the id of the user in this service
+	ExternalID string `xml:"ExternalId" yaml:"external_id"`
+	FirstName  string `xml:"FirstName" yaml:"first_name"`
+	LastName   string `xml:"LastName" yaml:"last_name"`
+	Email      string `xml:"Email" yaml:"email"`
+	Phone      string `xml:"Phone" yaml:"phone"`
+	Active     bool   `xml:"Active" yaml:"active"`
 }
 
-type Mock struct {
+// EmployeePage is the last one when NextPageToken is empty.
+type EmployeePage struct {
+	Employees     []Employee `xml:"Employees>Employee" yaml:"employees"`
+	NextPageToken string     `xml:"NextPageToken" yaml:"next_page_token"`
 }
 
-func (m *Mock) CallWSDL() error {
-	// some logics
-	return nil
+type Shift struct {
+	ID         string    `xml:"Id" yaml:"id"`
+	EmployeeID string    `xml:"EmployeeId" yaml:"employee_id"`
+	Section    string    `xml:"Section" yaml:"section"`
+	Start      time.Time `xml:"Start" yaml:"start"`
+	End        time.Time `xml:"End" yaml:"end"`
 }
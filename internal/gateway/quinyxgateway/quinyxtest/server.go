@@ -0,0 +1,178 @@
+package quinyxtest
+
+import (
+	"embed"
+	"encoding/base64"
+	"encoding/xml"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/gateway/quinyxgateway"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+// the credentials the server accepts
+const (
+	Username  = "api-user"
+	Password  = "secret"
+	Namespace = "urn:quinyx:wfm"
+)
+
+// the fixtures are responses recorded from the Quinyx test environment
+const (
+	FixtureGetEmployee          = "get_employee.xml"
+	FixtureListEmployees        = "list_employees.xml"
+	FixtureSaveEmployee         = "save_employee.xml"
+	FixtureListShifts           = "list_shifts.xml"
+	FixtureEmployeeNotFound     = "fault_employee_not_found.xml"
+	FixtureValidationFailed     = "fault_validation_failed.xml"
+	FixtureAuthenticationFailed = "fault_authentication.xml"
+	FixtureServerFault          = "fault_server.xml"
+	faultFixturePrefix          = "fault_"
+)
+
+//go:embed testdata/*.xml
+var fixtures embed.FS
+
+// Request is a call received by the server, Body is the inner xml of the SOAP body.
+type Request struct {
+	Operation string
+	Username  string
+	Body      string
+}
+
+// Server is a fake Quinyx SOAP service replaying the fixtures. It checks the WS-Security
+// UsernameToken, text or digest, and answers every operation with its fixture, Respond
+// changes the fixture of an operation. The pages after the first one of FixtureListEmployees
+// are in list_employees_{page token}.xml.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	responses map[string]string
+	requests  []Request
+}
+
+func NewServer() *Server {
+	server := &Server{
+		responses: map[string]string{
+			quinyxgateway.OperationGetEmployee:   FixtureGetEmployee,
+			quinyxgateway.OperationListEmployees: FixtureListEmployees,
+			quinyxgateway.OperationSaveEmployee:  FixtureSaveEmployee,
+			quinyxgateway.OperationListShifts:    FixtureListShifts,
+		},
+	}
+	server.Server = httptest.NewServer(http.HandlerFunc(server.handle))
+	return server
+}
+
+// Config points a client to the server with valid credentials.
+func (s *Server) Config() config.Quinyx {
+	return config.Quinyx{
+		Endpoint:       s.URL + "/FlexForceWebServices.php",
+		Namespace:      Namespace,
+		Username:       Username,
+		Password:       Password,
+		PasswordDigest: true,
+		Timeout:        time.Second,
+	}
+}
+
+func (s *Server) Respond(operation, fixture string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.responses[operation] = fixture
+}
+
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request(nil), s.requests...)
+}
+
+type envelope struct {
+	Header struct {
+		Security struct {
+			UsernameToken struct {
+				Username string `xml:"Username"`
+				Password struct {
+					Type  string `xml:"Type,attr"`
+					Value string `xml:",chardata"`
+				} `xml:"Password"`
+				Nonce   string `xml:"Nonce"`
+				Created string `xml:"Created"`
+			} `xml:"UsernameToken"`
+		} `xml:"Security"`
+	} `xml:"Header"`
+	Body struct {
+		Content   string `xml:",innerxml"`
+		PageToken string `xml:"ListEmployeesRequest>PageToken"`
+	} `xml:"Body"`
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var request envelope
+	if r.Method != http.MethodPost || xml.Unmarshal(body, &request) != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	// the SOAPAction is "namespace/operation"
+	action := strings.Trim(r.Header.Get("SOAPAction"), `"`)
+	operation := action[strings.LastIndex(action, "/")+1:]
+	token := request.Header.Security.UsernameToken
+
+	s.mu.Lock()
+	s.requests = append(s.requests, Request{
+		Operation: operation,
+		Username:  token.Username,
+		Body:      strings.TrimSpace(request.Body.Content),
+	})
+	fixture, ok := s.responses[operation]
+	s.mu.Unlock()
+
+	switch {
+	case !authenticated(token.Username, token.Password.Type, token.Password.Value, token.Nonce, token.Created):
+		fixture = FixtureAuthenticationFailed
+	case !ok:
+		fixture = FixtureServerFault
+	case fixture == FixtureListEmployees && request.Body.PageToken != "":
+		fixture = "list_employees_" + request.Body.PageToken + ".xml"
+	}
+	s.write(w, fixture)
+}
+
+func (s *Server) write(w http.ResponseWriter, fixture string) {
+	content, err := fixtures.ReadFile("testdata/" + fixture)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+	if strings.HasPrefix(fixture, faultFixturePrefix) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+	_, _ = w.Write(content)
+}
+
+func authenticated(username, passwordType, password, nonce, created string) bool {
+	if username != Username {
+		return false
+	}
+	switch passwordType {
+	case quinyxgateway.PasswordText:
+		return password == Password
+	case quinyxgateway.PasswordDigest:
+		raw, err := base64.StdEncoding.DecodeString(nonce)
+		return err == nil && password == quinyxgateway.PasswordDigestOf(raw, created, Password)
+	}
+	return false
+}
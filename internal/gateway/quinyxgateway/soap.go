@@ -0,0 +1,104 @@
+package quinyxgateway
+
+import (
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"time"
+)
+
+const (
+	NamespaceSOAP     = "http://schemas.xmlsoap.org/soap/envelope/"
+	NamespaceWSSE     = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-secext-1.0.xsd"
+	NamespaceWSU      = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-wssecurity-utility-1.0.xsd"
+	PasswordText      = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordText"
+	PasswordDigest    = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-username-token-profile-1.0#PasswordDigest"
+	EncodingBase64    = "http://docs.oasis-open.org/wss/2004/01/oasis-200401-wss-soap-message-security-1.0#Base64Binary"
+	createdTimeLayout = "2006-01-02T15:04:05.000Z"
+)
+
+// the request envelope is written with prefixed names, the responses are read by local
+// names so any prefix the server picks works.
+type requestEnvelope struct {
+	XMLName   xml.Name      `xml:"soap:Envelope"`
+	XMLNSSoap string        `xml:"xmlns:soap,attr"`
+	Header    requestHeader `xml:"soap:Header"`
+	Body      requestBody   `xml:"soap:Body"`
+}
+
+type requestHeader struct {
+	Security security `xml:"wsse:Security"`
+}
+
+type requestBody struct {
+	Content interface{}
+}
+
+type security struct {
+	XMLNSWSSE      string        `xml:"xmlns:wsse,attr"`
+	XMLNSWSU       string        `xml:"xmlns:wsu,attr"`
+	MustUnderstand string        `xml:"soap:mustUnderstand,attr"`
+	UsernameToken  usernameToken `xml:"wsse:UsernameToken"`
+}
+
+type usernameToken struct {
+	Username string   `xml:"wsse:Username"`
+	Password password `xml:"wsse:Password"`
+	Nonce    *nonce   `xml:"wsse:Nonce,omitempty"`
+	Created  string   `xml:"wsu:Created,omitempty"`
+}
+
+type password struct {
+	Type  string `xml:"Type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type nonce struct {
+	EncodingType string `xml:"EncodingType,attr"`
+	Value        string `xml:",chardata"`
+}
+
+type responseEnvelope struct {
+	Body struct {
+		Fault   *Fault `xml:"Fault"`
+		Content []byte `xml:",innerxml"`
+	} `xml:"Body"`
+}
+
+// newEnvelope wraps the content with a WS-Security UsernameToken header. The digest is
+// Base64(SHA1(nonce + created + password)) as in the username token profile.
+func newEnvelope(content interface{}, username, secret string, digest bool, now time.Time) (requestEnvelope, error) {
+	token := usernameToken{
+		Username: username,
+		Password: password{Type: PasswordText, Value: secret},
+	}
+	if digest {
+		raw := make([]byte, 16)
+		if _, err := rand.Read(raw); err != nil {
+			return requestEnvelope{}, err
+		}
+		token.Created = now.UTC().Format(createdTimeLayout)
+		token.Nonce = &nonce{EncodingType: EncodingBase64, Value: base64.StdEncoding.EncodeToString(raw)}
+		token.Password = password{Type: PasswordDigest, Value: PasswordDigestOf(raw, token.Created, secret)}
+	}
+
+	return requestEnvelope{
+		XMLNSSoap: NamespaceSOAP,
+		Header: requestHeader{Security: security{
+			XMLNSWSSE:      NamespaceWSSE,
+			XMLNSWSU:       NamespaceWSU,
+			MustUnderstand: "1",
+			UsernameToken:  token,
+		}},
+		Body: requestBody{Content: content},
+	}, nil
+}
+
+func PasswordDigestOf(nonce []byte, created, secret string) string {
+	hash := sha1.New()
+	hash.Write(nonce)
+	hash.Write([]byte(created))
+	hash.Write([]byte(secret))
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
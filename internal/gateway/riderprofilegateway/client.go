@@ -0,0 +1,226 @@
+package riderprofilegateway
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-structure-demo/internal/circuitbreaker"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+)
+
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+
+	maxResponseSize = 1 << 20
+)
+
+var _ Client = (*Concrete)(nil)
+
+type withoutRetryKey struct{}
+
+// WithoutRetry makes the requests sent with ctx a single attempt, for the callers that retry
+// later on their own.
+func WithoutRetry(ctx context.Context) context.Context {
+	return context.WithValue(ctx, withoutRetryKey{}, true)
+}
+
+// Concrete is the JSON client of the rider profile service. The idempotent requests are
+// retried on the network errors, 429 and 5xx, and every attempt goes through a circuit
+// breaker so a service that is down fails the calls right away.
+type Concrete struct {
+	cfg     config.RiderProfile
+	logger  log.Logger
+	metrics metrics.Metrics
+	baseURL string
+	client  *http.Client
+	breaker *circuitbreaker.Breaker
+	random  func() float64
+}
+
+func New(cfg config.RiderProfile, logger log.Logger, metricsClient metrics.Metrics) *Concrete {
+	return &Concrete{
+		cfg:     cfg,
+		logger:  logger,
+		metrics: metricsClient,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		client:  httptrace.WrapClient(&http.Client{Timeout: cfg.Timeout}),
+		breaker: circuitbreaker.New(cfg.CircuitBreaker, func(from, to circuitbreaker.State) {
+			logger.Info("rider profile circuit breaker changed", map[string]interface{}{
+				"from": string(from),
+				"to":   string(to),
+			})
+			metricsClient.Count("riderprofile.circuit_breaker", 1, metrics.Tag("state", string(to)))
+		}),
+		random: rand.Float64,
+	}
+}
+
+func (c *Concrete) CreateRider(ctx context.Context, request CreateRiderRequest) (Rider, error) {
+	var rider Rider
+	err := c.do(ctx, http.MethodPost, "/v1/riders", request, request.IdempotencyKey, &rider)
+	return rider, err
+}
+
+func (c *Concrete) GetRider(ctx context.Context, id string) (Rider, error) {
+	var rider Rider
+	err := c.do(ctx, http.MethodGet, "/v1/riders/"+url.PathEscape(id), nil, "", &rider)
+	return rider, err
+}
+
+// do sends the request until it succeeds, fails for good or runs out of attempts. A request
+// without an idempotency key that is not a GET is sent once, the service could have acted on
+// it even when the response is lost, and so is a request made with WithoutRetry.
+func (c *Concrete) do(ctx context.Context, method, path string, body interface{}, idempotencyKey string, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	idempotent := method == http.MethodGet || idempotencyKey != ""
+	withoutRetry, _ := ctx.Value(withoutRetryKey{}).(bool)
+
+	for attempt := 1; ; attempt++ {
+		retryAfter, err := c.attempt(ctx, method, path, payload, idempotencyKey, out)
+		if err == nil || !idempotent || withoutRetry || attempt >= c.cfg.Retry.MaxAttempts || !retryable(err) || ctx.Err() != nil {
+			return err
+		}
+
+		delay := c.backoff(attempt, retryAfter)
+		c.metrics.Count("riderprofile.retry", 1, metrics.Tag("method", method))
+		c.logger.DebugWithContext(ctx, "retrying rider profile request", map[string]interface{}{
+			"method":     method,
+			"path":       path,
+			"attempt":    attempt,
+			"delay":      delay.String(),
+			log.KeyError: err.Error(),
+		})
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt returns the Retry-After of the error responses along with the error.
+func (c *Concrete) attempt(ctx context.Context, method, path string, payload []byte, idempotencyKey string, out interface{}) (time.Duration, error) {
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+	request, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return 0, err
+	}
+	request.Header.Set("Accept", "application/json")
+	if payload != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Token != "" {
+		request.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+	if idempotencyKey != "" {
+		request.Header.Set(HeaderIdempotencyKey, idempotencyKey)
+	}
+
+	done, err := c.breaker.Allow()
+	if err != nil {
+		c.metrics.Count("riderprofile.rejected", 1, metrics.Tag("method", method))
+		return 0, fmt.Errorf("riderprofile: %w", err)
+	}
+
+	start := time.Now()
+	response, err := c.client.Do(request)
+	if err != nil {
+		// a cancelled caller says nothing about the health of the service
+		if ctx.Err() != nil {
+			done(circuitbreaker.Ignored)
+		} else {
+			done(circuitbreaker.Failure)
+		}
+		return 0, fmt.Errorf("riderprofile: %w", err)
+	}
+	defer func() { _ = response.Body.Close() }()
+
+	responseBody, err := io.ReadAll(io.LimitReader(response.Body, maxResponseSize))
+	c.metrics.Timing("riderprofile.request", time.Since(start),
+		metrics.Tag("method", method),
+		metrics.Tag("status", strconv.Itoa(response.StatusCode)),
+	)
+	if err != nil {
+		done(circuitbreaker.Failure)
+		return 0, fmt.Errorf("riderprofile: reading response: %w", err)
+	}
+	if response.StatusCode >= 500 {
+		done(circuitbreaker.Failure)
+	} else {
+		done(circuitbreaker.Success)
+	}
+
+	if response.StatusCode >= 300 {
+		apiErr := &Error{StatusCode: response.StatusCode}
+		_ = json.Unmarshal(responseBody, apiErr)
+		return parseRetryAfter(response.Header.Get("Retry-After"), time.Now()), apiErr
+	}
+	if out != nil && len(responseBody) > 0 {
+		if err := json.Unmarshal(responseBody, out); err != nil {
+			return 0, fmt.Errorf("riderprofile: decoding response: %w", err)
+		}
+	}
+	return 0, nil
+}
+
+// backoff is an exponential backoff with full jitter, a longer Retry-After of the service is
+// honored up to MaxDelay.
+func (c *Concrete) backoff(attempt int, retryAfter time.Duration) time.Duration {
+	delay := c.cfg.Retry.BaseDelay << (attempt - 1)
+	if delay <= 0 || delay > c.cfg.Retry.MaxDelay {
+		delay = c.cfg.Retry.MaxDelay
+	}
+	delay = time.Duration(c.random() * float64(delay))
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	if delay > c.cfg.Retry.MaxDelay {
+		delay = c.cfg.Retry.MaxDelay
+	}
+	return delay
+}
+
+// retryable tells the transient errors, the transport errors are *url.Error.
+func retryable(err error) bool {
+	var urlErr *url.Error
+	return errors.Is(err, ErrRateLimited) || errors.Is(err, ErrUnavailable) || errors.As(err, &urlErr)
+}
+
+// parseRetryAfter reads both the seconds and the http date forms.
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(value); err == nil && at.After(now) {
+		return at.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,234 @@
+package riderprofilegateway
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-structure-demo/internal/circuitbreaker"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestClient(url string, metricsClient metrics.Metrics) *Concrete {
+	client := New(config.RiderProfile{
+		BaseURL: url + "/",
+		Token:   "secret",
+		Timeout: time.Second,
+		Retry: config.Retry{
+			MaxAttempts: 3,
+			BaseDelay:   time.Millisecond,
+			MaxDelay:    10 * time.Millisecond,
+		},
+		CircuitBreaker: config.CircuitBreaker{
+			FailureThreshold: 3,
+			OpenTimeout:      time.Minute,
+			HalfOpenRequests: 1,
+		},
+	}, log.NewMock("riderprofile"), metricsClient)
+	client.random = func() float64 { return 1 }
+	return client
+}
+
+func TestConcrete_CreateRider(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/v1/riders", r.URL.Path)
+		assert.Equal(t, "Bearer secret", r.Header.Get("Authorization"))
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Equal(t, "key-1", r.Header.Get(HeaderIdempotencyKey))
+
+		var request CreateRiderRequest
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&request))
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(Rider{ID: "r-1", Name: request.Name, Email: request.Email, Phone: request.Phone})
+	}))
+	defer server.Close()
+
+	rider, err := newTestClient(server.URL, metrics.NewNoop()).CreateRider(context.Background(), CreateRiderRequest{
+		Name:           "John Doe",
+		Email:          "john@doe.com",
+		Phone:          "+31600000000",
+		IdempotencyKey: "key-1",
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, Rider{ID: "r-1", Name: "John Doe", Email: "john@doe.com", Phone: "+31600000000"}, rider)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestConcrete_Retries(t *testing.T) {
+	testCases := []struct {
+		name           string
+		call           func(client *Concrete) error
+		statuses       []int
+		shouldCalls    int32
+		shouldErr      error
+		shouldAPIError bool
+	}{
+		{
+			name:        "get_retries_until_success",
+			call:        getRider,
+			statuses:    []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK},
+			shouldCalls: 3,
+		},
+		{
+			name:           "get_gives_up",
+			call:           getRider,
+			statuses:       []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			shouldCalls:    3,
+			shouldErr:      ErrUnavailable,
+			shouldAPIError: true,
+		},
+		{
+			name:           "client_errors_are_not_retried",
+			call:           getRider,
+			statuses:       []int{http.StatusNotFound, http.StatusOK},
+			shouldCalls:    1,
+			shouldErr:      ErrNotFound,
+			shouldAPIError: true,
+		},
+		{
+			name:           "create_without_key_is_sent_once",
+			call:           createRider(""),
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusCreated},
+			shouldCalls:    1,
+			shouldErr:      ErrUnavailable,
+			shouldAPIError: true,
+		},
+		{
+			name:        "create_with_key_is_retried",
+			call:        createRider("key-1"),
+			statuses:    []int{http.StatusServiceUnavailable, http.StatusCreated},
+			shouldCalls: 2,
+		},
+		{
+			name:           "conflict",
+			call:           createRider("key-1"),
+			statuses:       []int{http.StatusConflict},
+			shouldCalls:    1,
+			shouldErr:      ErrConflict,
+			shouldAPIError: true,
+		},
+		{
+			name:           "invalid_request",
+			call:           createRider("key-1"),
+			statuses:       []int{http.StatusUnprocessableEntity},
+			shouldCalls:    1,
+			shouldErr:      ErrInvalidRequest,
+			shouldAPIError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var calls int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				status := tc.statuses[atomic.AddInt32(&calls, 1)-1]
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(status)
+				if status >= 300 {
+					_, _ = w.Write([]byte(`{"code":"some_code","message":"some message"}`))
+					return
+				}
+				_, _ = w.Write([]byte(`{"id":"r-1"}`))
+			}))
+			defer server.Close()
+
+			metricsClient := metrics.NewMock()
+			err := tc.call(newTestClient(server.URL, metricsClient))
+			assert.Equal(t, tc.shouldCalls, calls)
+			assert.Equal(t, int64(tc.shouldCalls-1), metricsClient.Counter("riderprofile.retry"))
+			if tc.shouldErr == nil {
+				assert.Nil(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, tc.shouldErr)
+			var apiErr *Error
+			assert.Equal(t, tc.shouldAPIError, errors.As(err, &apiErr))
+			if tc.shouldAPIError {
+				assert.Equal(t, "some_code", apiErr.Code)
+				assert.Equal(t, "some message", apiErr.Message)
+			}
+		})
+	}
+}
+
+func TestConcrete_WithoutRetry(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	ctx := WithoutRetry(context.Background())
+	_, err := newTestClient(server.URL, metrics.NewNoop()).CreateRider(ctx, CreateRiderRequest{Name: "John Doe", IdempotencyKey: "key-1"})
+	assert.ErrorIs(t, err, ErrUnavailable)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestConcrete_CircuitBreaker(t *testing.T) {
+	var calls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := newTestClient(server.URL, metrics.NewNoop())
+	assert.ErrorIs(t, getRider(client), ErrUnavailable)
+	assert.Equal(t, int32(3), calls)
+
+	// the breaker is open now, nothing reaches the service
+	assert.ErrorIs(t, getRider(client), circuitbreaker.ErrOpen)
+	assert.ErrorIs(t, createRider("key-1")(client), circuitbreaker.ErrOpen)
+	assert.Equal(t, int32(3), calls)
+}
+
+func TestConcrete_Cancellation(t *testing.T) {
+	var calls int32
+	ctx, cancel := context.WithCancel(context.Background())
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		time.AfterFunc(10*time.Millisecond, cancel)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	// the caller gives up while waiting for the retry
+	client := newTestClient(server.URL, metrics.NewNoop())
+	client.cfg.Retry.BaseDelay = time.Hour
+	client.cfg.Retry.MaxDelay = time.Hour
+	_, err := client.GetRider(ctx, "r-1")
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(1), calls)
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2*time.Second, parseRetryAfter("2", now))
+	assert.Equal(t, time.Minute, parseRetryAfter(now.Add(time.Minute).Format(http.TimeFormat), now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("", now))
+	assert.Equal(t, time.Duration(0), parseRetryAfter("soon", now))
+}
+
+func getRider(client *Concrete) error {
+	_, err := client.GetRider(context.Background(), "r-1")
+	return err
+}
+
+func createRider(idempotencyKey string) func(client *Concrete) error {
+	return func(client *Concrete) error {
+		_, err := client.CreateRider(context.Background(), CreateRiderRequest{Name: "John Doe", IdempotencyKey: idempotencyKey})
+		return err
+	}
+}
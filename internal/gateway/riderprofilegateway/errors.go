@@ -0,0 +1,49 @@
+package riderprofilegateway
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// the errors of the responses, match them with errors.Is
+var (
+	ErrInvalidRequest = errors.New("riderprofile: invalid request")
+	ErrUnauthorized   = errors.New("riderprofile: unauthorized")
+	ErrNotFound       = errors.New("riderprofile: not found")
+	ErrConflict       = errors.New("riderprofile: conflict")
+	ErrRateLimited    = errors.New("riderprofile: rate limited")
+	ErrUnavailable    = errors.New("riderprofile: unavailable")
+)
+
+// Error is an error response of the rider profile service.
+type Error struct {
+	StatusCode int    `json:"-"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("riderprofile: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("riderprofile: status %d: %s", e.StatusCode, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	switch {
+	case e.StatusCode == http.StatusBadRequest || e.StatusCode == http.StatusUnprocessableEntity:
+		return ErrInvalidRequest
+	case e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden:
+		return ErrUnauthorized
+	case e.StatusCode == http.StatusNotFound:
+		return ErrNotFound
+	case e.StatusCode == http.StatusConflict:
+		return ErrConflict
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrRateLimited
+	case e.StatusCode >= 500:
+		return ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package riderprofilegateway
+
+import (
+	"context"
+	"go-structure-demo/internal/gateway/gatewayfake"
+)
+
+// the methods of the Client, to script the Fake
+const (
+	MethodCreateRider = "CreateRider"
+	MethodGetRider    = "GetRider"
+)
+
+var _ Client = (*Fake)(nil)
+
+// Fake is a programmable Client, see gatewayfake.Fake. It creates the riders and returns
+// them as asked by default. The scenarios name the errors in snake case, like
+// rate_limited.
+type Fake struct {
+	*gatewayfake.Fake
+}
+
+func NewFake() *Fake {
+	return &Fake{Fake: gatewayfake.New(
+		map[string]interface{}{
+			MethodCreateRider: Rider{},
+			MethodGetRider:    Rider{},
+		},
+		map[string]error{
+			"invalid_request": ErrInvalidRequest,
+			"unauthorized":    ErrUnauthorized,
+			"not_found":       ErrNotFound,
+			"conflict":        ErrConflict,
+			"rate_limited":    ErrRateLimited,
+			"unavailable":     ErrUnavailable,
+		},
+	)}
+}
+
+// LoadFake returns a Fake scripted with the scenario file.
+func LoadFake(path string) (*Fake, error) {
+	fake := NewFake()
+	if err := fake.LoadScenarioFile(path); err != nil {
+		return nil, err
+	}
+	return fake, nil
+}
+
+func (f *Fake) CreateRider(ctx context.Context, request CreateRiderRequest) (Rider, error) {
+	step := f.Call(ctx, MethodCreateRider, request)
+	if step.Err != nil {
+		return Rider{}, step.Err
+	}
+	if rider, ok := step.Result.(Rider); ok {
+		return rider, nil
+	}
+	return Rider{ID: "fake-" + request.Email, Name: request.Name, Email: request.Email, Phone: request.Phone}, nil
+}
+
+func (f *Fake) GetRider(ctx context.Context, id string) (Rider, error) {
+	step := f.Call(ctx, MethodGetRider, id)
+	if step.Err != nil {
+		return Rider{}, step.Err
+	}
+	if rider, ok := step.Result.(Rider); ok {
+		return rider, nil
+	}
+	return Rider{ID: id}, nil
+}
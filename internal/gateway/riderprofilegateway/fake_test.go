@@ -0,0 +1,55 @@
+package riderprofilegateway
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/gateway/gatewayfake"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+	fake.Script(MethodCreateRider, gatewayfake.Step{Err: ErrConflict})
+
+	_, err := fake.CreateRider(ctx, CreateRiderRequest{Email: "john@doe.com"})
+	assert.True(t, errors.Is(err, ErrConflict))
+	rider, err := fake.CreateRider(ctx, CreateRiderRequest{Name: "John Doe", Email: "john@doe.com", IdempotencyKey: "user-1"})
+	assert.Nil(t, err)
+	assert.Equal(t, Rider{ID: "fake-john@doe.com", Name: "John Doe", Email: "john@doe.com"}, rider)
+
+	calls := fake.Calls(MethodCreateRider)
+	assert.Len(t, calls, 2)
+	assert.Equal(t, "user-1", calls[1].Args[0].(CreateRiderRequest).IdempotencyKey)
+}
+
+func TestLoadFake(t *testing.T) {
+	fake, err := LoadFake("testdata/flaky.yaml")
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		_, err = fake.CreateRider(ctx, CreateRiderRequest{})
+		assert.True(t, errors.Is(err, ErrUnavailable))
+	}
+
+	// the third call is slower than the caller waits for
+	timeoutCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = fake.CreateRider(timeoutCtx, CreateRiderRequest{})
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+
+	for i := 0; i < 3; i++ {
+		_, err = fake.CreateRider(ctx, CreateRiderRequest{})
+		assert.True(t, errors.Is(err, ErrRateLimited))
+	}
+	_, err = fake.GetRider(ctx, "rider-42")
+	assert.True(t, errors.Is(err, ErrNotFound))
+	rider, err := fake.GetRider(ctx, "rider-42")
+	assert.Nil(t, err)
+	assert.Equal(t, Rider{ID: "rider-42"}, rider)
+	assert.Len(t, fake.Calls(""), 8)
+}
@@ -1,21 +1,25 @@
 package riderprofilegateway
 
-type Client interface {
-	CreateRider(name, email, phone string) error
-}
+import "context"
 
-type Concrete struct {
-}
-
-func (c *Concrete) CreateRider(name, email, phone string) error {
-	// some logics
-	return nil
+type Client interface {
+	// CreateRider is retried only when the request has an IdempotencyKey.
+	CreateRider(ctx context.Context, request CreateRiderRequest) (Rider, error)
+	GetRider(ctx context.Context, id string) (Rider, error)
 }
 
-type Mock struct {
+type Rider struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
 }
 
-func (m *Mock) CreateRider(name, email, phone string) error {
-	// some logics
-	return nil
+type CreateRiderRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+	// IdempotencyKey lets the rider profile service dedupe the retries, it is sent as the
+	// Idempotency-Key header.
+	IdempotencyKey string `json:"-"`
 }
@@ -0,0 +1,59 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+)
+
+// Elector runs a callback on a single replica at a time, the replica holding the lock of
+// the key is the leader.
+type Elector struct {
+	cfg    config.Lock
+	logger log.Logger
+	locker *Locker
+	key    string
+}
+
+func NewElector(cfg config.Lock, logger log.Logger, locker *Locker, key string) *Elector {
+	return &Elector{cfg: cfg, logger: logger, locker: locker, key: key}
+}
+
+// Run tries to become the leader every RetryInterval until ctx is done. As the leader it
+// calls fn with a context that is cancelled when the leadership is lost, once fn returns
+// the lock is released so another replica can take over right away.
+func (e *Elector) Run(ctx context.Context, fn func(ctx context.Context)) {
+	fields := map[string]interface{}{"key": e.key}
+	for {
+		lock, err := e.locker.Obtain(ctx, e.key, e.cfg.TTL)
+		switch {
+		case err == nil:
+			e.logger.InfoWithContext(ctx, "became the leader", fields)
+			fn(lock.Context())
+
+			// the parent context may be done already, the release must still go through
+			releaseCtx, cancel := context.WithTimeout(context.Background(), e.cfg.TTL)
+			if err := lock.Release(releaseCtx); err != nil && !errors.Is(err, ErrNotHeld) {
+				e.logger.ErrorWithContext(ctx, "releasing the leadership failed", map[string]interface{}{
+					"key":        e.key,
+					log.KeyError: err.Error(),
+				})
+			}
+			cancel()
+			e.logger.InfoWithContext(ctx, "stepped down", fields)
+		case errors.Is(err, ErrNotAcquired):
+		case ctx.Err() == nil:
+			e.logger.ErrorWithContext(ctx, "leader election failed", map[string]interface{}{
+				"key":        e.key,
+				log.KeyError: err.Error(),
+			})
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-e.locker.clock.After(e.cfg.RetryInterval):
+		}
+	}
+}
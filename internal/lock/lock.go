@@ -0,0 +1,174 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"go-structure-demo/internal/adapter/redis"
+	"go-structure-demo/internal/log"
+	"sync"
+	"time"
+)
+
+const keyPrefix = "lock:"
+
+var (
+	ErrNotAcquired = errors.New("lock: not acquired")
+	ErrNotHeld     = errors.New("lock: not held")
+)
+
+// Clock is the time of the locks, the tests use a manual one.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Locker hands out locks stored in redis, a lock is a key holding a random token so only
+// its holder can extend or release it.
+type Locker struct {
+	adapter redis.Adapter
+	logger  log.Logger
+	clock   Clock
+}
+
+func NewLocker(adapter redis.Adapter, logger log.Logger) *Locker {
+	return &Locker{adapter: adapter, logger: logger, clock: realClock{}}
+}
+
+// Lock is held until it is released or lost, it is extended every ttl/3 in the background.
+type Lock struct {
+	locker *Locker
+	key    string
+	token  string
+	ttl    time.Duration
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+}
+
+// Obtain tries to acquire the lock once, it returns ErrNotAcquired when someone else holds it.
+// The lock is extended until ctx is done, so cancelling ctx without a Release lets it expire.
+func (l *Locker) Obtain(ctx context.Context, key string, ttl time.Duration) (*Lock, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	// the key expires a ttl after the request at the latest
+	started := l.clock.Now()
+	acquired, err := l.adapter.SetNX(ctx, keyPrefix+key, token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, ErrNotAcquired
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	lock := &Lock{
+		locker: l,
+		key:    keyPrefix + key,
+		token:  token,
+		ttl:    ttl,
+		ctx:    lockCtx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+	go lock.keepAlive(started.Add(ttl))
+	return lock, nil
+}
+
+// Context is cancelled as soon as the lock is lost or released, the work guarded by the
+// lock must stop with it.
+func (l *Lock) Context() context.Context {
+	return l.ctx
+}
+
+// Release stops the extension and deletes the lock if it is still ours, it returns
+// ErrNotHeld when the lock was lost in the meantime.
+func (l *Lock) Release(ctx context.Context) error {
+	l.once.Do(l.cancel)
+	<-l.done
+
+	released, err := l.locker.adapter.DelIfEqual(ctx, l.key, l.token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return ErrNotHeld
+	}
+	return nil
+}
+
+// keepAlive extends the lock every ttl/3 until its context is done. The key expires a ttl
+// after the last extension that went through. When the extensions fail they are retried
+// every tenth of the ttl, and the lock is given up a third of the ttl before the key
+// expires, so the work it guards is stopped before another replica can take the key. The
+// lock is lost right away when another token took the key.
+func (l *Lock) keepAlive(expiresAt time.Time) {
+	defer close(l.done)
+	defer l.once.Do(l.cancel)
+
+	clock := l.locker.clock
+	interval := l.ttl / 3
+	wait := interval
+	for {
+		select {
+		case <-l.ctx.Done():
+			return
+		case <-clock.After(wait):
+		}
+
+		started := clock.Now()
+		giveUpAt := expiresAt.Add(-interval)
+		if !started.Before(giveUpAt) {
+			l.locker.logger.ErrorWithContext(l.ctx, "lock lost", map[string]interface{}{"key": l.key})
+			return
+		}
+
+		// an extension landing after the lock is given up is of no use
+		extendCtx, cancel := context.WithTimeout(l.ctx, giveUpAt.Sub(started))
+		extended, err := l.locker.adapter.ExpireIfEqual(extendCtx, l.key, l.token, l.ttl)
+		cancel()
+		if err != nil && l.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			l.locker.logger.ErrorWithContext(l.ctx, "extending lock failed", map[string]interface{}{
+				"key":        l.key,
+				log.KeyError: err.Error(),
+			})
+			wait = l.ttl / 10
+			if left := giveUpAt.Sub(clock.Now()); left < wait {
+				wait = left
+			}
+			continue
+		}
+		if !extended {
+			l.locker.logger.ErrorWithContext(l.ctx, "lock lost", map[string]interface{}{"key": l.key})
+			return
+		}
+		expiresAt = started.Add(l.ttl)
+		wait = interval
+	}
+}
+
+func newToken() (string, error) {
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(token), nil
+}
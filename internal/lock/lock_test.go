@@ -0,0 +1,178 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testTTL = 30 * time.Second
+
+// flakyMemory fails the extensions while failing is set.
+type flakyMemory struct {
+	*redistest.Memory
+	failing int32
+}
+
+func (m *flakyMemory) ExpireIfEqual(ctx context.Context, key string, value string, expiration time.Duration) (bool, error) {
+	if atomic.LoadInt32(&m.failing) == 1 {
+		return false, errors.New("connection refused")
+	}
+	return m.Memory.ExpireIfEqual(ctx, key, value, expiration)
+}
+
+func newTestLocker() (*Locker, *flakyMemory, *redistest.Clock) {
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	adapter := &flakyMemory{Memory: redistest.NewMemory(clock.Now)}
+	locker := NewLocker(adapter, log.NewMock("lock"))
+	locker.clock = clock
+	return locker, adapter, clock
+}
+
+// advance moves the clock once the keepAlive of the lock waits for it, a BlockUntil after it
+// waits for the keepAlive to be done with the tick.
+func advance(clock *redistest.Clock, d time.Duration) {
+	clock.BlockUntil(1)
+	clock.Advance(d)
+}
+
+func assertDone(t *testing.T, ctx context.Context) {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatal("lock context is not cancelled")
+	}
+}
+
+func TestLocker_Obtain(t *testing.T) {
+	ctx := context.Background()
+	locker, _, clock := newTestLocker()
+
+	lock, err := locker.Obtain(ctx, "job", testTTL)
+	assert.Nil(t, err)
+
+	_, err = locker.Obtain(ctx, "job", testTTL)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+
+	// held past its ttl because it is extended
+	for i := 0; i < 9; i++ {
+		advance(clock, testTTL/3)
+	}
+	_, err = locker.Obtain(ctx, "job", testTTL)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	assert.Nil(t, lock.Context().Err())
+
+	assert.Nil(t, lock.Release(ctx))
+	assert.NotNil(t, lock.Context().Err())
+	assert.ErrorIs(t, lock.Release(ctx), ErrNotHeld)
+
+	other, err := locker.Obtain(ctx, "job", testTTL)
+	assert.Nil(t, err)
+	assert.Nil(t, other.Release(ctx))
+}
+
+func TestLock_Lost(t *testing.T) {
+	ctx := context.Background()
+	locker, adapter, clock := newTestLocker()
+
+	lock, err := locker.Obtain(ctx, "job", testTTL)
+	assert.Nil(t, err)
+
+	// someone else takes over the key, like after a failover
+	assert.Nil(t, adapter.Set(ctx, keyPrefix+"job", "other-token", time.Minute))
+
+	advance(clock, testTTL/3)
+	assertDone(t, lock.Context())
+	assert.ErrorIs(t, lock.Release(ctx), ErrNotHeld)
+	value, _, _ := adapter.Get(ctx, keyPrefix+"job")
+	assert.Equal(t, "other-token", value)
+}
+
+func TestLock_ExtensionFailures(t *testing.T) {
+	ctx := context.Background()
+	locker, adapter, clock := newTestLocker()
+	started := clock.Now()
+
+	lock, err := locker.Obtain(ctx, "job", testTTL)
+	assert.Nil(t, err)
+
+	// a failed extension is retried and the lock is kept once one goes through
+	atomic.StoreInt32(&adapter.failing, 1)
+	advance(clock, testTTL/3)
+	clock.BlockUntil(1)
+	atomic.StoreInt32(&adapter.failing, 0)
+	advance(clock, testTTL/10)
+	clock.BlockUntil(1)
+	assert.Nil(t, lock.Context().Err())
+	extendedAt := clock.Now()
+
+	// the lock is given up a third of the ttl before the key expires, the extensions are
+	// retried every tenth of the ttl until then
+	atomic.StoreInt32(&adapter.failing, 1)
+	advance(clock, testTTL/3)
+	for i := 0; i < 3; i++ {
+		advance(clock, testTTL/10)
+	}
+	clock.BlockUntil(1)
+	assert.Nil(t, lock.Context().Err())
+	advance(clock, testTTL/30)
+	assertDone(t, lock.Context())
+	assert.Equal(t, extendedAt.Add(testTTL-testTTL/3), clock.Now())
+	assert.True(t, clock.Now().After(started.Add(testTTL)))
+
+	// the key is still held, nobody runs as the leader along with the lost lock
+	_, err = locker.Obtain(ctx, "job", testTTL)
+	assert.ErrorIs(t, err, ErrNotAcquired)
+	clock.Advance(testTTL/3 + time.Second)
+	other, err := locker.Obtain(ctx, "job", testTTL)
+	assert.Nil(t, err)
+	atomic.StoreInt32(&adapter.failing, 0)
+	assert.Nil(t, other.Release(ctx))
+}
+
+func TestElector_Run(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	locker, _, clock := newTestLocker()
+	cfg := config.Lock{TTL: testTTL, RetryInterval: time.Second}
+
+	leaders := int32(0)
+	started := make(chan struct{})
+	stop := make(chan struct{})
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			NewElector(cfg, log.NewMock("elector"), locker, "job").Run(ctx, func(ctx context.Context) {
+				assert.Equal(t, int32(1), atomic.AddInt32(&leaders, 1))
+				started <- struct{}{}
+				<-stop
+				atomic.AddInt32(&leaders, -1)
+			})
+		}()
+	}
+
+	// the leadership is handed over every time a leader steps down
+	for runs := 0; runs < 4; {
+		select {
+		case <-started:
+			runs++
+			stop <- struct{}{}
+		case <-time.After(time.Millisecond):
+			clock.Advance(cfg.RetryInterval)
+		}
+	}
+	cancel()
+	close(stop)
+	wg.Wait()
+}
@@ -2,7 +2,9 @@ package log
 
 import (
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Level string
@@ -10,6 +12,7 @@ type Level string
 const (
 	LevelDebug Level = "DEBUG"
 	LevelInfo  Level = "INFO"
+	LevelWarn  Level = "WARN"
 	LevelError Level = "ERROR"
 	LevelFatal Level = "FATAL"
 )
@@ -21,9 +24,20 @@ const (
 	FormatJSON    Format = "JSON"
 )
 
+// ParseLevel reads the level case insensitively, it reports whether the level is known.
+func ParseLevel(s string) (Level, bool) {
+	level := Level(strings.ToUpper(s))
+	switch level {
+	case LevelDebug, LevelInfo, LevelWarn, LevelError, LevelFatal:
+		return level, true
+	default:
+		return "", false
+	}
+}
+
 func configFromEnv() (Format, Level) {
-	level := Level(strings.ToUpper(os.Getenv("LOG_LEVEL")))
-	if level != LevelDebug && level != LevelInfo && level != LevelError && level != LevelFatal {
+	level, ok := ParseLevel(os.Getenv("LOG_LEVEL"))
+	if !ok {
 		level = LevelInfo
 	}
 
@@ -34,3 +48,45 @@ func configFromEnv() (Format, Level) {
 
 	return format, level
 }
+
+// redactorFromEnv masks the keys of LOG_REDACT_KEYS, a comma separated list, on top of the
+// default ones.
+func redactorFromEnv() *Redactor {
+	keys := append([]string(nil), DefaultRedactKeys...)
+	for _, key := range strings.Split(os.Getenv("LOG_REDACT_KEYS"), ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return NewRedactor(keys, DefaultRedactPatterns)
+}
+
+// samplingFromEnv reads the LOG_SAMPLING_* variables, LOG_SAMPLING_INITIAL=0 turns the
+// sampling off and LOG_SAMPLING_ERROR_INITIAL=0 exempts the errors.
+func samplingFromEnv() Sampling {
+	return Sampling{
+		Initial:         envInt("LOG_SAMPLING_INITIAL", 100),
+		Thereafter:      envInt("LOG_SAMPLING_THEREAFTER", 100),
+		Tick:            envDuration("LOG_SAMPLING_TICK", time.Second),
+		ErrorInitial:    envInt("LOG_SAMPLING_ERROR_INITIAL", 1000),
+		ErrorThereafter: envInt("LOG_SAMPLING_ERROR_THEREAFTER", 100),
+		SummaryInterval: envDuration("LOG_SAMPLING_SUMMARY_INTERVAL", defaultSummaryInterval),
+	}
+}
+
+// envInt returns fallback when the variable is not a number or is negative.
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+func envDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -29,6 +29,13 @@ func TestConfigFromEnv(t *testing.T) {
 			outLevel:  LevelError,
 			outFormat: FormatConsole,
 		},
+		{
+			name:      "warn_level",
+			envLevel:  "warn",
+			envFormat: "json",
+			outLevel:  LevelWarn,
+			outFormat: FormatJSON,
+		},
 		{
 			name:      "non_recognized_case_insensitive",
 			envLevel:  "not-correct",
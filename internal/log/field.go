@@ -0,0 +1,88 @@
+package log
+
+import (
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Field is a typed key value pair, it can be passed among the args of any log call and
+// bound to a child logger with With.
+type Field struct {
+	Key   string
+	Value interface{}
+}
+
+func String(key, value string) Field {
+	return Field{Key: key, Value: value}
+}
+
+func Int(key string, value int) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Err is the error under KeyError, nil errors are skipped.
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: KeyError, Value: err}
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, Value: value}
+}
+
+// Any is for the values without a constructor of their own.
+func Any(key string, value interface{}) Field {
+	return Field{Key: key, Value: value}
+}
+
+// splitFields takes the fields out of the args, the other args keep their order.
+func splitFields(args []interface{}) ([]Field, []interface{}) {
+	var fields []Field
+	rest := args[:0:0]
+	for _, arg := range args {
+		if field, ok := arg.(Field); ok {
+			fields = append(fields, field)
+			continue
+		}
+		rest = append(rest, arg)
+	}
+	if fields == nil {
+		return nil, args
+	}
+	return fields, rest
+}
+
+func (f Field) zap() zap.Field {
+	if f.Key == "" {
+		return zap.Skip()
+	}
+	switch value := f.Value.(type) {
+	case string:
+		return zap.String(f.Key, value)
+	case int:
+		return zap.Int(f.Key, value)
+	case time.Duration:
+		return zap.Duration(f.Key, value)
+	case error:
+		return zap.NamedError(f.Key, value)
+	default:
+		return zap.Any(f.Key, value)
+	}
+}
+
+// mock returns the value the MockLogger records, the errors are kept as their message like
+// the error args are.
+func (f Field) mock() (interface{}, bool) {
+	if f.Key == "" {
+		return nil, false
+	}
+	switch value := f.Value.(type) {
+	case error:
+		return value.Error(), true
+	default:
+		return value, true
+	}
+}
@@ -7,19 +7,34 @@ import (
 	"strings"
 )
 
+const (
+	KeyError = "error"
+)
+
 type Logger interface {
 	GetStd() *stdlog.Logger
+	// GetLevel returns the live level, the one of the last SetLevel if any.
 	GetLevel() Level
+	// SetLevel changes the level of the logger and of all the loggers derived from it.
+	SetLevel(level Level)
 	GetFormat() Format
 
 	Debug(msg string, args ...interface{})
 	DebugWithContext(ctx context.Context, msg string, args ...interface{})
 	Info(msg string, args ...interface{})
 	InfoWithContext(ctx context.Context, msg string, args ...interface{})
+	Warn(msg string, args ...interface{})
+	WarnWithContext(ctx context.Context, msg string, args ...interface{})
 	Error(msg string, args ...interface{})
 	ErrorWithContext(ctx context.Context, msg string, args ...interface{})
 	Fatal(msg string, args ...interface{})
 	FatalWithContext(ctx context.Context, msg string, args ...interface{})
+
+	// With returns a child logger adding the fields to every entry.
+	With(fields ...Field) Logger
+	// Named returns a child logger for a sub-component, its name is appended to the name of
+	// the logger after a dot.
+	Named(name string) Logger
 }
 
 func formatMessageWithArgs(msg string, args []interface{}) (string, []interface{}) {
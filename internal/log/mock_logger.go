@@ -20,6 +20,14 @@ type MockLogger struct {
 	Format      Format
 	Events      []string
 	LastEvent   string
+
+	// redactor masks the entries before they are recorded, nil is the DefaultRedactor
+	redactor *Redactor
+	// level is the one of GetLevel and SetLevel, Level is the one of the last entry
+	level Level
+	// root is the logger the children of With and Named record into, nil for the root itself
+	root   *MockLogger
+	fields []Field
 }
 
 func NewMock(name string) Logger {
@@ -29,6 +37,8 @@ func NewMock(name string) Logger {
 		Messages:    make([]string, 0),
 		LastMessage: "",
 		Level:       level,
+		level:       level,
+		redactor:    redactorFromEnv(),
 		Format:      format,
 		LastItems:   make(map[string]interface{}),
 		WasFatal:    false,
@@ -42,7 +52,17 @@ func (l *MockLogger) GetStd() *stdlog.Logger {
 }
 
 func (l *MockLogger) GetLevel() Level {
-	return l.Level
+	root := l.rootLogger()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+	return root.level
+}
+
+func (l *MockLogger) SetLevel(level Level) {
+	root := l.rootLogger()
+	root.executeInLock(func() {
+		root.level = level
+	})
 }
 
 func (l *MockLogger) GetFormat() Format {
@@ -55,92 +75,111 @@ func (l *MockLogger) executeInLock(f func()) {
 	f()
 }
 
-func (l *MockLogger) Debug(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsMock(msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelDebug
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = false
+// With returns a child recording into l, the bound fields are added to its LastItems.
+func (l *MockLogger) With(fields ...Field) Logger {
+	child := l.child(l.Name)
+	child.fields = append(child.fields, l.getRedactor().Fields(fields)...)
+	return child
+}
+
+func (l *MockLogger) Named(name string) Logger {
+	return l.child(l.Name + "." + name)
+}
+
+func (l *MockLogger) rootLogger() *MockLogger {
+	if l.root != nil {
+		return l.root
+	}
+	return l
+}
+
+func (l *MockLogger) getRedactor() *Redactor {
+	if l.redactor == nil {
+		return DefaultRedactor()
+	}
+	return l.redactor
+}
+
+func (l *MockLogger) child(name string) *MockLogger {
+	root := l.rootLogger()
+	return &MockLogger{
+		Name:     name,
+		Level:    l.Level,
+		Format:   l.Format,
+		root:     root,
+		redactor: l.redactor,
+		fields:   append([]Field(nil), l.fields...),
+	}
+}
+
+// record keeps the entry on the root logger, the fields of the args win over the bound ones.
+func (l *MockLogger) record(level Level, msg string, fields map[string]interface{}, fatal bool) {
+	for _, field := range l.fields {
+		if _, ok := fields[field.Key]; ok {
+			continue
+		}
+		if value, ok := field.mock(); ok {
+			fields[field.Key] = value
+		}
+	}
+	root := l.rootLogger()
+	root.executeInLock(func() {
+		root.Level = level
+		root.Messages = append(root.Messages, msg)
+		root.LastMessage = msg
+		root.LastItems = fields
+		root.WasFatal = fatal
 	})
 }
 
+func (l *MockLogger) Debug(msg string, args ...interface{}) {
+	msg, fields := buildMsgAndArgsMock(l.getRedactor(), msg, args...)
+	l.record(LevelDebug, msg, fields, false)
+}
+
 func (l *MockLogger) DebugWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextMock(ctx, msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelDebug
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = false
-	})
+	msg, fields := buildMsgAndArgsWithContextMock(ctx, l.getRedactor(), msg, args...)
+	l.record(LevelDebug, msg, fields, false)
 }
 
 func (l *MockLogger) Info(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsMock(msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelInfo
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = false
-	})
+	msg, fields := buildMsgAndArgsMock(l.getRedactor(), msg, args...)
+	l.record(LevelInfo, msg, fields, false)
 }
 
 func (l *MockLogger) InfoWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextMock(ctx, msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelInfo
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = false
-	})
+	msg, fields := buildMsgAndArgsWithContextMock(ctx, l.getRedactor(), msg, args...)
+	l.record(LevelInfo, msg, fields, false)
+}
+
+func (l *MockLogger) Warn(msg string, args ...interface{}) {
+	msg, fields := buildMsgAndArgsMock(l.getRedactor(), msg, args...)
+	l.record(LevelWarn, msg, fields, false)
+}
+
+func (l *MockLogger) WarnWithContext(ctx context.Context, msg string, args ...interface{}) {
+	msg, fields := buildMsgAndArgsWithContextMock(ctx, l.getRedactor(), msg, args...)
+	l.record(LevelWarn, msg, fields, false)
 }
 
 func (l *MockLogger) Error(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsMock(msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelError
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = false
-	})
+	msg, fields := buildMsgAndArgsMock(l.getRedactor(), msg, args...)
+	l.record(LevelError, msg, fields, false)
 }
 
 func (l *MockLogger) ErrorWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextMock(ctx, msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelError
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = false
-	})
+	msg, fields := buildMsgAndArgsWithContextMock(ctx, l.getRedactor(), msg, args...)
+	l.record(LevelError, msg, fields, false)
 }
 
 func (l *MockLogger) Fatal(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsMock(msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelFatal
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = true
-	})
+	msg, fields := buildMsgAndArgsMock(l.getRedactor(), msg, args...)
+	l.record(LevelFatal, msg, fields, true)
 }
 
 func (l *MockLogger) FatalWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextMock(ctx, msg, args...)
-	l.executeInLock(func() {
-		l.Level = LevelFatal
-		l.Messages = append(l.Messages, msg)
-		l.LastMessage = msg
-		l.LastItems = fields
-		l.WasFatal = false
-	})
+	msg, fields := buildMsgAndArgsWithContextMock(ctx, l.getRedactor(), msg, args...)
+	l.record(LevelFatal, msg, fields, false)
 }
 
 func normalizeValuesMock(values ...interface{}) map[string]interface{} {
@@ -167,13 +206,21 @@ func normalizeValuesMock(values ...interface{}) map[string]interface{} {
 	return fields
 }
 
-func buildMsgAndArgsMock(msg string, args ...interface{}) (string, map[string]interface{}) {
+func buildMsgAndArgsMock(redactor *Redactor, msg string, args ...interface{}) (string, map[string]interface{}) {
 	msg, args = formatMessageWithArgs(msg, args)
-	return msg, normalizeValuesMock(args...)
+	msg, args = redactor.String(msg), redactor.Args(args)
+	typed, args := splitFields(args)
+	fields := normalizeValuesMock(args...)
+	for _, field := range typed {
+		if value, ok := field.mock(); ok {
+			fields[field.Key] = value
+		}
+	}
+	return msg, fields
 }
 
-func buildMsgAndArgsWithContextMock(ctx context.Context, msg string, args ...interface{}) (string, map[string]interface{}) {
-	msg, fields := buildMsgAndArgsMock(msg, args...)
+func buildMsgAndArgsWithContextMock(ctx context.Context, redactor *Redactor, msg string, args ...interface{}) (string, map[string]interface{}) {
+	msg, fields := buildMsgAndArgsMock(redactor, msg, args...)
 	span, has := tracer.SpanFromContext(ctx)
 	if has {
 		fields["dd.trace_id"] = span.Context().TraceID()
@@ -374,6 +374,186 @@ func TestMockLogger_InfoWithContext(t *testing.T) {
 	}
 }
 
+func TestMockLogger_Warn(t *testing.T) {
+
+	name := "service-name"
+	logger := NewMock(name).(*MockLogger)
+
+	testCases := []struct {
+		name          string
+		msg           string
+		args          []interface{}
+		shouldLevel   Level
+		shouldLastMsg string
+		shouldItems   map[string]interface{}
+	}{
+		{
+			name:          "simple_msg",
+			msg:           "just a simple msg",
+			args:          nil,
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg",
+			shouldItems:   map[string]interface{}{},
+		},
+		{
+			name:          "formatted_msg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace"},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems:   map[string]interface{}{},
+		},
+		{
+			name: "formatted_msg_with_key_value_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", map[string]interface{}{
+				"key":  "value",
+				"key2": "value2",
+			}},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"key":  "value",
+				"key2": "value2",
+			},
+		},
+		{
+			name:          "formatted_msg_with_error_arg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace", errors.New("the new error")},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"error": "the new error",
+			},
+		},
+		{
+			name:          "formatted_msg_with_string_arg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace", "some random string"},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"extra": "some random string",
+			},
+		},
+		{
+			name:          "formatted_msg_with_number_arg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace", 15},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"extra": 15,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			logger.Warn(tc.msg, tc.args...)
+			assert.False(t, logger.WasFatal)
+			assert.Equal(t, logger.Level, tc.shouldLevel)
+			assert.Equal(t, logger.LastMessage, tc.shouldLastMsg)
+			assert.Equal(t, logger.LastItems, tc.shouldItems)
+		})
+	}
+}
+
+func TestMockLogger_WarnWithContext(t *testing.T) {
+
+	name := "service-name"
+	logger := NewMock(name).(*MockLogger)
+
+	testCases := []struct {
+		name          string
+		msg           string
+		args          []interface{}
+		shouldLevel   Level
+		shouldLastMsg string
+		shouldItems   map[string]interface{}
+	}{
+		{
+			name:          "simple_msg",
+			msg:           "just a simple msg",
+			args:          nil,
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg",
+			shouldItems:   map[string]interface{}{},
+		},
+		{
+			name:          "formatted_msg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace"},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems:   map[string]interface{}{},
+		},
+		{
+			name: "formatted_msg_with_key_value_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", map[string]interface{}{
+				"key":  "value",
+				"key2": "value2",
+			}},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"key":  "value",
+				"key2": "value2",
+			},
+		},
+		{
+			name:          "formatted_msg_with_error_arg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace", errors.New("the new error")},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"error": "the new error",
+			},
+		},
+		{
+			name:          "formatted_msg_with_string_arg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace", "some random string"},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"extra": "some random string",
+			},
+		},
+		{
+			name:          "formatted_msg_with_number_arg",
+			msg:           "just a simple msg %s",
+			args:          []interface{}{"the replace", 15},
+			shouldLevel:   LevelWarn,
+			shouldLastMsg: "just a simple msg the replace",
+			shouldItems: map[string]interface{}{
+				"extra": 15,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			span := tracer.StartSpan("test")
+			ctx := tracer.ContextWithSpan(context.Background(), span)
+			logger.WarnWithContext(ctx, tc.msg, tc.args...)
+			assert.False(t, logger.WasFatal)
+			assert.Equal(t, logger.Level, tc.shouldLevel)
+			assert.Equal(t, logger.LastMessage, tc.shouldLastMsg)
+			assert.Equal(t, span.Context().SpanID(), logger.LastItems["dd.span_id"])
+			assert.Equal(t, span.Context().TraceID(), logger.LastItems["dd.trace_id"])
+			for k, v := range tc.shouldItems {
+				assert.Equal(t, logger.LastItems[k], v)
+			}
+		})
+	}
+}
+
 func TestMockLogger_Error(t *testing.T) {
 
 	name := "service-name"
@@ -553,3 +733,25 @@ func TestMockLogger_ErrorWithContext(t *testing.T) {
 		})
 	}
 }
+
+func TestMockLogger_With(t *testing.T) {
+
+	name := "service-name"
+	logger := NewMock(name).(*MockLogger)
+	child := logger.Named("subscriber").With(String("subscription_id", "users"), Int("worker", 2))
+
+	child.Warn("just a simple msg", String("key", "value"), Int("worker", 3), Err(errors.New("the new error")))
+	assert.Equal(t, LevelWarn, logger.Level)
+	assert.Equal(t, []string{"just a simple msg"}, logger.Messages)
+	assert.Equal(t, map[string]interface{}{
+		"subscription_id": "users",
+		"worker":          3,
+		"key":             "value",
+		"error":           "the new error",
+	}, logger.LastItems)
+	assert.Equal(t, name+".subscriber", child.(*MockLogger).Name)
+
+	// the bound fields stay on the child
+	logger.Info("another msg", Err(nil))
+	assert.Equal(t, map[string]interface{}{}, logger.LastItems)
+}
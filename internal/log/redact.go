@@ -0,0 +1,220 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Redacted replaces the personal data and the credentials in the logs.
+const Redacted = "[REDACTED]"
+
+// DefaultRedactKeys are the field keys masked whatever their value, at any depth of the
+// fields. The keys are matched case insensitively.
+var DefaultRedactKeys = []string{
+	"email",
+	"first_name",
+	"last_name",
+	"full_name",
+	"phone",
+	"password",
+	"token",
+	"access_token",
+	"refresh_token",
+	"secret",
+	"authorization",
+	"api_key",
+	"x-api-key",
+	"cookie",
+	"set-cookie",
+}
+
+// DefaultRedactPatterns are masked in the messages and in the string values of the fields.
+var DefaultRedactPatterns = []*regexp.Regexp{
+	// the authorization headers, before the bearer tokens so the scheme is masked as well
+	regexp.MustCompile(`(?i)\bauthorization\b["']?\s*[:=]\s*["']?(?:(?:bearer|basic)\s+)?[^\s"',;]+`),
+	regexp.MustCompile(`(?i)\b(?:bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`),
+	// the jwts
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`),
+	// the api keys and the webhook secrets, see auth.APIKeyPrefix and webhook.NewSecret
+	regexp.MustCompile(`\b(?:gsd|whsec)_[A-Za-z0-9]+`),
+	// the one-time tokens in the query of the links, like the magic links
+	regexp.MustCompile(`[?&](token|code|key)=[^&\s]+`),
+	regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`),
+	// the phone numbers in the international format only, the local ones look like any id
+	regexp.MustCompile(`\+\d[\d\s().-]{6,}\d`),
+}
+
+// Redactor masks the configured keys and patterns, the loggers run the messages, the args
+// and the bound fields through it at every level.
+type Redactor struct {
+	keys     map[string]bool
+	patterns []*regexp.Regexp
+}
+
+func NewRedactor(keys []string, patterns []*regexp.Regexp) *Redactor {
+	r := &Redactor{keys: make(map[string]bool, len(keys)), patterns: patterns}
+	for _, key := range keys {
+		r.keys[strings.ToLower(key)] = true
+	}
+	return r
+}
+
+// DefaultRedactor masks the default keys and patterns.
+func DefaultRedactor() *Redactor {
+	return NewRedactor(DefaultRedactKeys, DefaultRedactPatterns)
+}
+
+// SensitiveValue is a value that never reaches the logs, see Sensitive.
+type SensitiveValue struct {
+	value interface{}
+}
+
+// Sensitive marks a value to be masked whatever its key, in a message like in a field:
+//
+//	logger.Info("rider %s synced", log.Sensitive(rider.Name))
+func Sensitive(value interface{}) SensitiveValue {
+	return SensitiveValue{value: value}
+}
+
+func (SensitiveValue) String() string {
+	return Redacted
+}
+
+func (SensitiveValue) GoString() string {
+	return Redacted
+}
+
+// Format prints Redacted for every verb, %d or %v would print the value otherwise.
+func (SensitiveValue) Format(f fmt.State, verb rune) {
+	_, _ = io.WriteString(f, Redacted)
+}
+
+func (SensitiveValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(Redacted)
+}
+
+// redactedError keeps the masked errors errors, so they are still logged under KeyError.
+type redactedError struct {
+	msg string
+}
+
+func (e redactedError) Error() string {
+	return e.msg
+}
+
+// String masks the patterns in s.
+func (r *Redactor) String(s string) string {
+	for _, pattern := range r.patterns {
+		s = pattern.ReplaceAllString(s, Redacted)
+	}
+	return s
+}
+
+// Args masks the args of a log call, the ones left after the formatting of the message.
+func (r *Redactor) Args(args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return args
+	}
+	redacted := make([]interface{}, 0, len(args))
+	for _, arg := range args {
+		switch typedArg := arg.(type) {
+		case Field:
+			redacted = append(redacted, r.Field(typedArg))
+		case error:
+			redacted = append(redacted, redactedError{msg: r.String(typedArg.Error())})
+		default:
+			redacted = append(redacted, r.value(arg))
+		}
+	}
+	return redacted
+}
+
+func (r *Redactor) Field(field Field) Field {
+	if err, ok := field.Value.(error); ok {
+		field.Value = redactedError{msg: r.String(err.Error())}
+		return field
+	}
+	field.Value = r.Value(field.Key, field.Value)
+	return field
+}
+
+func (r *Redactor) Fields(fields []Field) []Field {
+	redacted := make([]Field, 0, len(fields))
+	for _, field := range fields {
+		redacted = append(redacted, r.Field(field))
+	}
+	return redacted
+}
+
+// Value masks the value of the key, entirely when the key is a configured one.
+func (r *Redactor) Value(key string, value interface{}) interface{} {
+	if value != nil && r.keys[strings.ToLower(key)] {
+		return Redacted
+	}
+	return r.value(value)
+}
+
+func (r *Redactor) value(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64, time.Duration, time.Time:
+		return value
+	case SensitiveValue:
+		return Redacted
+	case string:
+		return r.String(typedValue)
+	case error:
+		return r.String(typedValue.Error())
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(typedValue))
+		for k, v := range typedValue {
+			redacted[k] = r.Value(k, v)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]string, len(typedValue))
+		for k, v := range typedValue {
+			redacted[k] = r.Value(k, v).(string)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, 0, len(typedValue))
+		for _, v := range typedValue {
+			redacted = append(redacted, r.value(v))
+		}
+		return redacted
+	case []string:
+		redacted := make([]string, 0, len(typedValue))
+		for _, v := range typedValue {
+			redacted = append(redacted, r.String(v))
+		}
+		return redacted
+	case json.RawMessage:
+		return r.raw(typedValue)
+	}
+
+	switch reflect.ValueOf(value).Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array, reflect.Ptr, reflect.Interface:
+		// the other values, like the entities, are logged the way they marshal
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return Redacted
+		}
+		return r.raw(encoded)
+	default:
+		return value
+	}
+}
+
+// raw masks a JSON document, anything else is masked as a string.
+func (r *Redactor) raw(encoded []byte) interface{} {
+	var decoded interface{}
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return r.String(string(encoded))
+	}
+	return r.value(decoded)
+}
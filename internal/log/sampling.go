@@ -0,0 +1,172 @@
+package log
+
+import (
+	"fmt"
+	"go-structure-demo/internal/metrics"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	metricDropped = "log.dropped"
+
+	defaultSummaryInterval = 10 * time.Second
+)
+
+// Sampling keeps a flood of the same message from overwhelming the log pipeline. In every
+// Tick, the first Initial entries of a level and message are written and then one in every
+// Thereafter. The errors have limits of their own, they are never dropped when ErrorInitial
+// is zero. The fatal entries are never dropped, they are the last words of the process.
+type Sampling struct {
+	// Initial is zero when the sampling is off
+	Initial    int
+	Thereafter int
+	Tick       time.Duration
+
+	ErrorInitial    int
+	ErrorThereafter int
+
+	// SummaryInterval is how often a summary of the dropped entries is written, 10s when
+	// it is zero
+	SummaryInterval time.Duration
+}
+
+func (s Sampling) enabled() bool {
+	return s.Initial > 0 && s.Tick > 0
+}
+
+// ReportDrops counts the entries the sampling of the logger drops from now on, as the
+// log.dropped metric tagged with their level. The loggers without sampling drop nothing. The
+// logger comes before the metrics, so they are set afterwards.
+func ReportDrops(logger Logger, metricsClient metrics.Metrics) {
+	z, ok := logger.(*ZapLogger)
+	if !ok || z.drops == nil {
+		return
+	}
+	z.drops.mu.Lock()
+	defer z.drops.mu.Unlock()
+	z.drops.metrics = metricsClient
+}
+
+// sampledCore samples core, level is the live level the sampled cores are enabled by.
+func sampledCore(core zapcore.Core, level zap.AtomicLevel, sampling Sampling, drops *drops) zapcore.Core {
+	hook := zapcore.SamplerHook(func(entry zapcore.Entry, decision zapcore.SamplingDecision) {
+		if decision&zapcore.LogDropped != 0 {
+			drops.add(entry.Level)
+		}
+	})
+
+	low := newLevelCore(core, func(l zapcore.Level) bool { return level.Enabled(l) && l < zapcore.ErrorLevel })
+	high := newLevelCore(core, func(l zapcore.Level) bool {
+		return level.Enabled(l) && l >= zapcore.ErrorLevel && l < zapcore.FatalLevel
+	})
+	fatal := newLevelCore(core, func(l zapcore.Level) bool { return level.Enabled(l) && l >= zapcore.FatalLevel })
+	cores := []zapcore.Core{fatal, zapcore.NewSamplerWithOptions(low, sampling.Tick, sampling.Initial, sampling.Thereafter, hook)}
+	if sampling.ErrorInitial > 0 {
+		cores = append(cores, zapcore.NewSamplerWithOptions(high, sampling.Tick, sampling.ErrorInitial, sampling.ErrorThereafter, hook))
+	} else {
+		cores = append(cores, high)
+	}
+	return zapcore.NewTee(cores...)
+}
+
+// levelCore restricts core to the levels of enabled.
+type levelCore struct {
+	zapcore.Core
+	enabled zap.LevelEnablerFunc
+}
+
+func newLevelCore(core zapcore.Core, enabled zap.LevelEnablerFunc) zapcore.Core {
+	return &levelCore{Core: core, enabled: enabled}
+}
+
+func (c *levelCore) Enabled(level zapcore.Level) bool {
+	return c.enabled(level)
+}
+
+func (c *levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &levelCore{Core: c.Core.With(fields), enabled: c.enabled}
+}
+
+func (c *levelCore) Check(entry zapcore.Entry, checked *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(entry.Level) {
+		return checked.AddCore(entry, c)
+	}
+	return checked
+}
+
+// drops counts the dropped entries per level and writes their summary every interval.
+type drops struct {
+	mu      sync.Mutex
+	dropped map[zapcore.Level]int64
+	metrics metrics.Metrics
+	// summary bypasses the sampling and the level, a summary is always written
+	summary *zap.Logger
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newDrops(summary *zap.Logger, interval time.Duration) *drops {
+	if interval <= 0 {
+		interval = defaultSummaryInterval
+	}
+	d := &drops{
+		dropped: make(map[zapcore.Level]int64),
+		summary: summary,
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go d.run(interval)
+	return d
+}
+
+func (d *drops) add(level zapcore.Level) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.dropped[level]++
+}
+
+func (d *drops) run(interval time.Duration) {
+	defer close(d.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.done:
+			d.flush()
+			return
+		case <-ticker.C:
+			d.flush()
+		}
+	}
+}
+
+// stop writes the summary of the drops left and stops the summaries.
+func (d *drops) stop() {
+	close(d.done)
+	<-d.stopped
+}
+
+func (d *drops) flush() {
+	d.mu.Lock()
+	dropped, m := d.dropped, d.metrics
+	d.dropped = make(map[zapcore.Level]int64)
+	d.mu.Unlock()
+	if len(dropped) == 0 {
+		return
+	}
+
+	var total int64
+	levels := make(map[string]int64, len(dropped))
+	for level, count := range dropped {
+		total += count
+		levels[level.String()] = count
+		if m != nil {
+			m.Count(metricDropped, count, metrics.Tag("level", level.String()))
+		}
+	}
+	d.summary.Warn(fmt.Sprintf("%d log messages dropped", total), zap.Int64("dropped", total), zap.Any("levels", levels))
+}
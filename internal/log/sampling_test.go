@@ -0,0 +1,181 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"go-structure-demo/internal/metrics"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestNewSampledZap(t *testing.T) {
+
+	testCases := []struct {
+		name          string
+		sampling      Sampling
+		level         Level
+		shouldWritten map[string]int
+		shouldDropped map[string]float64
+	}{
+		{
+			name:          "errors_exempt",
+			sampling:      Sampling{Initial: 2, Thereafter: 3, Tick: time.Minute},
+			level:         LevelDebug,
+			shouldWritten: map[string]int{"debug": 4, "warn": 4, "error": 8},
+			shouldDropped: map[string]float64{"debug": 4, "warn": 4},
+		},
+		{
+			name:          "errors_own_limits",
+			sampling:      Sampling{Initial: 2, Thereafter: 3, Tick: time.Minute, ErrorInitial: 5, ErrorThereafter: 10},
+			level:         LevelWarn,
+			shouldWritten: map[string]int{"warn": 4, "error": 5},
+			shouldDropped: map[string]float64{"warn": 4, "error": 3},
+		},
+		{
+			name:          "off",
+			sampling:      Sampling{},
+			level:         LevelDebug,
+			shouldWritten: map[string]int{"debug": 8, "warn": 8, "error": 8},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out := new(bytes.Buffer)
+			logger, closer := NewSampledZap("service-name", FormatJSON, tc.level, tc.sampling, zapcore.Lock(zapcore.AddSync(out)))
+			metricsClient := metrics.NewMock()
+			ReportDrops(logger, metricsClient)
+
+			for i := 0; i < 8; i++ {
+				logger.Debug("just a simple msg")
+				logger.With(Int("attempt", i)).Warn("just a simple msg")
+				logger.Error("just a simple msg")
+			}
+			// another message has limits of its own
+			logger.Warn("another msg")
+			closer()
+
+			written := make(map[string]int)
+			var summaries []map[string]interface{}
+			for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+				items := make(map[string]interface{})
+				assert.Nil(t, json.Unmarshal([]byte(line), &items))
+				switch items["msg"] {
+				case "just a simple msg":
+					written[items["level"].(string)]++
+				case "another msg":
+				default:
+					summaries = append(summaries, items)
+				}
+			}
+			assert.Equal(t, tc.shouldWritten, written)
+
+			var total float64
+			for _, count := range tc.shouldDropped {
+				total += count
+			}
+			if total == 0 {
+				assert.Empty(t, summaries)
+				return
+			}
+			if assert.Len(t, summaries, 1) {
+				levels := make(map[string]float64)
+				for level, count := range summaries[0]["levels"].(map[string]interface{}) {
+					levels[level] = count.(float64)
+				}
+				assert.Equal(t, tc.shouldDropped, levels)
+				assert.Equal(t, total, summaries[0]["dropped"])
+				assert.Equal(t, "warn", summaries[0]["level"])
+				assert.Equal(t, "service-name", summaries[0]["name"])
+			}
+			assert.Equal(t, int64(total), metricsClient.Counter(metricDropped))
+		})
+	}
+}
+
+func TestSampledCore_Fatal(t *testing.T) {
+	out := new(bytes.Buffer)
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.AddSync(out), zapcore.DebugLevel)
+	drops := newDrops(zap.NewNop(), time.Minute)
+	defer drops.stop()
+	sampling := Sampling{Initial: 1, Thereafter: 100, Tick: time.Minute, ErrorInitial: 1, ErrorThereafter: 100}
+	sampled := sampledCore(core, zap.NewAtomicLevelAt(zapcore.DebugLevel), sampling, drops)
+
+	// the entries are written without exiting, the exit is up to the logger
+	for i := 0; i < 3; i++ {
+		checked := sampled.Check(zapcore.Entry{Level: zapcore.FatalLevel, Message: "just a simple msg"}, nil)
+		if assert.NotNil(t, checked) {
+			checked.Write()
+		}
+	}
+	assert.Equal(t, 3, strings.Count(out.String(), `"level":"fatal"`))
+}
+
+// syncBuffer is read while the summaries are written.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) Sync() error {
+	return nil
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestNewSampledZap_Summary(t *testing.T) {
+	out := new(syncBuffer)
+	sampling := Sampling{Initial: 1, Thereafter: 100, Tick: time.Minute, ErrorInitial: 1, SummaryInterval: 10 * time.Millisecond}
+	logger, closer := NewSampledZap("service-name", FormatJSON, LevelError, sampling, out)
+	defer closer()
+
+	logger.Error("just a simple msg")
+	logger.Error("just a simple msg")
+	assert.Eventually(t, func() bool {
+		return strings.Contains(out.String(), `"msg":"1 log messages dropped"`)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestSamplingFromEnv(t *testing.T) {
+	defer func() {
+		for _, key := range []string{"LOG_SAMPLING_INITIAL", "LOG_SAMPLING_ERROR_INITIAL", "LOG_SAMPLING_TICK", "LOG_SAMPLING_SUMMARY_INTERVAL"} {
+			_ = os.Setenv(key, "")
+		}
+	}()
+
+	assert.Equal(t, Sampling{
+		Initial:         100,
+		Thereafter:      100,
+		Tick:            time.Second,
+		ErrorInitial:    1000,
+		ErrorThereafter: 100,
+		SummaryInterval: 10 * time.Second,
+	}, samplingFromEnv())
+
+	_ = os.Setenv("LOG_SAMPLING_INITIAL", "0")
+	_ = os.Setenv("LOG_SAMPLING_ERROR_INITIAL", "-1")
+	_ = os.Setenv("LOG_SAMPLING_TICK", "5s")
+	_ = os.Setenv("LOG_SAMPLING_SUMMARY_INTERVAL", "soon")
+	sampling := samplingFromEnv()
+	assert.False(t, sampling.enabled())
+	assert.Equal(t, 1000, sampling.ErrorInitial)
+	assert.Equal(t, 5*time.Second, sampling.Tick)
+	assert.Equal(t, 10*time.Second, sampling.SummaryInterval)
+}
@@ -7,22 +7,37 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 	stdlog "log"
 	"os"
+	"strings"
 )
 
 var _ Logger = (*ZapLogger)(nil)
 
 type ZapLogger struct {
 	internal *zap.Logger
-	level    Level
+	// level is shared with the children of With and Named
+	level    zap.AtomicLevel
 	format   Format
+	redactor *Redactor
+	// drops is nil without sampling
+	drops *drops
 }
 
 func NewZapFromEnv(name string) (Logger, func()) {
 	format, level := configFromEnv()
-	return NewZap(name, format, level, zapcore.Lock(os.Stdout))
+	return newZap(name, format, level, redactorFromEnv(), samplingFromEnv(), zapcore.Lock(os.Stdout))
 }
 
+// NewZap masks the default keys and patterns, see DefaultRedactor, and writes every entry.
 func NewZap(name string, format Format, level Level, writer zapcore.WriteSyncer) (Logger, func()) {
+	return newZap(name, format, level, DefaultRedactor(), Sampling{}, writer)
+}
+
+// NewSampledZap is NewZap with the sampling, the closer writes the summary of the last drops.
+func NewSampledZap(name string, format Format, level Level, sampling Sampling, writer zapcore.WriteSyncer) (Logger, func()) {
+	return newZap(name, format, level, DefaultRedactor(), sampling, writer)
+}
+
+func newZap(name string, format Format, level Level, redactor *Redactor, sampling Sampling, writer zapcore.WriteSyncer) (Logger, func()) {
 
 	encoderConfig := zap.NewProductionEncoderConfig()
 	encoderConfig.EncodeTime = zapcore.RFC3339TimeEncoder
@@ -32,84 +47,192 @@ func NewZap(name string, format Format, level Level, writer zapcore.WriteSyncer)
 		encoder = zapcore.NewConsoleEncoder(encoderConfig)
 	}
 
-	var coreLevel zap.AtomicLevel
-	switch level {
-	case LevelDebug:
-		coreLevel = zap.NewAtomicLevelAt(zapcore.DebugLevel)
-	case LevelInfo:
-		coreLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
-	case LevelError:
-		coreLevel = zap.NewAtomicLevelAt(zapcore.ErrorLevel)
-	default:
-		coreLevel = zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	coreLevel := zap.NewAtomicLevelAt(zapLevel(level))
+
+	core := zapcore.NewCore(encoder, writer, coreLevel)
+	var drops *drops
+	if sampling.enabled() {
+		summary := zap.New(zapcore.NewCore(encoder, writer, zap.LevelEnablerFunc(func(zapcore.Level) bool { return true })))
+		drops = newDrops(summary.Named(name), sampling.SummaryInterval)
+		core = sampledCore(core, coreLevel, sampling, drops)
 	}
 
-	logger := zap.New(zapcore.NewCore(encoder, writer, coreLevel))
+	logger := zap.New(core)
 	logger = logger.Named(name)
 	zap.ReplaceGlobals(logger)
-	_ = zap.RedirectStdLog(logger)
+	// like zap.RedirectStdLog, but through the redactor
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.SetOutput(stdWriter{logger: logger, redactor: redactor})
 
 	return &ZapLogger{
 			internal: logger,
-			level:    level,
+			level:    coreLevel,
 			format:   format,
+			redactor: redactor,
+			drops:    drops,
 		}, func() {
+			if drops != nil {
+				drops.stop()
+			}
 			_ = logger.Sync()
 		}
 }
 
+// GetStd returns a standard logger writing at info, its messages are masked like the others.
 func (z *ZapLogger) GetStd() *stdlog.Logger {
-	return zap.NewStdLog(z.internal)
+	return stdlog.New(stdWriter{logger: z.internal, redactor: z.redactor}, "", 0)
+}
+
+// stdWriter logs every line of a standard logger, the ones of the libraries like net/http.
+type stdWriter struct {
+	logger   *zap.Logger
+	redactor *Redactor
+}
+
+func (w stdWriter) Write(p []byte) (int, error) {
+	w.logger.Info(w.redactor.String(strings.TrimSuffix(string(p), "\n")))
+	return len(p), nil
 }
 
 func (z *ZapLogger) GetLevel() Level {
-	return z.level
+	switch z.level.Level() {
+	case zapcore.DebugLevel:
+		return LevelDebug
+	case zapcore.WarnLevel:
+		return LevelWarn
+	case zapcore.ErrorLevel:
+		return LevelError
+	case zapcore.FatalLevel:
+		return LevelFatal
+	default:
+		return LevelInfo
+	}
+}
+
+func (z *ZapLogger) SetLevel(level Level) {
+	z.level.SetLevel(zapLevel(level))
+}
+
+// zapLevel maps the unknown levels to info, like configFromEnv does.
+func zapLevel(level Level) zapcore.Level {
+	switch level {
+	case LevelDebug:
+		return zapcore.DebugLevel
+	case LevelWarn:
+		return zapcore.WarnLevel
+	case LevelError:
+		return zapcore.ErrorLevel
+	case LevelFatal:
+		return zapcore.FatalLevel
+	default:
+		return zapcore.InfoLevel
+	}
 }
 
 func (z *ZapLogger) GetFormat() Format {
 	return z.format
 }
 
+// enabled skips the redaction of the entries that would be dropped anyway.
+func (z *ZapLogger) enabled(level zapcore.Level) bool {
+	return z.level.Enabled(level)
+}
+
 func (z *ZapLogger) Debug(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsZap(msg, args...)
+	if !z.enabled(zapcore.DebugLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsZap(z.redactor, msg, args...)
 	z.internal.Debug(msg, fields...)
 }
 
 func (z *ZapLogger) DebugWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextZap(ctx, msg, args...)
+	if !z.enabled(zapcore.DebugLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsWithContextZap(ctx, z.redactor, msg, args...)
 	z.internal.Debug(msg, fields...)
 }
 
 func (z *ZapLogger) Info(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsZap(msg, args...)
+	if !z.enabled(zapcore.InfoLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsZap(z.redactor, msg, args...)
 	z.internal.Info(msg, fields...)
 }
 
 func (z *ZapLogger) InfoWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextZap(ctx, msg, args...)
+	if !z.enabled(zapcore.InfoLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsWithContextZap(ctx, z.redactor, msg, args...)
 	z.internal.Info(msg, fields...)
 }
 
+func (z *ZapLogger) Warn(msg string, args ...interface{}) {
+	if !z.enabled(zapcore.WarnLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsZap(z.redactor, msg, args...)
+	z.internal.Warn(msg, fields...)
+}
+
+func (z *ZapLogger) WarnWithContext(ctx context.Context, msg string, args ...interface{}) {
+	if !z.enabled(zapcore.WarnLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsWithContextZap(ctx, z.redactor, msg, args...)
+	z.internal.Warn(msg, fields...)
+}
+
 func (z *ZapLogger) Error(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsZap(msg, args...)
+	if !z.enabled(zapcore.ErrorLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsZap(z.redactor, msg, args...)
 	z.internal.Error(msg, fields...)
 }
 
 func (z *ZapLogger) ErrorWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextZap(ctx, msg, args...)
+	if !z.enabled(zapcore.ErrorLevel) {
+		return
+	}
+	msg, fields := buildMsgAndArgsWithContextZap(ctx, z.redactor, msg, args...)
 	z.internal.Error(msg, fields...)
 }
 
 func (z *ZapLogger) Fatal(msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsZap(msg, args...)
+	msg, fields := buildMsgAndArgsZap(z.redactor, msg, args...)
 	z.internal.Fatal(msg, fields...)
 }
 
 func (z *ZapLogger) FatalWithContext(ctx context.Context, msg string, args ...interface{}) {
-	msg, fields := buildMsgAndArgsWithContextZap(ctx, msg, args...)
+	msg, fields := buildMsgAndArgsWithContextZap(ctx, z.redactor, msg, args...)
 	z.internal.Fatal(msg, fields...)
 }
 
+func (z *ZapLogger) With(fields ...Field) Logger {
+	child := *z
+	child.internal = z.internal.With(fieldsZap(z.redactor.Fields(fields))...)
+	return &child
+}
+
+func (z *ZapLogger) Named(name string) Logger {
+	child := *z
+	child.internal = z.internal.Named(name)
+	return &child
+}
+
+func fieldsZap(fields []Field) []zap.Field {
+	zapFields := make([]zap.Field, 0, len(fields))
+	for _, field := range fields {
+		zapFields = append(zapFields, field.zap())
+	}
+	return zapFields
+}
+
 func normalizeValuesZap(values ...interface{}) []zap.Field {
 	fields := make([]zap.Field, 0, len(values))
 	if len(values) == 1 {
@@ -134,15 +257,17 @@ func normalizeValuesZap(values ...interface{}) []zap.Field {
 	return fields
 }
 
-func buildMsgAndArgsZap(msg string, args ...interface{}) (string, []zap.Field) {
+func buildMsgAndArgsZap(redactor *Redactor, msg string, args ...interface{}) (string, []zap.Field) {
 	msg, args = formatMessageWithArgs(msg, args)
-	var fields []zap.Field
+	msg, args = redactor.String(msg), redactor.Args(args)
+	typed, args := splitFields(args)
+	fields := fieldsZap(typed)
 	fields = append(fields, normalizeValuesZap(args...)...)
 	return msg, fields
 }
 
-func buildMsgAndArgsWithContextZap(ctx context.Context, msg string, args ...interface{}) (string, []zap.Field) {
-	msg, fields := buildMsgAndArgsZap(msg, args...)
+func buildMsgAndArgsWithContextZap(ctx context.Context, redactor *Redactor, msg string, args ...interface{}) (string, []zap.Field) {
+	msg, fields := buildMsgAndArgsZap(redactor, msg, args...)
 	span, has := tracer.SpanFromContext(ctx)
 	if has {
 		fields = append(
@@ -11,6 +11,7 @@ import (
 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 	"os"
 	"testing"
+	"time"
 )
 
 func TestNewZapFromEnv(t *testing.T) {
@@ -439,6 +440,212 @@ func TestZapLogger_InfoWithContext(t *testing.T) {
 	}
 }
 
+func TestZapLogger_Warn(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	writer := zapcore.AddSync(out)
+	name := "service-name"
+	logger, syncer := NewZap(name, FormatJSON, LevelDebug, writer)
+	defer syncer()
+
+	testCases := []struct {
+		name      string
+		msg       string
+		args      []interface{}
+		shouldHas map[string]interface{}
+	}{
+		{
+			name: "simple_msg",
+			msg:  "just a simple msg",
+			args: nil,
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg",
+				"name":  name,
+			},
+		},
+		{
+			name: "formatted_msg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace"},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+			},
+		},
+		{
+			name: "formatted_msg_with_key_value_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", map[string]interface{}{
+				"key":  "value",
+				"key2": "value2",
+			}},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"key":   "value",
+				"key2":  "value2",
+			},
+		},
+		{
+			name: "formatted_msg_with_error_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", errors.New("the new error")},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"error": "the new error",
+			},
+		},
+		{
+			name: "formatted_msg_with_string_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", "some random string"},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"extra": "some random string",
+			},
+		},
+		{
+			name: "formatted_msg_with_number_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", 15},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"extra": float64(15),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out.Reset()
+			logger.Warn(tc.msg, tc.args...)
+			items := make(map[string]interface{})
+			err := json.Unmarshal(out.Bytes(), &items)
+			assert.Nil(t, err)
+			for k, v := range tc.shouldHas {
+				res, has := items[k]
+				assert.True(t, has)
+				assert.Equal(t, v, res)
+			}
+		})
+	}
+}
+
+func TestZapLogger_WarnWithContext(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	writer := zapcore.AddSync(out)
+	name := "service-name"
+	logger, syncer := NewZap(name, FormatJSON, LevelDebug, writer)
+	defer syncer()
+
+	testCases := []struct {
+		name      string
+		msg       string
+		args      []interface{}
+		shouldHas map[string]interface{}
+	}{
+		{
+			name: "simple_msg",
+			msg:  "just a simple msg",
+			args: nil,
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg",
+				"name":  name,
+			},
+		},
+		{
+			name: "formatted_msg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace"},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+			},
+		},
+		{
+			name: "formatted_msg_with_key_value_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", map[string]interface{}{
+				"key":  "value",
+				"key2": "value2",
+			}},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"key":   "value",
+				"key2":  "value2",
+			},
+		},
+		{
+			name: "formatted_msg_with_error_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", errors.New("the new error")},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"error": "the new error",
+			},
+		},
+		{
+			name: "formatted_msg_with_string_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", "some random string"},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"extra": "some random string",
+			},
+		},
+		{
+			name: "formatted_msg_with_number_arg",
+			msg:  "just a simple msg %s",
+			args: []interface{}{"the replace", 15},
+			shouldHas: map[string]interface{}{
+				"level": "warn",
+				"msg":   "just a simple msg the replace",
+				"name":  name,
+				"extra": float64(15),
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out.Reset()
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			span := tracer.StartSpan("test")
+			ctx := tracer.ContextWithSpan(context.Background(), span)
+			logger.WarnWithContext(ctx, tc.msg, tc.args...)
+			items := make(map[string]interface{})
+			err := json.Unmarshal(out.Bytes(), &items)
+			assert.Nil(t, err)
+			for k, v := range tc.shouldHas {
+				res, has := items[k]
+				assert.True(t, has)
+				assert.Equal(t, v, res)
+			}
+			assert.Equal(t, float64(span.Context().SpanID()), items["dd.span_id"])
+			assert.Equal(t, float64(span.Context().TraceID()), items["dd.trace_id"])
+		})
+	}
+}
+
 func TestZapLogger_Error(t *testing.T) {
 
 	out := new(bytes.Buffer)
@@ -644,3 +851,135 @@ func TestZapLogger_ErrorWithContext(t *testing.T) {
 		})
 	}
 }
+
+func TestZapLogger_Fields(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	writer := zapcore.AddSync(out)
+	name := "service-name"
+	logger, syncer := NewZap(name, FormatJSON, LevelDebug, writer)
+	defer syncer()
+
+	testCases := []struct {
+		name      string
+		logger    Logger
+		msg       string
+		args      []interface{}
+		shouldHas map[string]interface{}
+		shouldNot []string
+	}{
+		{
+			name:   "typed_fields",
+			logger: logger,
+			msg:    "just a simple msg %s",
+			args: []interface{}{
+				"the replace",
+				String("key", "value"),
+				Int("count", 3),
+				Duration("took", 1500*time.Millisecond),
+				Err(errors.New("the new error")),
+			},
+			shouldHas: map[string]interface{}{
+				"msg":   "just a simple msg the replace",
+				"key":   "value",
+				"count": float64(3),
+				"took":  1.5,
+				"error": "the new error",
+			},
+			shouldNot: []string{"extra"},
+		},
+		{
+			name:   "typed_fields_with_key_value_arg",
+			logger: logger,
+			msg:    "just a simple msg",
+			args:   []interface{}{map[string]interface{}{"key": "value"}, Int("count", 3)},
+			shouldHas: map[string]interface{}{
+				"key":   "value",
+				"count": float64(3),
+			},
+			shouldNot: []string{"extra"},
+		},
+		{
+			name:      "nil_error",
+			logger:    logger,
+			msg:       "just a simple msg",
+			args:      []interface{}{Err(nil)},
+			shouldHas: map[string]interface{}{"msg": "just a simple msg"},
+			shouldNot: []string{"error", "extra"},
+		},
+		{
+			name:   "with",
+			logger: logger.With(String("subscription_id", "users"), Int("worker", 2)),
+			msg:    "just a simple msg",
+			args:   []interface{}{String("key", "value")},
+			shouldHas: map[string]interface{}{
+				"name":            name,
+				"subscription_id": "users",
+				"worker":          float64(2),
+				"key":             "value",
+			},
+		},
+		{
+			name:   "named_with",
+			logger: logger.Named("subscriber").With(String("subscription_id", "users")).Named("router"),
+			msg:    "just a simple msg",
+			shouldHas: map[string]interface{}{
+				"name":            name + ".subscriber.router",
+				"subscription_id": "users",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out.Reset()
+			tc.logger.Warn(tc.msg, tc.args...)
+			items := make(map[string]interface{})
+			err := json.Unmarshal(out.Bytes(), &items)
+			assert.Nil(t, err)
+			assert.Equal(t, "warn", items["level"])
+			for k, v := range tc.shouldHas {
+				res, has := items[k]
+				assert.True(t, has)
+				assert.Equal(t, v, res)
+			}
+			for _, k := range tc.shouldNot {
+				assert.NotContains(t, items, k)
+			}
+		})
+	}
+}
+
+func TestZapLogger_SetLevel(t *testing.T) {
+
+	out := new(bytes.Buffer)
+	writer := zapcore.AddSync(out)
+	logger, syncer := NewZap("service-name", FormatJSON, LevelError, writer)
+	defer syncer()
+	child := logger.Named("child").With(String("key", "value"))
+
+	testCases := []struct {
+		name        string
+		level       Level
+		shouldLevel Level
+		shouldLog   bool
+	}{
+		{name: "configured", shouldLevel: LevelError, shouldLog: false},
+		{name: "debug", level: LevelDebug, shouldLevel: LevelDebug, shouldLog: true},
+		{name: "warn", level: LevelWarn, shouldLevel: LevelWarn, shouldLog: false},
+		{name: "unknown_is_info", level: Level("TRACE"), shouldLevel: LevelInfo, shouldLog: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			out.Reset()
+			if tc.level != "" {
+				logger.SetLevel(tc.level)
+			}
+			assert.Equal(t, tc.shouldLevel, logger.GetLevel())
+			assert.Equal(t, tc.shouldLevel, child.GetLevel())
+			child.Info("just a simple msg")
+			assert.Equal(t, tc.shouldLog, out.Len() > 0)
+		})
+	}
+}
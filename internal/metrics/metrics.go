@@ -0,0 +1,16 @@
+package metrics
+
+import "time"
+
+// Metrics is the minimal set of instruments the service reports, tags are
+// passed in the "key:value" form.
+type Metrics interface {
+	Count(name string, value int64, tags ...string)
+	Gauge(name string, value float64, tags ...string)
+	Timing(name string, value time.Duration, tags ...string)
+}
+
+// Tag builds a "key:value" tag.
+func Tag(key, value string) string {
+	return key + ":" + value
+}
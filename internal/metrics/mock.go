@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"sync"
+	"time"
+)
+
+var _ Metrics = (*Mock)(nil)
+
+// Mock keeps the reported values in memory so tests can assert on them.
+type Mock struct {
+	mu       sync.Mutex
+	Counters map[string]int64
+	Gauges   map[string]float64
+	Timings  map[string][]time.Duration
+}
+
+func NewMock() *Mock {
+	return &Mock{
+		Counters: make(map[string]int64),
+		Gauges:   make(map[string]float64),
+		Timings:  make(map[string][]time.Duration),
+	}
+}
+
+func (m *Mock) Count(name string, value int64, tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Counters[name] += value
+}
+
+func (m *Mock) Gauge(name string, value float64, tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Gauges[name] = value
+}
+
+func (m *Mock) Timing(name string, value time.Duration, tags ...string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.Timings[name] = append(m.Timings[name], value)
+}
+
+func (m *Mock) Counter(name string) int64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Counters[name]
+}
+
+func (m *Mock) Gauged(name string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.Gauges[name]
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import "time"
+
+var _ Metrics = (*Noop)(nil)
+
+type Noop struct{}
+
+func NewNoop() Metrics {
+	return new(Noop)
+}
+
+func (n *Noop) Count(name string, value int64, tags ...string) {}
+
+func (n *Noop) Gauge(name string, value float64, tags ...string) {}
+
+func (n *Noop) Timing(name string, value time.Duration, tags ...string) {}
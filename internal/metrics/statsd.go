@@ -0,0 +1,41 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+	"go-structure-demo/internal/config"
+)
+
+var _ Metrics = (*Statsd)(nil)
+
+type Statsd struct {
+	client *statsd.Client
+}
+
+func New(cfg config.Metrics) (Metrics, func(), error) {
+	if !cfg.Enabled {
+		return NewNoop(), func() {}, nil
+	}
+
+	client, err := statsd.New(cfg.Address, statsd.WithNamespace(cfg.Namespace))
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	return &Statsd{client: client}, func() {
+		_ = client.Close()
+	}, nil
+}
+
+func (s *Statsd) Count(name string, value int64, tags ...string) {
+	_ = s.client.Count(name, value, tags, 1)
+}
+
+func (s *Statsd) Gauge(name string, value float64, tags ...string) {
+	_ = s.client.Gauge(name, value, tags, 1)
+}
+
+func (s *Statsd) Timing(name string, value time.Duration, tags ...string) {
+	_ = s.client.Timing(name, value, tags, 1)
+}
@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"os"
+	"sync"
+	"time"
+)
+
+var _ contract.Notifier = (*File)(nil)
+
+// File appends the notifications to a file as json lines, so a local setup or a test can
+// pick the links up.
+type File struct {
+	mu   sync.Mutex
+	file *os.File
+	// mask masks the tokens of the links, outside of the local env
+	mask bool
+}
+
+func NewFile(path string) (*File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	return &File{file: file}, nil
+}
+
+func (f *File) Notify(ctx context.Context, notification entity.Notification) error {
+	if f.mask {
+		notification.Body = maskLinks(notification.Body)
+	}
+	line, err := json.Marshal(struct {
+		entity.Notification
+		SentAt time.Time `json:"sent_at"`
+	}{notification, time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	_, err = f.file.Write(append(line, '\n'))
+	return err
+}
+
+func (f *File) Close() error {
+	return f.file.Close()
+}
@@ -0,0 +1,29 @@
+package notifier
+
+import (
+	"context"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+)
+
+var _ contract.Notifier = (*Log)(nil)
+
+// Log writes the notifications to the log instead of sending them. The tokens of the links
+// are masked since the logs are shipped, use the file driver to open the links.
+type Log struct {
+	logger log.Logger
+}
+
+func NewLog(logger log.Logger) *Log {
+	return &Log{logger: logger}
+}
+
+func (l *Log) Notify(ctx context.Context, notification entity.Notification) error {
+	l.logger.InfoWithContext(ctx, "notification", map[string]interface{}{
+		"to":      notification.To,
+		"subject": notification.Subject,
+		"body":    maskLinks(notification.Body),
+	})
+	return nil
+}
@@ -0,0 +1,52 @@
+package notifier
+
+import (
+	"fmt"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/log"
+	"regexp"
+)
+
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+)
+
+// linkToken matches the one-time tokens in the query of the links.
+var linkToken = regexp.MustCompile(`([?&](?:token|code|key)=)[^&\s]+`)
+
+// maskLinks masks the one-time tokens of the links in body.
+func maskLinks(body string) string {
+	return linkToken.ReplaceAllString(body, "${1}"+log.Redacted)
+}
+
+// New returns the notifier of the configured driver. Both drivers are meant for the local
+// environments, none of them delivers the notifications. In the other envs they still
+// start, with a warning, and the file driver masks the tokens of the links like the log
+// driver always does.
+func New(cfg config.Notifier, env string, logger log.Logger) (contract.Notifier, func(), error) {
+	local := env == config.EnvLocal
+	if !local && (cfg.Driver == DriverLog || cfg.Driver == DriverFile) {
+		logger.Warn("the notifications are not delivered, the notifier driver is meant for the local env", map[string]interface{}{
+			"driver": cfg.Driver,
+			"env":    env,
+		})
+	}
+
+	switch cfg.Driver {
+	case DriverLog:
+		return NewLog(logger), func() {}, nil
+	case DriverFile:
+		file, err := NewFile(cfg.FilePath)
+		if err != nil {
+			return nil, func() {}, err
+		}
+		file.mask = !local
+		return file, func() {
+			_ = file.Close()
+		}, nil
+	default:
+		return nil, func() {}, fmt.Errorf("notifier driver %q is not supported", cfg.Driver)
+	}
+}
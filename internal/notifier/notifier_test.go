@@ -0,0 +1,92 @@
+package notifier
+
+import (
+	"context"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name        string
+		driver      string
+		env         string
+		shouldWarn  bool
+		shouldError bool
+	}{
+		{name: "log_local", driver: DriverLog, env: config.EnvLocal},
+		{name: "file_local", driver: DriverFile, env: config.EnvLocal},
+		{name: "log_prod", driver: DriverLog, env: "prod", shouldWarn: true},
+		{name: "file_prod", driver: DriverFile, env: "prod", shouldWarn: true},
+		{name: "unknown_driver", driver: "smtp", env: config.EnvLocal, shouldError: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			logger := log.NewMock("test")
+			cfg := config.Notifier{Driver: test.driver, FilePath: filepath.Join(t.TempDir(), "notifications.jsonl")}
+			notifier, closer, err := New(cfg, test.env, logger)
+			defer closer()
+
+			if test.shouldError {
+				assert.Error(t, err)
+				assert.Nil(t, notifier)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NotNil(t, notifier)
+			assert.Equal(t, test.shouldWarn, logger.(*log.MockLogger).Level == log.LevelWarn)
+		})
+	}
+}
+
+func TestFile_Notify(t *testing.T) {
+	body := "Open the link below.\n\nhttps://example.com/login?token=s3cr3t"
+	tests := []struct {
+		name       string
+		env        string
+		shouldMask bool
+	}{
+		{name: "local", env: config.EnvLocal},
+		{name: "prod", env: "prod", shouldMask: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "notifications.jsonl")
+			notifier, closer, err := New(config.Notifier{Driver: DriverFile, FilePath: path}, test.env, log.NewMock("test"))
+			assert.NoError(t, err)
+			assert.NoError(t, notifier.Notify(context.Background(), entity.Notification{To: "rider@example.com", Body: body}))
+			closer()
+
+			written, err := os.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, !test.shouldMask, strings.Contains(string(written), "s3cr3t"))
+		})
+	}
+}
+
+func TestLog_Notify(t *testing.T) {
+	logger := log.NewMock("test")
+	notifier := NewLog(logger)
+
+	err := notifier.Notify(context.Background(), entity.Notification{
+		To:      "rider@example.com",
+		Subject: "Your login link",
+		Body:    "Open the link below, it expires in 15m0s.\n\nhttps://example.com/login?token=s3cr3t&next=/home",
+	})
+	assert.NoError(t, err)
+
+	// the logger masks the tokens of the links as well, the driver must not rely on it
+	body := logger.(*log.MockLogger).LastItems["body"].(string)
+	assert.NotContains(t, body, "s3cr3t")
+	assert.Contains(t, body, "https://example.com/login")
+	assert.Contains(t, body, "&next=/home")
+}
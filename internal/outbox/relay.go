@@ -0,0 +1,189 @@
+package outbox
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/backoff"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/delivery/pubsub/router"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/pubsub"
+	"time"
+)
+
+// LockKey is the key of the leader election, only one relay publishes at a time so the
+// order per aggregate holds across replicas.
+const LockKey = "outbox-relay"
+
+const (
+	metricPublished = "outbox.published"
+	metricFailed    = "outbox.failed"
+	metricDead      = "outbox.dead"
+	metricDeleted   = "outbox.deleted"
+	metricPending   = "outbox.pending"
+	metricDeadTotal = "outbox.dead_total"
+	metricLag       = "outbox.lag_seconds"
+)
+
+// Relay publishes the pending outbox events, an event is only published after every
+// earlier event of the same aggregate is published or dead.
+type Relay struct {
+	cfg       config.Outbox
+	logger    log.Logger
+	metrics   metrics.Metrics
+	store     contract.OutboxStore
+	publisher pubsub.Client
+	now       func() time.Time
+}
+
+func NewRelay(cfg config.Outbox, logger log.Logger, metrics metrics.Metrics, store contract.OutboxStore, publisher pubsub.Client) *Relay {
+	return &Relay{
+		cfg:       cfg,
+		logger:    logger,
+		metrics:   metrics,
+		store:     store,
+		publisher: publisher,
+		now:       func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// Run polls the outbox until ctx is done.
+func (r *Relay) Run(ctx context.Context) {
+	poll := time.NewTicker(r.cfg.PollInterval)
+	defer poll.Stop()
+	cleanup := time.NewTicker(r.cfg.CleanupInterval)
+	defer cleanup.Stop()
+	stats := time.NewTicker(r.cfg.MetricsInterval)
+	defer stats.Stop()
+
+	r.logger.InfoWithContext(ctx, "outbox relay started")
+	for {
+		select {
+		case <-ctx.Done():
+			r.logger.InfoWithContext(ctx, "outbox relay stopped")
+			return
+		case <-poll.C:
+			if _, err := r.RelayPending(ctx); err != nil {
+				r.logger.ErrorWithContext(ctx, "outbox relay", err)
+			}
+		case <-cleanup.C:
+			if _, err := r.Cleanup(ctx); err != nil {
+				r.logger.ErrorWithContext(ctx, "outbox cleanup", err)
+			}
+		case <-stats.C:
+			if err := r.ReportLag(ctx); err != nil {
+				r.logger.ErrorWithContext(ctx, "outbox lag", err)
+			}
+		}
+	}
+}
+
+// RelayPending publishes the due events batch after batch until none is left, and returns how
+// many were published. A batch holds a single event per aggregate, the next one of the
+// aggregate comes with the following batch once this one is published.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	published := 0
+	for {
+		batch, err := r.relayBatch(ctx)
+		published += batch
+		if err != nil || batch == 0 {
+			return published, err
+		}
+	}
+}
+
+func (r *Relay) relayBatch(ctx context.Context) (int, error) {
+	events, err := r.store.DueOutboxEvents(ctx, r.now(), r.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	for _, event := range events {
+		if ctx.Err() != nil {
+			return published, ctx.Err()
+		}
+
+		if err := r.publish(ctx, event); err != nil {
+			if err := r.fail(ctx, event, err); err != nil {
+				return published, err
+			}
+			continue
+		}
+
+		if err := r.store.MarkOutboxEventPublished(ctx, event.ID, r.now()); err != nil {
+			return published, err
+		}
+		published++
+		r.metrics.Count(metricPublished, 1, metrics.Tag("event_type", event.EventType))
+	}
+
+	return published, nil
+}
+
+func (r *Relay) publish(ctx context.Context, event entity.OutboxEvent) error {
+	topicID, ok := r.cfg.Topics[event.EventType]
+	if !ok {
+		return fmt.Errorf("no topic configured for event type %q", event.EventType)
+	}
+
+	// the events are all at their first version so far
+	_, err := r.publisher.PublishMessage(ctx, topicID, event.Payload, map[string]string{
+		router.AttributeEventType:    event.EventType,
+		router.AttributeEventVersion: router.DefaultVersion,
+	})
+	return err
+}
+
+func (r *Relay) fail(ctx context.Context, event entity.OutboxEvent, cause error) error {
+	attempts := event.Attempts + 1
+	fields := map[string]interface{}{
+		"outbox_event_id": event.ID,
+		"event_type":      event.EventType,
+		"aggregate_id":    event.AggregateID,
+		"attempts":        attempts,
+		"error":           cause.Error(),
+	}
+
+	if attempts >= r.cfg.MaxAttempts {
+		r.logger.ErrorWithContext(ctx, "outbox event is dead", fields)
+		r.metrics.Count(metricDead, 1, metrics.Tag("event_type", event.EventType))
+		return r.store.MarkOutboxEventDead(ctx, event.ID, cause.Error(), r.now())
+	}
+
+	r.logger.ErrorWithContext(ctx, "outbox event publish failed", fields)
+	r.metrics.Count(metricFailed, 1, metrics.Tag("event_type", event.EventType))
+	next := r.now().Add(backoff.Exponential(r.cfg.InitialBackoff, r.cfg.MaxBackoff, attempts))
+	return r.store.MarkOutboxEventRetry(ctx, event.ID, cause.Error(), next)
+}
+
+// Cleanup deletes the events published before the retention period.
+func (r *Relay) Cleanup(ctx context.Context) (int64, error) {
+	deleted, err := r.store.DeletePublishedOutboxEvents(ctx, r.now().Add(-r.cfg.Retention))
+	if err != nil {
+		return 0, err
+	}
+	r.metrics.Count(metricDeleted, deleted)
+	return deleted, nil
+}
+
+// ReportLag reports the number of pending events and the age of the oldest one.
+func (r *Relay) ReportLag(ctx context.Context) error {
+	stats, err := r.store.OutboxStats(ctx)
+	if err != nil {
+		return err
+	}
+
+	lag := time.Duration(0)
+	if stats.OldestPendingAt != nil {
+		lag = r.now().Sub(*stats.OldestPendingAt)
+	}
+
+	r.metrics.Gauge(metricPending, float64(stats.Pending))
+	r.metrics.Gauge(metricDeadTotal, float64(stats.Dead))
+	r.metrics.Gauge(metricLag, lag.Seconds())
+	return nil
+}
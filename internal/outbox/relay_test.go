@@ -0,0 +1,255 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/pubsub/pubsubtest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	events []entity.OutboxEvent
+}
+
+func (s *memoryStore) find(id uint64) *entity.OutboxEvent {
+	for i := range s.events {
+		if s.events[i].ID == id {
+			return &s.events[i]
+		}
+	}
+	return nil
+}
+
+func (s *memoryStore) AddOutboxEvent(ctx context.Context, event entity.OutboxEvent) error {
+	event.ID = uint64(len(s.events) + 1)
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *memoryStore) DueOutboxEvents(ctx context.Context, now time.Time, limit int) ([]entity.OutboxEvent, error) {
+	due := make([]entity.OutboxEvent, 0)
+	seen := make(map[string]bool)
+	for _, event := range s.events {
+		aggregate := event.AggregateType + ":" + event.AggregateID
+		if event.PublishedAt != nil || event.DeadAt != nil || seen[aggregate] {
+			continue
+		}
+		seen[aggregate] = true
+		if !event.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, event)
+		}
+	}
+	return due, nil
+}
+
+func (s *memoryStore) MarkOutboxEventPublished(ctx context.Context, id uint64, publishedAt time.Time) error {
+	event := s.find(id)
+	event.Attempts++
+	event.PublishedAt = &publishedAt
+	return nil
+}
+
+func (s *memoryStore) MarkOutboxEventRetry(ctx context.Context, id uint64, lastError string, nextAttemptAt time.Time) error {
+	event := s.find(id)
+	event.Attempts++
+	event.LastError = lastError
+	event.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (s *memoryStore) MarkOutboxEventDead(ctx context.Context, id uint64, lastError string, deadAt time.Time) error {
+	event := s.find(id)
+	event.Attempts++
+	event.LastError = lastError
+	event.DeadAt = &deadAt
+	return nil
+}
+
+func (s *memoryStore) DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	kept := make([]entity.OutboxEvent, 0)
+	for _, event := range s.events {
+		if event.PublishedAt == nil || !event.PublishedAt.Before(publishedBefore) {
+			kept = append(kept, event)
+		}
+	}
+	deleted := int64(len(s.events) - len(kept))
+	s.events = kept
+	return deleted, nil
+}
+
+func (s *memoryStore) OutboxStats(ctx context.Context) (entity.OutboxStats, error) {
+	stats := entity.OutboxStats{}
+	for i, event := range s.events {
+		if event.DeadAt != nil {
+			stats.Dead++
+			continue
+		}
+		if event.PublishedAt == nil {
+			stats.Pending++
+			if stats.OldestPendingAt == nil {
+				stats.OldestPendingAt = &s.events[i].CreatedAt
+			}
+		}
+	}
+	return stats, nil
+}
+
+func newTestRelay(store *memoryStore, publisher *pubsubtest.Client, now time.Time) (*Relay, *metrics.Mock) {
+	cfg := config.Outbox{
+		Topics:         map[string]string{entity.EventUserCreated: "user-events"},
+		BatchSize:      10,
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     3 * time.Second,
+		Retention:      time.Hour,
+	}
+	metricsMock := metrics.NewMock()
+	relay := NewRelay(cfg, log.NewMock("outbox"), metricsMock, store, publisher)
+	relay.now = func() time.Time { return now }
+	return relay, metricsMock
+}
+
+func addEvent(t *testing.T, store *memoryStore, aggregateID string, at time.Time) {
+	event, err := entity.NewOutboxEvent(entity.AggregateUser, aggregateID, entity.EventUserCreated, map[string]string{"id": aggregateID})
+	assert.Nil(t, err)
+	event.CreatedAt = at
+	event.NextAttemptAt = at
+	assert.Nil(t, store.AddOutboxEvent(context.Background(), event))
+}
+
+func TestRelay_RelayPending(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := new(memoryStore)
+	publisher := new(pubsubtest.Client)
+	relay, metricsMock := newTestRelay(store, publisher, now)
+
+	addEvent(t, store, "1", now)
+	addEvent(t, store, "2", now)
+	addEvent(t, store, "1", now)
+
+	published, err := relay.RelayPending(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 3, published)
+	assert.Len(t, publisher.Published, 3)
+	assert.Equal(t, "user-events", publisher.Published[0].TopicID)
+	assert.Equal(t, map[string]string{"event_type": entity.EventUserCreated, "event_version": "1"}, publisher.Published[0].Attributes)
+	assert.Equal(t, int64(3), metricsMock.Counter(metricPublished))
+
+	published, err = relay.RelayPending(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 0, published)
+}
+
+func TestRelay_RelayPendingKeepsOrderPerAggregate(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := new(memoryStore)
+	publisher := new(pubsubtest.Client)
+	relay, _ := newTestRelay(store, publisher, now)
+
+	addEvent(t, store, "1", now)
+	addEvent(t, store, "1", now)
+	addEvent(t, store, "2", now)
+
+	// the first event of aggregate 1 is waiting for a retry
+	store.events[0].NextAttemptAt = now.Add(time.Minute)
+
+	published, err := relay.RelayPending(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 1, published)
+	assert.Nil(t, store.events[0].PublishedAt)
+	assert.Nil(t, store.events[1].PublishedAt)
+	assert.NotNil(t, store.events[2].PublishedAt)
+}
+
+func TestRelay_RelayPendingAcrossAggregates(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := new(memoryStore)
+	publisher := new(pubsubtest.Client)
+	relay, _ := newTestRelay(store, publisher, now)
+	relay.cfg.BatchSize = 2
+
+	// a busy aggregate doesn't hold back the events of the others
+	for i := 0; i < 5; i++ {
+		addEvent(t, store, "1", now)
+	}
+	addEvent(t, store, "2", now)
+
+	published, err := relay.relayBatch(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 2, published)
+	assert.NotNil(t, store.events[0].PublishedAt)
+	assert.NotNil(t, store.events[5].PublishedAt)
+
+	published, err = relay.RelayPending(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 4, published)
+	for i := 1; i < 5; i++ {
+		assert.Equal(t, `{"id":"1"}`, string(publisher.Published[i+1].Message))
+	}
+}
+
+func TestRelay_RelayPendingRetriesAndDies(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := new(memoryStore)
+	publisher := &pubsubtest.Client{PublishErr: errors.New("unavailable")}
+	relay, metricsMock := newTestRelay(store, publisher, now)
+
+	addEvent(t, store, "1", now)
+
+	testCases := []struct {
+		name          string
+		shouldBackoff time.Duration
+		shouldDead    bool
+	}{
+		{name: "first_attempt", shouldBackoff: time.Second},
+		{name: "second_attempt", shouldBackoff: 2 * time.Second},
+		{name: "last_attempt", shouldDead: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			relay.now = func() time.Time { return now }
+			published, err := relay.RelayPending(context.Background())
+			assert.Nil(t, err)
+			assert.Equal(t, 0, published)
+			assert.Equal(t, "unavailable", store.events[0].LastError)
+			if tc.shouldDead {
+				assert.NotNil(t, store.events[0].DeadAt)
+				return
+			}
+			assert.Equal(t, now.Add(tc.shouldBackoff), store.events[0].NextAttemptAt)
+			now = store.events[0].NextAttemptAt
+		})
+	}
+
+	assert.Equal(t, int64(2), metricsMock.Counter(metricFailed))
+	assert.Equal(t, int64(1), metricsMock.Counter(metricDead))
+}
+
+func TestRelay_CleanupAndReportLag(t *testing.T) {
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := new(memoryStore)
+	publisher := new(pubsubtest.Client)
+	relay, metricsMock := newTestRelay(store, publisher, now.Add(-2*time.Hour))
+
+	addEvent(t, store, "1", now.Add(-2*time.Hour))
+	_, err := relay.RelayPending(context.Background())
+	assert.Nil(t, err)
+	addEvent(t, store, "2", now.Add(-time.Minute))
+
+	relay.now = func() time.Time { return now }
+	deleted, err := relay.Cleanup(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	assert.Nil(t, relay.ReportLag(context.Background()))
+	assert.Equal(t, float64(1), metricsMock.Gauged(metricPending))
+	assert.Equal(t, time.Minute.Seconds(), metricsMock.Gauged(metricLag))
+}
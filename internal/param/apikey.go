@@ -0,0 +1,60 @@
+package param
+
+import (
+	"encoding/json"
+	"go-structure-demo/internal/entity"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CreateAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Owner  string   `json:"owner"`
+	Scopes []string `json:"scopes"`
+	// ExpiresAt is optional, the key doesn't expire without it
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *CreateAPIKeyRequest) BindFromChi(request *http.Request) error {
+	return json.NewDecoder(request.Body).Decode(r)
+}
+
+// CreateAPIKeyResponse is the only place the plain key is shown, it can't be read again.
+type CreateAPIKeyResponse struct {
+	Message    string        `json:"message"`
+	Key        string        `json:"key"`
+	APIKey     entity.APIKey `json:"api_key"`
+	Error      error         `json:"-"`
+	StatusCode int           `json:"-"`
+}
+
+type ListAPIKeysRequest struct{}
+
+func (r *ListAPIKeysRequest) BindFromChi(request *http.Request) error {
+	return nil
+}
+
+type ListAPIKeysResponse struct {
+	APIKeys    []entity.APIKey `json:"api_keys"`
+	Error      error           `json:"-"`
+	StatusCode int             `json:"-"`
+}
+
+type RevokeAPIKeyRequest struct {
+	ID uint `json:"-"`
+}
+
+func (r *RevokeAPIKeyRequest) BindFromChi(request *http.Request) error {
+	id, err := strconv.ParseUint(chi.URLParam(request, "id"), 10, 64)
+	r.ID = uint(id)
+	return err
+}
+
+type RevokeAPIKeyResponse struct {
+	Message    string `json:"message"`
+	Error      error  `json:"-"`
+	StatusCode int    `json:"-"`
+}
@@ -0,0 +1,37 @@
+package param
+
+import (
+	"encoding/json"
+	"go-structure-demo/internal/entity"
+	"net/http"
+)
+
+type EmailLinkRequest struct {
+	Email string `json:"email"`
+}
+
+func (r *EmailLinkRequest) BindFromChi(request *http.Request) error {
+	return json.NewDecoder(request.Body).Decode(r)
+}
+
+type EmailLinkResponse struct {
+	Message    string `json:"message"`
+	Error      error  `json:"-"`
+	StatusCode int    `json:"-"`
+}
+
+type RedeemTokenRequest struct {
+	Token string `json:"token"`
+}
+
+func (r *RedeemTokenRequest) BindFromChi(request *http.Request) error {
+	return json.NewDecoder(request.Body).Decode(r)
+}
+
+type SessionResponse struct {
+	Token      string      `json:"token"`
+	ExpiresIn  int         `json:"expires_in"`
+	User       entity.User `json:"user"`
+	Error      error       `json:"-"`
+	StatusCode int         `json:"-"`
+}
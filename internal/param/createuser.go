@@ -1,7 +1,6 @@
 package param
 
 import (
-	v1 "go-structure-demo/internal/delivery/pubsub/handler/v1"
 	"go-structure-demo/internal/entity"
 	"net/http"
 )
@@ -18,10 +17,17 @@ func (r *CreateUserRequest) BindFromChi(ctx *http.Request) error {
 	return nil
 }
 
-func (r *CreateUserRequest) BindFromPubSub(event *v1.UserCreatedEvent) error {
+func (r *CreateUserRequest) BindFromPubSub(event *UserCreatedEvent) error {
 	return nil
 }
 
+type UserCreatedEvent struct {
+	Email     string `json:"email,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+	LastName  string `json:"last_name,omitempty"`
+	Phone     string `json:"phone,omitempty"`
+}
+
 type CreateUserResponse struct {
 	Message    string      `json:"message"`
 	User       entity.User `json:"user"`
@@ -0,0 +1,58 @@
+package param
+
+import (
+	"encoding/json"
+	"go-structure-demo/internal/log"
+	"net/http"
+	"time"
+)
+
+type GetLogLevelRequest struct{}
+
+func (r *GetLogLevelRequest) BindFromChi(request *http.Request) error {
+	return nil
+}
+
+// LogLevel is the live level, RevertAt and RevertTo are set while a revert is pending.
+type LogLevel struct {
+	Level    log.Level  `json:"level"`
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+	RevertTo log.Level  `json:"revert_to,omitempty"`
+}
+
+type GetLogLevelResponse struct {
+	LogLevel
+	Error      error `json:"-"`
+	StatusCode int   `json:"-"`
+}
+
+// SetLogLevelRequest takes the revert_after duration like "15m", the level stays until it is
+// changed again without it.
+type SetLogLevelRequest struct {
+	Level       string        `json:"level"`
+	RevertAfter time.Duration `json:"-"`
+}
+
+func (r *SetLogLevelRequest) BindFromChi(request *http.Request) error {
+	var body struct {
+		Level       string `json:"level"`
+		RevertAfter string `json:"revert_after"`
+	}
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil {
+		return err
+	}
+	r.Level = body.Level
+	if body.RevertAfter == "" {
+		return nil
+	}
+	revertAfter, err := time.ParseDuration(body.RevertAfter)
+	r.RevertAfter = revertAfter
+	return err
+}
+
+type SetLogLevelResponse struct {
+	Message string `json:"message"`
+	LogLevel
+	Error      error `json:"-"`
+	StatusCode int   `json:"-"`
+}
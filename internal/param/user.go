@@ -0,0 +1,88 @@
+package param
+
+import (
+	"encoding/json"
+	"go-structure-demo/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type GetUserRequest struct {
+	ID uint `json:"-"`
+}
+
+func (r *GetUserRequest) BindFromChi(request *http.Request) error {
+	id, err := userIDFromChi(request)
+	r.ID = id
+	return err
+}
+
+type GetUserResponse struct {
+	User       entity.User `json:"user"`
+	Error      error       `json:"-"`
+	StatusCode int         `json:"-"`
+}
+
+type UpdateUserRequest struct {
+	ID        uint    `json:"-"`
+	Email     string  `json:"email"`
+	FirstName string  `json:"first_name"`
+	LastName  string  `json:"last_name"`
+	Gender    *string `json:"gender"`
+}
+
+func (r *UpdateUserRequest) BindFromChi(request *http.Request) error {
+	if err := json.NewDecoder(request.Body).Decode(r); err != nil {
+		return err
+	}
+	id, err := userIDFromChi(request)
+	r.ID = id
+	return err
+}
+
+type UpdateUserResponse struct {
+	Message    string      `json:"message"`
+	User       entity.User `json:"user"`
+	Error      error       `json:"-"`
+	StatusCode int         `json:"-"`
+}
+
+type DeleteUserRequest struct {
+	ID uint `json:"-"`
+}
+
+func (r *DeleteUserRequest) BindFromChi(request *http.Request) error {
+	id, err := userIDFromChi(request)
+	r.ID = id
+	return err
+}
+
+type DeleteUserResponse struct {
+	Message    string `json:"message"`
+	Error      error  `json:"-"`
+	StatusCode int    `json:"-"`
+}
+
+type SyncRiderRequest struct {
+	ID uint `json:"-"`
+}
+
+func (r *SyncRiderRequest) BindFromChi(request *http.Request) error {
+	id, err := userIDFromChi(request)
+	r.ID = id
+	return err
+}
+
+type SyncRiderResponse struct {
+	Message    string      `json:"message"`
+	User       entity.User `json:"user"`
+	Error      error       `json:"-"`
+	StatusCode int         `json:"-"`
+}
+
+func userIDFromChi(request *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(chi.URLParam(request, "id"), 10, 64)
+	return uint(id), err
+}
@@ -0,0 +1,136 @@
+package param
+
+import (
+	"encoding/json"
+	"go-structure-demo/internal/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CreateWebhookRequest struct {
+	URL string `json:"url"`
+	// Secret is generated when empty
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+	// Active defaults to true
+	Active *bool `json:"active"`
+}
+
+func (r *CreateWebhookRequest) BindFromChi(request *http.Request) error {
+	return json.NewDecoder(request.Body).Decode(r)
+}
+
+// CreateWebhookResponse is the only place the secret is shown, it can't be read again.
+type CreateWebhookResponse struct {
+	Message    string         `json:"message"`
+	Secret     string         `json:"secret"`
+	Webhook    entity.Webhook `json:"webhook"`
+	Error      error          `json:"-"`
+	StatusCode int            `json:"-"`
+}
+
+type ListWebhooksRequest struct{}
+
+func (r *ListWebhooksRequest) BindFromChi(request *http.Request) error {
+	return nil
+}
+
+type ListWebhooksResponse struct {
+	Webhooks   []entity.Webhook `json:"webhooks"`
+	Error      error            `json:"-"`
+	StatusCode int              `json:"-"`
+}
+
+// UpdateWebhookRequest replaces the url, the events and the active flag, the secret can't
+// be changed.
+type UpdateWebhookRequest struct {
+	ID     uint     `json:"-"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active bool     `json:"active"`
+}
+
+func (r *UpdateWebhookRequest) BindFromChi(request *http.Request) error {
+	if err := json.NewDecoder(request.Body).Decode(r); err != nil {
+		return err
+	}
+	id, err := webhookIDFromChi(request)
+	r.ID = id
+	return err
+}
+
+type UpdateWebhookResponse struct {
+	Message    string         `json:"message"`
+	Webhook    entity.Webhook `json:"webhook"`
+	Error      error          `json:"-"`
+	StatusCode int            `json:"-"`
+}
+
+type DeleteWebhookRequest struct {
+	ID uint `json:"-"`
+}
+
+func (r *DeleteWebhookRequest) BindFromChi(request *http.Request) error {
+	id, err := webhookIDFromChi(request)
+	r.ID = id
+	return err
+}
+
+type DeleteWebhookResponse struct {
+	Message    string `json:"message"`
+	Error      error  `json:"-"`
+	StatusCode int    `json:"-"`
+}
+
+// ListWebhookDeliveriesRequest reads the limit from the query, the controller defaults it.
+type ListWebhookDeliveriesRequest struct {
+	WebhookID uint `json:"-"`
+	Limit     int  `json:"-"`
+}
+
+func (r *ListWebhookDeliveriesRequest) BindFromChi(request *http.Request) error {
+	id, err := webhookIDFromChi(request)
+	if err != nil {
+		return err
+	}
+	r.WebhookID = id
+	if limit := request.URL.Query().Get("limit"); limit != "" {
+		r.Limit, err = strconv.Atoi(limit)
+	}
+	return err
+}
+
+type ListWebhookDeliveriesResponse struct {
+	Deliveries []entity.WebhookDelivery `json:"deliveries"`
+	Error      error                    `json:"-"`
+	StatusCode int                      `json:"-"`
+}
+
+type RedeliverWebhookRequest struct {
+	WebhookID  uint   `json:"-"`
+	DeliveryID uint64 `json:"-"`
+}
+
+func (r *RedeliverWebhookRequest) BindFromChi(request *http.Request) error {
+	id, err := webhookIDFromChi(request)
+	if err != nil {
+		return err
+	}
+	r.WebhookID = id
+	r.DeliveryID, err = strconv.ParseUint(chi.URLParam(request, "delivery_id"), 10, 64)
+	return err
+}
+
+type RedeliverWebhookResponse struct {
+	Message    string                 `json:"message"`
+	Delivery   entity.WebhookDelivery `json:"delivery"`
+	Error      error                  `json:"-"`
+	StatusCode int                    `json:"-"`
+}
+
+func webhookIDFromChi(request *http.Request) (uint, error) {
+	id, err := strconv.ParseUint(chi.URLParam(request, "id"), 10, 64)
+	return uint(id), err
+}
@@ -0,0 +1,108 @@
+package policy
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+)
+
+// the actions are scopes as well, a principal holding one is allowed to do it
+const (
+	ActionUserCreate = "user:create"
+	ActionUserRead   = "user:read"
+	ActionUserUpdate = "user:update"
+	ActionUserDelete = "user:delete"
+	// ActionUserSyncRider triggers the rider profile sync of a user again
+	ActionUserSyncRider = "user:sync_rider"
+
+	ActionAPIKeyCreate = "api_key:create"
+	ActionAPIKeyRead   = "api_key:read"
+	ActionAPIKeyRevoke = "api_key:revoke"
+
+	ActionWebhookCreate = "webhook:create"
+	ActionWebhookRead   = "webhook:read"
+	ActionWebhookUpdate = "webhook:update"
+	ActionWebhookDelete = "webhook:delete"
+	// ActionWebhookRedeliver sends a delivery of a webhook again
+	ActionWebhookRedeliver = "webhook:redeliver"
+
+	ActionLogLevelRead   = "log_level:read"
+	ActionLogLevelUpdate = "log_level:update"
+)
+
+// Rule allows the action when it returns true, the resource is the target of the action
+// and nil when there is none yet, like on creation.
+type Rule func(principal auth.Principal, resource interface{}) bool
+
+// rules are every permission of the service, admins and the principals holding the action as
+// a scope are allowed on top of them.
+var rules = map[string][]Rule{
+	ActionUserCreate:    {isSystem},
+	ActionUserRead:      {isSystem, isSelf},
+	ActionUserUpdate:    {isSelf},
+	ActionUserDelete:    {},
+	ActionUserSyncRider: {},
+	// the system manages the keys from the cli
+	ActionAPIKeyCreate: {isSystem},
+	ActionAPIKeyRead:   {isSystem},
+	ActionAPIKeyRevoke: {isSystem},
+	// the webhooks are managed by the admins
+	ActionWebhookCreate:    {},
+	ActionWebhookRead:      {},
+	ActionWebhookUpdate:    {},
+	ActionWebhookDelete:    {},
+	ActionWebhookRedeliver: {},
+	ActionLogLevelRead:     {},
+	ActionLogLevelUpdate:   {},
+}
+
+// IsAction tells if the scope is one of the actions, the other scopes allow nothing.
+func IsAction(scope string) bool {
+	_, ok := rules[scope]
+	return ok
+}
+
+// CanGrant tells if the principal may hand the scope to another one, like to an api key.
+// The admins and the system grant any, the others only the scopes they hold.
+func CanGrant(principal auth.Principal, scope string) bool {
+	return principal.Role == entity.RoleAdmin || principal.Role == auth.RoleSystem || principal.HasScope(scope)
+}
+
+var _ contract.Authorizer = (*Policy)(nil)
+
+type Policy struct {
+	rules map[string][]Rule
+}
+
+func New() *Policy {
+	return &Policy{rules: rules}
+}
+
+// Authorize checks the action of the principal in ctx, it returns an Unauthorized app error
+// without a principal and a Forbidden one when no rule allows it.
+func (p *Policy) Authorize(ctx context.Context, action string, resource interface{}) error {
+	principal, ok := auth.PrincipalFrom(ctx)
+	if !ok {
+		return apperror.Unauthorized("authentication required")
+	}
+	if principal.Role == entity.RoleAdmin || principal.HasScope(action) {
+		return nil
+	}
+	for _, rule := range p.rules[action] {
+		if rule(principal, resource) {
+			return nil
+		}
+	}
+	return apperror.Forbidden("not allowed to " + action)
+}
+
+func isSystem(principal auth.Principal, resource interface{}) bool {
+	return principal.Role == auth.RoleSystem
+}
+
+func isSelf(principal auth.Principal, resource interface{}) bool {
+	user, ok := resource.(entity.User)
+	return ok && principal.UserID != 0 && principal.UserID == user.ID
+}
@@ -0,0 +1,78 @@
+package policy
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/entity"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_Authorize(t *testing.T) {
+	admin := auth.NewUserPrincipal(1, entity.RoleAdmin, nil, auth.MethodSession)
+	user := auth.NewUserPrincipal(42, entity.RoleUser, nil, auth.MethodSession)
+	system := auth.SystemPrincipal()
+	service := auth.Principal{Subject: "billing", Scopes: []string{ActionUserCreate}, Method: auth.MethodJWT}
+	self := entity.User{ID: 42}
+	other := entity.User{ID: 7}
+
+	testCases := []struct {
+		name       string
+		principal  *auth.Principal
+		action     string
+		resource   interface{}
+		shouldKind apperror.Kind
+	}{
+		{name: "anonymous", action: ActionUserRead, resource: self, shouldKind: apperror.KindUnauthorized},
+		{name: "admin_deletes", principal: &admin, action: ActionUserDelete, resource: other},
+		{name: "user_reads_self", principal: &user, action: ActionUserRead, resource: self},
+		{name: "user_updates_self", principal: &user, action: ActionUserUpdate, resource: self},
+		{name: "user_reads_other", principal: &user, action: ActionUserRead, resource: other, shouldKind: apperror.KindForbidden},
+		{name: "user_updates_other", principal: &user, action: ActionUserUpdate, resource: other, shouldKind: apperror.KindForbidden},
+		{name: "user_deletes_self", principal: &user, action: ActionUserDelete, resource: self, shouldKind: apperror.KindForbidden},
+		{name: "user_creates", principal: &user, action: ActionUserCreate, shouldKind: apperror.KindForbidden},
+		{name: "system_creates", principal: &system, action: ActionUserCreate},
+		{name: "system_deletes", principal: &system, action: ActionUserDelete, resource: other, shouldKind: apperror.KindForbidden},
+		{name: "scope_allows", principal: &service, action: ActionUserCreate},
+		{name: "user_syncs_own_rider", principal: &user, action: ActionUserSyncRider, resource: self, shouldKind: apperror.KindForbidden},
+		{name: "user_creates_api_key", principal: &user, action: ActionAPIKeyCreate, shouldKind: apperror.KindForbidden},
+		{name: "admin_revokes_api_key", principal: &admin, action: ActionAPIKeyRevoke},
+		{name: "system_lists_api_keys", principal: &system, action: ActionAPIKeyRead},
+		{name: "user_reads_webhooks", principal: &user, action: ActionWebhookRead, shouldKind: apperror.KindForbidden},
+		{name: "system_creates_webhook", principal: &system, action: ActionWebhookCreate, shouldKind: apperror.KindForbidden},
+		{name: "admin_redelivers_webhook", principal: &admin, action: ActionWebhookRedeliver},
+		{name: "user_sets_log_level", principal: &user, action: ActionLogLevelUpdate, shouldKind: apperror.KindForbidden},
+		{name: "admin_sets_log_level", principal: &admin, action: ActionLogLevelUpdate},
+		{name: "scope_is_per_action", principal: &service, action: ActionUserRead, resource: other, shouldKind: apperror.KindForbidden},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.principal != nil {
+				ctx = auth.WithPrincipal(ctx, *tc.principal)
+			}
+
+			err := New().Authorize(ctx, tc.action, tc.resource)
+			if tc.shouldKind == "" {
+				assert.Nil(t, err)
+				return
+			}
+			assert.Equal(t, tc.shouldKind, apperror.KindOf(err))
+		})
+	}
+}
+
+func TestCanGrant(t *testing.T) {
+	admin := auth.NewUserPrincipal(1, entity.RoleAdmin, nil, auth.MethodSession)
+	service := auth.NewAPIKeyPrincipal(entity.APIKey{ID: 3, Scopes: []string{ActionAPIKeyCreate, ActionUserRead}})
+
+	assert.True(t, CanGrant(admin, ActionUserDelete))
+	assert.True(t, CanGrant(auth.SystemPrincipal(), ActionUserDelete))
+	assert.True(t, CanGrant(service, ActionUserRead))
+	assert.False(t, CanGrant(service, ActionUserDelete))
+	assert.True(t, IsAction(ActionUserDelete))
+	assert.False(t, IsAction("user:*"))
+}
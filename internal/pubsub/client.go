@@ -2,6 +2,7 @@ package pubsub
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	gcloudpubsub "cloud.google.com/go/pubsub"
@@ -13,49 +14,172 @@ import (
 var _ Client = (*GCPClient)(nil)
 
 // MessageHandler is an alias for a function that handles an incoming message
-type MessageHandler func(context.Context, []byte) (bool, error)
+type MessageHandler func(context.Context, *Message) (bool, error)
+
+// Message is an incoming or outgoing message, detached from the underlying client, only Data
+// and Attributes are published
+type Message struct {
+	ID              string
+	Data            []byte
+	Attributes      map[string]string
+	PublishTime     time.Time
+	DeliveryAttempt *int
+}
+
+// PublishResult is the outcome of a single message of a batch
+type PublishResult struct {
+	ID  string
+	Err error
+}
 
 type Client interface {
-	PublishMessage(ctx context.Context, topicID string, message []byte) (id string, err error)
-	Consume(ctx context.Context, subID string, fn MessageHandler)
+	PublishMessage(ctx context.Context, topicID string, message []byte, attributes map[string]string) (id string, err error)
+	PublishBatch(ctx context.Context, topicID string, messages []Message) []PublishResult
+	Consume(ctx context.Context, subID string, settings ReceiveSettings, fn MessageHandler) (drained bool, err error)
+}
+
+// ReceiveSettings controls the flow of a single subscription, zero values fall back to the
+// library defaults.
+type ReceiveSettings struct {
+	MaxOutstandingMessages int
+	MaxOutstandingBytes    int
+	NumGoroutines          int
+	MaxExtension           time.Duration
+	Synchronous            bool
+	// DrainTimeout is how long the in-flight messages may take after ctx is cancelled.
+	DrainTimeout time.Duration
+}
+
+// PublishSettings are the batching thresholds of the topics, zero values fall back to the
+// library defaults.
+type PublishSettings struct {
+	CountThreshold int
+	ByteThreshold  int
+	DelayThreshold time.Duration
+	Timeout        time.Duration
 }
 
 type GCPClient struct {
-	logger         log.Logger
-	gcpClient      *gcloudpubsub.Client
-	projectID      string
-	tracingEnabled bool
+	logger          log.Logger
+	gcpClient       *gcloudpubsub.Client
+	projectID       string
+	tracingEnabled  bool
+	publishSettings PublishSettings
+	topicsMu        sync.Mutex
+	topics          map[string]*gcloudpubsub.Topic
+	closeOnce       sync.Once
 }
 
-func New(logger log.Logger, ctx context.Context, projectID string, opts ...option.ClientOption) (*GCPClient, error) {
+func New(logger log.Logger, ctx context.Context, projectID string, publishSettings PublishSettings, opts ...option.ClientOption) (*GCPClient, error) {
 	client, err := gcloudpubsub.NewClient(ctx, projectID, opts...)
 
+	c := &GCPClient{
+		logger:          logger,
+		gcpClient:       client,
+		projectID:       projectID,
+		tracingEnabled:  true,
+		publishSettings: publishSettings,
+		topics:          make(map[string]*gcloudpubsub.Topic),
+	}
+	if err != nil {
+		return c, err
+	}
+
 	go func(ctx context.Context) {
-		defer func() { _ = client.Close() }()
+		defer c.Close()
 		<-ctx.Done()
 	}(ctx)
 
-	return &GCPClient{logger: logger, gcpClient: client, projectID: projectID, tracingEnabled: true}, err
+	return c, nil
+}
+
+// Close flushes the messages waiting in the topics batches and closes the client.
+func (c *GCPClient) Close() {
+	c.closeOnce.Do(func() {
+		c.topicsMu.Lock()
+		defer c.topicsMu.Unlock()
+		for _, topic := range c.topics {
+			topic.Stop()
+		}
+		c.topics = make(map[string]*gcloudpubsub.Topic)
+		if c.gcpClient != nil {
+			_ = c.gcpClient.Close()
+		}
+	})
+}
+
+// topic returns the cached handle of the topic, its batches are shared by all publishers.
+func (c *GCPClient) topic(topicID string) *gcloudpubsub.Topic {
+	c.topicsMu.Lock()
+	defer c.topicsMu.Unlock()
+
+	topic, ok := c.topics[topicID]
+	if !ok {
+		topic = c.gcpClient.Topic(topicID)
+		if c.publishSettings.CountThreshold > 0 {
+			topic.PublishSettings.CountThreshold = c.publishSettings.CountThreshold
+		}
+		if c.publishSettings.ByteThreshold > 0 {
+			topic.PublishSettings.ByteThreshold = c.publishSettings.ByteThreshold
+		}
+		if c.publishSettings.DelayThreshold > 0 {
+			topic.PublishSettings.DelayThreshold = c.publishSettings.DelayThreshold
+		}
+		if c.publishSettings.Timeout > 0 {
+			topic.PublishSettings.Timeout = c.publishSettings.Timeout
+		}
+		c.topics[topicID] = topic
+	}
+	return topic
 }
 
 func (c *GCPClient) EnableTracing(enable bool) {
 	c.tracingEnabled = enable
 }
 
-func (c *GCPClient) PublishMessage(ctx context.Context, topicID string, message []byte) (string, error) {
-	topic := c.gcpClient.Topic(topicID)
+func (c *GCPClient) PublishMessage(ctx context.Context, topicID string, message []byte, attributes map[string]string) (string, error) {
+	return c.publish(ctx, c.topic(topicID), &gcloudpubsub.Message{Data: message, Attributes: attributes}).Get(ctx)
+}
 
-	if c.tracingEnabled {
-		id, err := pubsubtrace.Publish(ctx, topic, &gcloudpubsub.Message{Data: message}).Get(ctx)
-		return id, err
+// PublishBatch hands every message to the topic batcher before waiting for any of them, the
+// results are in the order of the messages.
+func (c *GCPClient) PublishBatch(ctx context.Context, topicID string, messages []Message) []PublishResult {
+	topic := c.topic(topicID)
+
+	pending := make([]publishResult, len(messages))
+	for i, message := range messages {
+		pending[i] = c.publish(ctx, topic, &gcloudpubsub.Message{Data: message.Data, Attributes: message.Attributes})
 	}
 
-	id, err := topic.Publish(ctx, &gcloudpubsub.Message{Data: message}).Get(ctx)
-	return id, err
+	results := make([]PublishResult, len(messages))
+	for i, result := range pending {
+		results[i].ID, results[i].Err = result.Get(ctx)
+	}
+	return results
+}
+
+// publishResult is implemented by both the traced and the plain publish results
+type publishResult interface {
+	Get(ctx context.Context) (string, error)
 }
 
-func (c *GCPClient) Consume(ctx context.Context, subscriptionID string, fn MessageHandler) {
+func (c *GCPClient) publish(ctx context.Context, topic *gcloudpubsub.Topic, msg *gcloudpubsub.Message) publishResult {
+	if c.tracingEnabled {
+		return pubsubtrace.Publish(ctx, topic, msg)
+	}
+	return topic.Publish(ctx, msg)
+}
+
+// Consume receives the messages until ctx is cancelled, then waits up to DrainTimeout for
+// the in-flight handlers and reports whether they all finished.
+func (c *GCPClient) Consume(ctx context.Context, subscriptionID string, settings ReceiveSettings, fn MessageHandler) (bool, error) {
+	// the handlers keep running on their own context while draining, it is only
+	// cancelled when the drain times out
+	drainCtx, drainCancel := context.WithCancel(context.Background())
+	defer drainCancel()
+
 	handler := func(ctx context.Context, msg *gcloudpubsub.Message) {
+		ctx = drainContext{Context: drainCtx, values: ctx}
 		defer func() {
 			if err := recover(); err != nil {
 				c.logger.ErrorWithContext(ctx, "panic recovered", err)
@@ -63,7 +187,13 @@ func (c *GCPClient) Consume(ctx context.Context, subscriptionID string, fn Messa
 			}
 		}()
 		start := time.Now()
-		ack, err := fn(ctx, msg.Data)
+		ack, err := fn(ctx, &Message{
+			ID:              msg.ID,
+			Data:            msg.Data,
+			Attributes:      msg.Attributes,
+			PublishTime:     msg.PublishTime,
+			DeliveryAttempt: msg.DeliveryAttempt,
+		})
 		if err != nil {
 			c.logger.ErrorWithContext(ctx, "pubsub consumer handler error", map[string]interface{}{
 				"elapsed":         time.Since(start).Milliseconds(),
@@ -81,10 +211,37 @@ func (c *GCPClient) Consume(ctx context.Context, subscriptionID string, fn Messa
 	}
 
 	sub := c.gcpClient.Subscription(subscriptionID)
+	sub.ReceiveSettings.MaxOutstandingMessages = settings.MaxOutstandingMessages
+	sub.ReceiveSettings.MaxOutstandingBytes = settings.MaxOutstandingBytes
+	sub.ReceiveSettings.NumGoroutines = settings.NumGoroutines
+	sub.ReceiveSettings.MaxExtension = settings.MaxExtension
+	sub.ReceiveSettings.Synchronous = settings.Synchronous
 	if c.tracingEnabled {
 		handler = pubsubtrace.WrapReceiveHandler(sub, handler)
 	}
-	err := sub.Receive(ctx, handler)
+
+	received := make(chan error, 1)
+	go func() {
+		received <- sub.Receive(ctx, handler)
+	}()
+
+	select {
+	case err := <-received:
+		return true, c.receiveError(ctx, subscriptionID, err)
+	case <-ctx.Done():
+	}
+
+	timer := time.NewTimer(settings.DrainTimeout)
+	defer timer.Stop()
+	select {
+	case err := <-received:
+		return true, c.receiveError(ctx, subscriptionID, err)
+	case <-timer.C:
+		return false, nil
+	}
+}
+
+func (c *GCPClient) receiveError(ctx context.Context, subscriptionID string, err error) error {
 	if err != nil {
 		c.logger.ErrorWithContext(ctx, "pubsub consumer receiving error", map[string]interface{}{
 			"project_id":      c.projectID,
@@ -92,4 +249,16 @@ func (c *GCPClient) Consume(ctx context.Context, subscriptionID string, fn Messa
 			"error":           err.Error(),
 		})
 	}
+	return err
+}
+
+// drainContext carries the values of the receive context, like the tracing span, while its
+// cancellation comes from the drain context.
+type drainContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c drainContext) Value(key interface{}) interface{} {
+	return c.values.Value(key)
 }
@@ -0,0 +1,122 @@
+package pubsub
+
+import (
+	"context"
+	"go-structure-demo/internal/log"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/pubsub/pstest"
+	"github.com/stretchr/testify/assert"
+)
+
+const testProjectID = "test-project"
+
+// newTestClient returns a client of a fake server with the topic and subscription test.
+func newTestClient(t *testing.T) (*GCPClient, *pstest.Server) {
+	srv := pstest.NewServer()
+	t.Cleanup(func() { _ = srv.Close() })
+
+	opts, err := EmulatorOptions(srv.Addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	c, err := New(log.NewMock("pubsub"), ctx, testProjectID, PublishSettings{}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.EnableTracing(false)
+	t.Cleanup(c.Close)
+
+	if _, err := c.EnsureTopic(ctx, "test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.EnsureSubscription(ctx, SubscriptionConfig{ID: "test", TopicID: "test"}); err != nil {
+		t.Fatal(err)
+	}
+	return c, srv
+}
+
+type consumed struct {
+	drained bool
+	err     error
+}
+
+func TestGCPClient_Consume_Drained(t *testing.T) {
+	c, srv := newTestClient(t)
+	id := srv.Publish("projects/"+testProjectID+"/topics/test", []byte("hello"), nil)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan consumed, 1)
+	go func() {
+		drained, err := c.Consume(ctx, "test", ReceiveSettings{DrainTimeout: 5 * time.Second}, func(ctx context.Context, msg *Message) (bool, error) {
+			close(started)
+			<-release
+			// the handler keeps its context while draining
+			return ctx.Err() == nil, nil
+		})
+		done <- consumed{drained: drained, err: err}
+	}()
+
+	<-started
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("consume returned before the in-flight handler")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(release)
+
+	result := <-done
+	assert.True(t, result.drained)
+	assert.Nil(t, result.err)
+	assert.Eventually(t, func() bool {
+		return srv.Message(id).Acks == 1
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestGCPClient_Consume_DrainTimeout(t *testing.T) {
+	c, srv := newTestClient(t)
+	srv.Publish("projects/"+testProjectID+"/topics/test", []byte("hello"), nil)
+
+	started := make(chan struct{})
+	handlerCtxDone := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan consumed, 1)
+	go func() {
+		drained, err := c.Consume(ctx, "test", ReceiveSettings{DrainTimeout: 50 * time.Millisecond}, func(ctx context.Context, msg *Message) (bool, error) {
+			close(started)
+			<-ctx.Done()
+			close(handlerCtxDone)
+			return false, ctx.Err()
+		})
+		done <- consumed{drained: drained, err: err}
+	}()
+
+	<-started
+	cancel()
+	result := <-done
+	assert.False(t, result.drained)
+	assert.Nil(t, result.err)
+
+	// the handlers still running are cancelled once the drain times out
+	select {
+	case <-handlerCtxDone:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the handler context was not cancelled")
+	}
+}
+
+func TestGCPClient_Consume_ReceiveError(t *testing.T) {
+	c, _ := newTestClient(t)
+
+	drained, err := c.Consume(context.Background(), "missing", ReceiveSettings{}, func(ctx context.Context, msg *Message) (bool, error) {
+		return true, nil
+	})
+	assert.True(t, drained)
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,16 @@
+package pubsub
+
+import (
+	"google.golang.org/api/option"
+	"google.golang.org/grpc"
+)
+
+// EmulatorOptions connects the client to the emulator at host without authentication, the
+// PUBSUB_EMULATOR_HOST environment variable does the same without any option.
+func EmulatorOptions(host string) ([]option.ClientOption, error) {
+	conn, err := grpc.Dial(host, grpc.WithInsecure())
+	if err != nil {
+		return nil, err
+	}
+	return []option.ClientOption{option.WithGRPCConn(conn)}, nil
+}
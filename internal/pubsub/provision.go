@@ -0,0 +1,101 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	gcloudpubsub "cloud.google.com/go/pubsub"
+)
+
+// SubscriptionConfig is the desired state of a subscription, zero values are left to the
+// server defaults.
+type SubscriptionConfig struct {
+	ID                  string
+	TopicID             string
+	AckDeadline         time.Duration
+	RetryMinimumBackoff time.Duration
+	RetryMaximumBackoff time.Duration
+	// DeadLetterTopicID is a topic of the same project
+	DeadLetterTopicID   string
+	MaxDeliveryAttempts int
+}
+
+// EnsureTopic creates the topic unless it exists.
+func (c *GCPClient) EnsureTopic(ctx context.Context, topicID string) (bool, error) {
+	exists, err := c.gcpClient.Topic(topicID).Exists(ctx)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		return false, nil
+	}
+
+	_, err = c.gcpClient.CreateTopic(ctx, topicID)
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// EnsureSubscription creates the subscription unless it exists, an existing one gets its
+// ack deadline, retry and dead letter policies updated to the config.
+func (c *GCPClient) EnsureSubscription(ctx context.Context, cfg SubscriptionConfig) (bool, error) {
+	sub := c.gcpClient.Subscription(cfg.ID)
+	exists, err := sub.Exists(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if exists {
+		current, err := sub.Config(ctx)
+		if err != nil {
+			return false, err
+		}
+		if current.Topic.ID() != cfg.TopicID {
+			return false, fmt.Errorf("subscription %s is attached to topic %s instead of %s", cfg.ID, current.Topic.ID(), cfg.TopicID)
+		}
+
+		update := gcloudpubsub.SubscriptionConfigToUpdate{
+			AckDeadline:      cfg.AckDeadline,
+			RetryPolicy:      c.retryPolicy(cfg),
+			DeadLetterPolicy: c.deadLetterPolicy(cfg),
+		}
+		if update.AckDeadline == 0 && update.RetryPolicy == nil && update.DeadLetterPolicy == nil {
+			return false, nil
+		}
+		_, err = sub.Update(ctx, update)
+		return false, err
+	}
+
+	_, err = c.gcpClient.CreateSubscription(ctx, cfg.ID, gcloudpubsub.SubscriptionConfig{
+		Topic:            c.gcpClient.Topic(cfg.TopicID),
+		AckDeadline:      cfg.AckDeadline,
+		RetryPolicy:      c.retryPolicy(cfg),
+		DeadLetterPolicy: c.deadLetterPolicy(cfg),
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (c *GCPClient) retryPolicy(cfg SubscriptionConfig) *gcloudpubsub.RetryPolicy {
+	if cfg.RetryMinimumBackoff == 0 && cfg.RetryMaximumBackoff == 0 {
+		return nil
+	}
+	return &gcloudpubsub.RetryPolicy{
+		MinimumBackoff: cfg.RetryMinimumBackoff,
+		MaximumBackoff: cfg.RetryMaximumBackoff,
+	}
+}
+
+func (c *GCPClient) deadLetterPolicy(cfg SubscriptionConfig) *gcloudpubsub.DeadLetterPolicy {
+	if cfg.DeadLetterTopicID == "" {
+		return nil
+	}
+	return &gcloudpubsub.DeadLetterPolicy{
+		DeadLetterTopic:     fmt.Sprintf("projects/%s/topics/%s", c.projectID, cfg.DeadLetterTopicID),
+		MaxDeliveryAttempts: cfg.MaxDeliveryAttempts,
+	}
+}
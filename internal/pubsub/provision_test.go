@@ -0,0 +1,83 @@
+package pubsub
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCPClient_EnsureTopic(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+
+	created, err := c.EnsureTopic(ctx, "user")
+	assert.Nil(t, err)
+	assert.True(t, created)
+
+	// a second run finds the topic
+	created, err = c.EnsureTopic(ctx, "user")
+	assert.Nil(t, err)
+	assert.False(t, created)
+}
+
+func TestGCPClient_EnsureSubscription(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+	for _, topicID := range []string{"user", "user-dead-letter"} {
+		if _, err := c.EnsureTopic(ctx, topicID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := SubscriptionConfig{
+		ID:                  "user-sub",
+		TopicID:             "user",
+		AckDeadline:         20 * time.Second,
+		RetryMinimumBackoff: 10 * time.Second,
+		RetryMaximumBackoff: time.Minute,
+		DeadLetterTopicID:   "user-dead-letter",
+		MaxDeliveryAttempts: 5,
+	}
+	created, err := c.EnsureSubscription(ctx, cfg)
+	assert.Nil(t, err)
+	assert.True(t, created)
+
+	current, err := c.gcpClient.Subscription("user-sub").Config(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, "user", current.Topic.ID())
+	assert.Equal(t, 20*time.Second, current.AckDeadline)
+	if assert.NotNil(t, current.RetryPolicy) {
+		assert.Equal(t, time.Minute, current.RetryPolicy.MaximumBackoff)
+	}
+	if assert.NotNil(t, current.DeadLetterPolicy) {
+		assert.Equal(t, "projects/"+testProjectID+"/topics/user-dead-letter", current.DeadLetterPolicy.DeadLetterTopic)
+		assert.Equal(t, 5, current.DeadLetterPolicy.MaxDeliveryAttempts)
+	}
+
+	// a second run updates the existing subscription, the fake server only updates the
+	// ack deadline
+	created, err = c.EnsureSubscription(ctx, SubscriptionConfig{ID: "user-sub", TopicID: "user", AckDeadline: 30 * time.Second})
+	assert.Nil(t, err)
+	assert.False(t, created)
+	current, err = c.gcpClient.Subscription("user-sub").Config(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, current.AckDeadline)
+
+	// nothing to update
+	created, err = c.EnsureSubscription(ctx, SubscriptionConfig{ID: "user-sub", TopicID: "user"})
+	assert.Nil(t, err)
+	assert.False(t, created)
+}
+
+func TestGCPClient_EnsureSubscription_OtherTopic(t *testing.T) {
+	ctx := context.Background()
+	c, _ := newTestClient(t)
+	if _, err := c.EnsureTopic(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := c.EnsureSubscription(ctx, SubscriptionConfig{ID: "test", TopicID: "user"})
+	assert.EqualError(t, err, "subscription test is attached to topic test instead of user")
+}
@@ -3,22 +3,57 @@ package pubsubtest
 import (
 	"context"
 	"go-structure-demo/internal/pubsub"
+	"sync"
 )
 
 var _ pubsub.Client = (*Client)(nil)
 
 type Client struct {
+	mu         sync.Mutex
 	PublishErr error
 	ConsumeErr error
+	Published  []Published
+}
+
+type Published struct {
+	TopicID    string
+	Message    []byte
+	Attributes map[string]string
 }
 
 func NewMock() pubsub.Client {
 	return new(Client)
 }
 
-func (c *Client) PublishMessage(ctx context.Context, topicID string, message []byte) (id string, err error) {
-	return "mockEventID", c.PublishErr
+func (c *Client) PublishMessage(ctx context.Context, topicID string, message []byte, attributes map[string]string) (id string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.PublishErr != nil {
+		return "", c.PublishErr
+	}
+	c.Published = append(c.Published, Published{TopicID: topicID, Message: message, Attributes: attributes})
+	return "mockEventID", nil
+}
+
+func (c *Client) PublishBatch(ctx context.Context, topicID string, messages []pubsub.Message) []pubsub.PublishResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	results := make([]pubsub.PublishResult, len(messages))
+	for i, message := range messages {
+		if c.PublishErr != nil {
+			results[i].Err = c.PublishErr
+			continue
+		}
+		c.Published = append(c.Published, Published{TopicID: topicID, Message: message.Data, Attributes: message.Attributes})
+		results[i].ID = "mockEventID"
+	}
+	return results
 }
 
-func (c *Client) Consume(ctx context.Context, subscriptionID string, fn pubsub.MessageHandler) {
+func (c *Client) Consume(ctx context.Context, subscriptionID string, settings pubsub.ReceiveSettings, fn pubsub.MessageHandler) (bool, error) {
+	if c.ConsumeErr != nil {
+		return true, c.ConsumeErr
+	}
+	<-ctx.Done()
+	return true, nil
 }
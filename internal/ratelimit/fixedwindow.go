@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/adapter/redis"
+	"time"
+)
+
+var _ Limiter = (*FixedWindow)(nil)
+
+// FixedWindow counts the requests of each period, it is cheap but lets up to twice the
+// rate through around the window boundaries.
+type FixedWindow struct {
+	adapter redis.Adapter
+	now     func() time.Time
+}
+
+func NewFixedWindow(adapter redis.Adapter, now func() time.Time) *FixedWindow {
+	return &FixedWindow{adapter: adapter, now: now}
+}
+
+func (f *FixedWindow) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	now := f.now()
+	window := now.UnixNano() / int64(limit.Period)
+	resetAfter := time.Duration((window+1)*int64(limit.Period) - now.UnixNano())
+
+	count, err := f.adapter.Incr(ctx, fmt.Sprintf("%sfw:%s:%d", keyPrefix, key, window), resetAfter)
+	if err != nil {
+		return Result{}, err
+	}
+
+	result := Result{
+		Allowed:    count <= int64(limit.Rate),
+		Limit:      limit.Rate,
+		Remaining:  limit.Rate - int(count),
+		ResetAfter: resetAfter,
+	}
+	if !result.Allowed {
+		result.Remaining = 0
+		result.RetryAfter = resetAfter
+	}
+	return result, nil
+}
@@ -0,0 +1,80 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/adapter/redis"
+	"strconv"
+	"time"
+)
+
+var _ Limiter = (*GCRA)(nil)
+
+const gcraMaxAttempts = 10
+
+var errContention = errors.New("rate limit state changed too often, giving up")
+
+// GCRA is the generic cell rate algorithm, a sliding window that only stores the theoretical
+// arrival time of the next request. The state is updated with a compare and swap, so the
+// clocks of the replicas should be in sync.
+type GCRA struct {
+	adapter redis.Adapter
+	now     func() time.Time
+}
+
+func NewGCRA(adapter redis.Adapter, now func() time.Time) *GCRA {
+	return &GCRA{adapter: adapter, now: now}
+}
+
+func (g *GCRA) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	burst := limit.Burst
+	if burst <= 0 {
+		burst = limit.Rate
+	}
+	interval := limit.Period / time.Duration(limit.Rate)
+	tolerance := interval * time.Duration(burst)
+	key = keyPrefix + "gcra:" + key
+
+	for attempt := 0; attempt < gcraMaxAttempts; attempt++ {
+		now := g.now()
+		stored, _, err := g.adapter.Get(ctx, key)
+		if err != nil {
+			return Result{}, err
+		}
+
+		tat := now
+		if stored != "" {
+			nanos, err := strconv.ParseInt(stored, 10, 64)
+			if err != nil {
+				return Result{}, err
+			}
+			if storedTat := time.Unix(0, nanos); storedTat.After(now) {
+				tat = storedTat
+			}
+		}
+
+		newTat := tat.Add(interval)
+		allowAt := newTat.Add(-tolerance)
+		if now.Before(allowAt) {
+			return Result{
+				Limit:      burst,
+				ResetAfter: tat.Sub(now),
+				RetryAfter: allowAt.Sub(now),
+			}, nil
+		}
+
+		swapped, err := g.adapter.CompareAndSwap(ctx, key, stored, strconv.FormatInt(newTat.UnixNano(), 10), newTat.Sub(now))
+		if err != nil {
+			return Result{}, err
+		}
+		if swapped {
+			return Result{
+				Allowed:    true,
+				Limit:      burst,
+				Remaining:  int(now.Sub(allowAt) / interval),
+				ResetAfter: newTat.Sub(now),
+			}, nil
+		}
+	}
+	return Result{}, errContention
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"go-structure-demo/internal/adapter/redis"
+	"time"
+)
+
+const (
+	AlgorithmFixedWindow = "fixed_window"
+	AlgorithmGCRA        = "gcra"
+
+	keyPrefix = "ratelimit:"
+)
+
+// Limit allows Rate requests per Period, Burst is how many of them can come at once and
+// is only used by GCRA, it defaults to Rate.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+type Result struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// ResetAfter is when the limit is fully available again.
+	ResetAfter time.Duration
+	// RetryAfter is when the next request is allowed, zero when this one was.
+	RetryAfter time.Duration
+}
+
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// New returns the limiter of the algorithm, the state is kept in redis so the limits are
+// shared by every replica.
+func New(algorithm string, adapter redis.Adapter) (Limiter, error) {
+	switch algorithm {
+	case AlgorithmFixedWindow:
+		return NewFixedWindow(adapter, time.Now), nil
+	case AlgorithmGCRA:
+		return NewGCRA(adapter, time.Now), nil
+	default:
+		return nil, fmt.Errorf("rate limit algorithm %q is not supported", algorithm)
+	}
+}
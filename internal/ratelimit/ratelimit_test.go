@@ -0,0 +1,97 @@
+package ratelimit
+
+import (
+	"context"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFixedWindow_Allow(t *testing.T) {
+	ctx := context.Background()
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := NewFixedWindow(redistest.NewMemory(clock.Now), clock.Now)
+	limit := Limit{Rate: 3, Period: time.Minute}
+
+	clock.Advance(15 * time.Second)
+	for i := 0; i < 3; i++ {
+		result, err := limiter.Allow(ctx, "ip", limit)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2-i, result.Remaining)
+		assert.Equal(t, 45*time.Second, result.ResetAfter)
+	}
+
+	result, err := limiter.Allow(ctx, "ip", limit)
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	assert.Equal(t, 45*time.Second, result.RetryAfter)
+
+	// other keys have their own counters
+	result, _ = limiter.Allow(ctx, "other", limit)
+	assert.True(t, result.Allowed)
+
+	clock.Advance(45 * time.Second)
+	result, _ = limiter.Allow(ctx, "ip", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 2, result.Remaining)
+}
+
+func TestGCRA_Allow(t *testing.T) {
+	ctx := context.Background()
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	limiter := NewGCRA(redistest.NewMemory(clock.Now), clock.Now)
+	limit := Limit{Rate: 6, Period: time.Minute, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		result, err := limiter.Allow(ctx, "ip", limit)
+		assert.Nil(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1-i, result.Remaining)
+	}
+
+	result, err := limiter.Allow(ctx, "ip", limit)
+	assert.Nil(t, err)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 10*time.Second, result.RetryAfter)
+	assert.Equal(t, 20*time.Second, result.ResetAfter)
+
+	// a request is allowed every period/rate afterwards
+	clock.Advance(10 * time.Second)
+	result, _ = limiter.Allow(ctx, "ip", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+	result, _ = limiter.Allow(ctx, "ip", limit)
+	assert.False(t, result.Allowed)
+
+	// the burst is available again once the state expired
+	clock.Advance(time.Minute)
+	result, _ = limiter.Allow(ctx, "ip", limit)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestNew(t *testing.T) {
+	adapter := redistest.NewMemory(nil)
+
+	testCases := []struct {
+		name        string
+		algorithm   string
+		shouldError bool
+	}{
+		{name: "fixed_window", algorithm: AlgorithmFixedWindow},
+		{name: "gcra", algorithm: AlgorithmGCRA},
+		{name: "unknown", algorithm: "leaky_bucket", shouldError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			limiter, err := New(tc.algorithm, adapter)
+			assert.Equal(t, tc.shouldError, err != nil)
+			assert.Equal(t, tc.shouldError, limiter == nil)
+		})
+	}
+}
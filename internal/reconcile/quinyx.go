@@ -0,0 +1,352 @@
+package reconcile
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/gateway/quinyxgateway"
+	"go-structure-demo/internal/lock"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/param"
+	"strconv"
+	"time"
+)
+
+// QuinyxLockKey is the key of the leader election, a single replica schedules the
+// reconciliations.
+const QuinyxLockKey = "quinyx-reconcile"
+
+const (
+	// quinyxRunLockKey is held for the time of a run, so a run of the cli never overlaps a
+	// scheduled one.
+	quinyxRunLockKey = "quinyx-reconcile-run"
+	quinyxCheckpoint = "quinyx-reconcile"
+	// quinyxLastRun is when the last scheduled run completed.
+	quinyxLastRun = "quinyx-reconcile-last-run"
+)
+
+// ErrRunning is returned by Reconcile while another run is in progress.
+var ErrRunning = errors.New("reconcile: a quinyx reconciliation is running already")
+
+const (
+	metricEmployees = "reconcile.quinyx.employees"
+	metricMissing   = "reconcile.quinyx.missing"
+	metricCreated   = "reconcile.quinyx.created"
+	metricFailed    = "reconcile.quinyx.failed"
+	metricDrift     = "reconcile.quinyx.drift"
+)
+
+// Report is the outcome of a reconciliation, the emails are listed for the missing users.
+type Report struct {
+	DryRun    bool     `json:"dry_run"`
+	Pages     int      `json:"pages"`
+	Employees int      `json:"employees"`
+	Matched   int      `json:"matched"`
+	Skipped   int      `json:"skipped"`
+	Missing   []string `json:"missing"`
+	Created   []string `json:"created"`
+	Failed    []string `json:"failed"`
+	Drifts    []Drift  `json:"drifts"`
+}
+
+// Drift is a field of a user that differs from its employee, it is reported and never fixed.
+type Drift struct {
+	Email      string `json:"email"`
+	EmployeeID string `json:"employee_id"`
+	UserID     uint   `json:"user_id"`
+	Field      string `json:"field"`
+	Quinyx     string `json:"quinyx"`
+	User       string `json:"user"`
+}
+
+// checkpoint is saved after every page, the report holds the pages done so far.
+type checkpoint struct {
+	PageToken string `json:"page_token"`
+	Report    Report `json:"report"`
+}
+
+// Quinyx pages through the Quinyx employees and creates the users missing for the active
+// ones, the hires whose EmployeeHired message never reached us. The users are matched by
+// email. A run that stops halfway resumes from the page after the last one done, unless it
+// is a dry run, those never read nor write the checkpoint.
+type Quinyx struct {
+	cfg             config.Reconcile
+	lockCfg         config.Lock
+	logger          log.Logger
+	metrics         metrics.Metrics
+	client          quinyxgateway.Client
+	userStore       contract.UserStore
+	userController  contract.UserController
+	checkpointStore contract.CheckpointStore
+	locker          *lock.Locker
+	now             func() time.Time
+}
+
+func NewQuinyx(
+	cfg config.Reconcile,
+	lockCfg config.Lock,
+	logger log.Logger,
+	metrics metrics.Metrics,
+	client quinyxgateway.Client,
+	userStore contract.UserStore,
+	userController contract.UserController,
+	checkpointStore contract.CheckpointStore,
+	locker *lock.Locker,
+) *Quinyx {
+	return &Quinyx{
+		cfg:             cfg,
+		lockCfg:         lockCfg,
+		logger:          logger,
+		metrics:         metrics,
+		client:          client,
+		userStore:       userStore,
+		userController:  userController,
+		checkpointStore: checkpointStore,
+		locker:          locker,
+		now:             time.Now,
+	}
+}
+
+// Run reconciles every Interval until ctx is done, the Interval must be positive. The first
+// run is due an Interval after the last scheduled one that completed, right away when there
+// is none, so the restarts and the leader changes don't push the runs back.
+func (q *Quinyx) Run(ctx context.Context) {
+	timer := time.NewTimer(q.untilNextRun(ctx))
+	defer timer.Stop()
+
+	q.logger.InfoWithContext(ctx, "quinyx reconciliation started")
+	for {
+		select {
+		case <-ctx.Done():
+			q.logger.InfoWithContext(ctx, "quinyx reconciliation stopped")
+			return
+		case <-timer.C:
+			_, err := q.Reconcile(ctx, q.cfg.DryRun)
+			switch {
+			case err == nil:
+				q.saveLastRun(ctx)
+			case errors.Is(err, ErrRunning):
+				// a run of the cli is in progress, it does the work of this one
+			default:
+				q.logger.ErrorWithContext(ctx, "quinyx reconciliation", err)
+			}
+			timer.Reset(q.cfg.Interval)
+		}
+	}
+}
+
+// untilNextRun returns how long until the next scheduled run is due.
+func (q *Quinyx) untilNextRun(ctx context.Context) time.Duration {
+	saved, ok, err := q.checkpointStore.GetCheckpoint(ctx, quinyxLastRun)
+	if err != nil {
+		q.logger.ErrorWithContext(ctx, "reading the last quinyx reconciliation failed", map[string]interface{}{
+			log.KeyError: err.Error(),
+		})
+	}
+	if !ok {
+		return 0
+	}
+	lastRun, err := time.Parse(time.RFC3339, saved)
+	if err != nil {
+		return 0
+	}
+	if wait := lastRun.Add(q.cfg.Interval).Sub(q.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+// saveLastRun is best effort, without it the next leader runs right away.
+func (q *Quinyx) saveLastRun(ctx context.Context) {
+	if err := q.checkpointStore.SetCheckpoint(ctx, quinyxLastRun, q.now().UTC().Format(time.RFC3339), q.cfg.Interval); err != nil {
+		q.logger.ErrorWithContext(ctx, "saving the last quinyx reconciliation failed", map[string]interface{}{
+			log.KeyError: err.Error(),
+		})
+	}
+}
+
+// Reconcile runs as the system. The errors of Quinyx and of the stores stop the run, a user
+// that fails to be created is reported and the run goes on. A single run goes at a time, the
+// others get ErrRunning, but the dry runs go anytime since they neither create users nor
+// touch the checkpoint.
+func (q *Quinyx) Reconcile(ctx context.Context, dryRun bool) (Report, error) {
+	if dryRun {
+		return q.run(ctx, dryRun)
+	}
+
+	runLock, err := q.locker.Obtain(ctx, quinyxRunLockKey, q.lockCfg.TTL)
+	if errors.Is(err, lock.ErrNotAcquired) {
+		return Report{}, ErrRunning
+	}
+	if err != nil {
+		return Report{}, err
+	}
+	defer q.release(ctx, runLock)
+
+	// the run stops if the lock is lost, another one may have started
+	return q.run(runLock.Context(), dryRun)
+}
+
+func (q *Quinyx) release(ctx context.Context, runLock *lock.Lock) {
+	// ctx may be done already, the release must still go through
+	releaseCtx, cancel := context.WithTimeout(context.Background(), q.lockCfg.TTL)
+	defer cancel()
+	if err := runLock.Release(releaseCtx); err != nil && !errors.Is(err, lock.ErrNotHeld) {
+		q.logger.ErrorWithContext(ctx, "releasing the quinyx reconciliation lock failed", map[string]interface{}{
+			log.KeyError: err.Error(),
+		})
+	}
+}
+
+func (q *Quinyx) run(ctx context.Context, dryRun bool) (Report, error) {
+	ctx = auth.WithPrincipal(ctx, auth.SystemPrincipal())
+
+	state := checkpoint{Report: Report{DryRun: dryRun}}
+	if !dryRun {
+		saved, ok, err := q.checkpointStore.GetCheckpoint(ctx, quinyxCheckpoint)
+		if err != nil {
+			return state.Report, err
+		}
+		if ok {
+			if err := json.Unmarshal([]byte(saved), &state); err != nil {
+				return state.Report, err
+			}
+			q.logger.InfoWithContext(ctx, "quinyx reconciliation resumed", map[string]interface{}{
+				"pages": state.Report.Pages,
+			})
+		}
+	}
+
+	for {
+		page, err := q.client.ListEmployees(ctx, state.PageToken, q.cfg.PageSize)
+		if err != nil {
+			return state.Report, err
+		}
+		for _, employee := range page.Employees {
+			if err := q.reconcile(ctx, employee, &state.Report); err != nil {
+				return state.Report, err
+			}
+		}
+		state.Report.Pages++
+
+		if page.NextPageToken == "" {
+			break
+		}
+		state.PageToken = page.NextPageToken
+		if !dryRun {
+			if err := q.saveCheckpoint(ctx, state); err != nil {
+				return state.Report, err
+			}
+		}
+	}
+
+	if !dryRun {
+		q.checkpointStore.DeleteCheckpoint(ctx, quinyxCheckpoint)
+	}
+	report := state.Report
+	q.logger.InfoWithContext(ctx, "quinyx reconciliation done", map[string]interface{}{
+		"dry_run":   report.DryRun,
+		"pages":     report.Pages,
+		"employees": report.Employees,
+		"matched":   report.Matched,
+		"skipped":   report.Skipped,
+		"missing":   len(report.Missing),
+		"created":   len(report.Created),
+		"failed":    len(report.Failed),
+		"drifts":    len(report.Drifts),
+	})
+	return report, nil
+}
+
+// reconcile adds the employee to the report. The inactive employees are only compared, a
+// user is never created for them, and the ones without an email can't be matched at all.
+func (q *Quinyx) reconcile(ctx context.Context, employee quinyxgateway.Employee, report *Report) error {
+	report.Employees++
+	q.metrics.Count(metricEmployees, 1)
+	if employee.Email == "" {
+		report.Skipped++
+		return nil
+	}
+
+	user, err := q.userStore.GetUserByEmail(ctx, employee.Email)
+	if apperror.Is(err, apperror.KindNotFound) {
+		if !employee.Active {
+			report.Skipped++
+			return nil
+		}
+		report.Missing = append(report.Missing, employee.Email)
+		q.metrics.Count(metricMissing, 1)
+		if report.DryRun {
+			return nil
+		}
+		q.createUser(ctx, employee, report)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	report.Matched++
+	fields := []struct{ name, quinyx, user string }{
+		{"first_name", employee.FirstName, user.FirstName},
+		{"last_name", employee.LastName, user.LastName},
+		{"active", strconv.FormatBool(employee.Active), "true"},
+	}
+	for _, field := range fields {
+		if field.quinyx == field.user {
+			continue
+		}
+		drift := Drift{
+			Email:      employee.Email,
+			EmployeeID: employee.ID,
+			UserID:     user.ID,
+			Field:      field.name,
+			Quinyx:     field.quinyx,
+			User:       field.user,
+		}
+		report.Drifts = append(report.Drifts, drift)
+		q.metrics.Count(metricDrift, 1, metrics.Tag("field", field.name))
+		q.logger.InfoWithContext(ctx, "quinyx drift", map[string]interface{}{
+			"user_id":     drift.UserID,
+			"employee_id": drift.EmployeeID,
+			"field":       drift.Field,
+		})
+	}
+	return nil
+}
+
+func (q *Quinyx) createUser(ctx context.Context, employee quinyxgateway.Employee, report *Report) {
+	response := q.userController.CreateUser(ctx, &param.CreateUserRequest{
+		Email:     employee.Email,
+		FirstName: employee.FirstName,
+		LastName:  employee.LastName,
+	})
+	if response.Error != nil {
+		report.Failed = append(report.Failed, employee.Email)
+		q.metrics.Count(metricFailed, 1)
+		q.logger.ErrorWithContext(ctx, "quinyx reconciliation user creation", map[string]interface{}{
+			"employee_id": employee.ID,
+			log.KeyError:  response.Error.Error(),
+		})
+		return
+	}
+	report.Created = append(report.Created, employee.Email)
+	q.metrics.Count(metricCreated, 1)
+	q.logger.InfoWithContext(ctx, "quinyx reconciliation user created", map[string]interface{}{
+		"user_id":     response.User.ID,
+		"employee_id": employee.ID,
+	})
+}
+
+func (q *Quinyx) saveCheckpoint(ctx context.Context, state checkpoint) error {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return q.checkpointStore.SetCheckpoint(ctx, quinyxCheckpoint, string(encoded), q.cfg.CheckpointTTL)
+}
@@ -0,0 +1,253 @@
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/auth"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/gateway/quinyxgateway"
+	"go-structure-demo/internal/gateway/quinyxgateway/quinyxtest"
+	"go-structure-demo/internal/lock"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/repository/redisrepo"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryUserStore struct {
+	contract.UserStore
+	users []entity.User
+}
+
+func (s *memoryUserStore) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return entity.User{}, apperror.NotFound("user not found")
+}
+
+// creatingUserController adds the users to the store, the emails in fail are rejected.
+type creatingUserController struct {
+	contract.UserController
+	store      *memoryUserStore
+	fail       map[string]bool
+	principals []auth.Principal
+}
+
+func (c *creatingUserController) CreateUser(ctx context.Context, request *param.CreateUserRequest) param.CreateUserResponse {
+	principal, _ := auth.PrincipalFrom(ctx)
+	c.principals = append(c.principals, principal)
+	if c.fail[request.Email] {
+		return param.CreateUserResponse{Error: errors.New("boom"), StatusCode: http.StatusInternalServerError}
+	}
+	user := entity.User{ID: uint(len(c.store.users) + 1), Email: request.Email, FirstName: request.FirstName, LastName: request.LastName}
+	c.store.users = append(c.store.users, user)
+	return param.CreateUserResponse{User: user, StatusCode: http.StatusCreated}
+}
+
+// failingClient fails the pages of the tokens in fail once.
+type failingClient struct {
+	quinyxgateway.Client
+	fail map[string]bool
+}
+
+func (c *failingClient) ListEmployees(ctx context.Context, pageToken string, pageSize int) (quinyxgateway.EmployeePage, error) {
+	if c.fail[pageToken] {
+		delete(c.fail, pageToken)
+		return quinyxgateway.EmployeePage{}, quinyxgateway.ErrUnavailable
+	}
+	return c.Client.ListEmployees(ctx, pageToken, pageSize)
+}
+
+type reconcileTest struct {
+	server      *quinyxtest.Server
+	client      *failingClient
+	store       *memoryUserStore
+	controller  *creatingUserController
+	checkpoints *redisrepo.RedisRepo
+	locker      *lock.Locker
+	reconciler  *Quinyx
+}
+
+func newReconcileTest(t *testing.T) *reconcileTest {
+	server := quinyxtest.NewServer()
+	t.Cleanup(server.Close)
+
+	test := &reconcileTest{server: server}
+	test.client = &failingClient{
+		Client: quinyxgateway.New(server.Config(), log.NewMock("quinyx"), metrics.NewNoop()),
+		fail:   make(map[string]bool),
+	}
+	test.store = &memoryUserStore{users: []entity.User{
+		{ID: 1, Email: "JOHN@doe.com", FirstName: "John", LastName: "Doe"},
+		{ID: 2, Email: "former@employee.com", FirstName: "Former", LastName: "Employee"},
+	}}
+	test.controller = &creatingUserController{store: test.store, fail: make(map[string]bool)}
+	adapter := redistest.NewMemory(nil)
+	test.checkpoints = redisrepo.NewWithAdapter(adapter)
+	test.locker = lock.NewLocker(adapter, log.NewMock("lock"))
+	test.reconciler = NewQuinyx(
+		config.Reconcile{PageSize: 2, CheckpointTTL: time.Hour},
+		config.Lock{TTL: time.Minute},
+		log.NewMock("reconcile"),
+		metrics.NewNoop(),
+		test.client,
+		test.store,
+		test.controller,
+		test.checkpoints,
+		test.locker,
+	)
+	return test
+}
+
+func TestQuinyx_Reconcile(t *testing.T) {
+	test := newReconcileTest(t)
+
+	report, err := test.reconciler.Reconcile(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Pages)
+	assert.Equal(t, 5, report.Employees)
+	assert.Equal(t, 2, report.Matched)
+	assert.Equal(t, 1, report.Skipped)
+	assert.Equal(t, []string{"jane@roe.com", "richard@miles.com"}, report.Missing)
+	assert.Equal(t, []string{"jane@roe.com", "richard@miles.com"}, report.Created)
+	assert.Empty(t, report.Failed)
+	assert.Equal(t, []Drift{{
+		Email:      "former@employee.com",
+		EmployeeID: "10045",
+		UserID:     2,
+		Field:      "active",
+		Quinyx:     "false",
+		User:       "true",
+	}}, report.Drifts)
+	assert.Len(t, test.store.users, 4)
+	for _, principal := range test.controller.principals {
+		assert.Equal(t, auth.RoleSystem, principal.Role)
+	}
+	_, ok, _ := test.checkpoints.GetCheckpoint(context.Background(), quinyxCheckpoint)
+	assert.False(t, ok)
+
+	// a second run finds nothing missing
+	report, err = test.reconciler.Reconcile(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Empty(t, report.Missing)
+	assert.Equal(t, 4, report.Matched)
+}
+
+func TestQuinyx_Reconcile_DryRun(t *testing.T) {
+	test := newReconcileTest(t)
+	test.client.fail["page-2"] = true
+
+	_, err := test.reconciler.Reconcile(context.Background(), true)
+	assert.True(t, errors.Is(err, quinyxgateway.ErrUnavailable))
+	_, ok, _ := test.checkpoints.GetCheckpoint(context.Background(), quinyxCheckpoint)
+	assert.False(t, ok)
+
+	report, err := test.reconciler.Reconcile(context.Background(), true)
+	assert.Nil(t, err)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, []string{"jane@roe.com", "richard@miles.com"}, report.Missing)
+	assert.Empty(t, report.Created)
+	assert.Empty(t, test.controller.principals)
+	assert.Len(t, test.store.users, 2)
+}
+
+func TestQuinyx_Reconcile_Resume(t *testing.T) {
+	test := newReconcileTest(t)
+	test.client.fail["page-2"] = true
+
+	report, err := test.reconciler.Reconcile(context.Background(), false)
+	assert.True(t, errors.Is(err, quinyxgateway.ErrUnavailable))
+	assert.Equal(t, []string{"jane@roe.com"}, report.Created)
+	_, ok, _ := test.checkpoints.GetCheckpoint(context.Background(), quinyxCheckpoint)
+	assert.True(t, ok)
+
+	report, err = test.reconciler.Reconcile(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, report.Pages)
+	assert.Equal(t, 5, report.Employees)
+	assert.Equal(t, []string{"jane@roe.com", "richard@miles.com"}, report.Created)
+
+	// the first page was read once, the resumed run started from the second one
+	firstPages := 0
+	for _, request := range test.server.Requests() {
+		if strings.Contains(request.Body, "<PageToken>") {
+			continue
+		}
+		firstPages++
+	}
+	assert.Equal(t, 1, firstPages)
+}
+
+func TestQuinyx_Reconcile_Running(t *testing.T) {
+	test := newReconcileTest(t)
+	ctx := context.Background()
+	running, err := test.locker.Obtain(ctx, quinyxRunLockKey, time.Minute)
+	assert.Nil(t, err)
+
+	_, err = test.reconciler.Reconcile(ctx, false)
+	assert.True(t, errors.Is(err, ErrRunning))
+	assert.Empty(t, test.server.Requests())
+
+	// a dry run goes anyway
+	report, err := test.reconciler.Reconcile(ctx, true)
+	assert.Nil(t, err)
+	assert.Len(t, report.Missing, 2)
+
+	// the lock is released once the run is over
+	assert.Nil(t, running.Release(ctx))
+	_, err = test.reconciler.Reconcile(ctx, false)
+	assert.Nil(t, err)
+	_, err = test.reconciler.Reconcile(ctx, false)
+	assert.Nil(t, err)
+}
+
+func TestQuinyx_Run(t *testing.T) {
+	test := newReconcileTest(t)
+	test.reconciler.cfg.Interval = time.Hour
+	now := time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)
+	test.reconciler.now = func() time.Time { return now }
+
+	// the first leader runs right away
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		test.reconciler.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool {
+		_, ok, _ := test.checkpoints.GetCheckpoint(context.Background(), quinyxLastRun)
+		return ok
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+	assert.Len(t, test.store.users, 4)
+
+	// the next one waits for the rest of the interval
+	now = now.Add(20 * time.Minute)
+	assert.Equal(t, 40*time.Minute, test.reconciler.untilNextRun(context.Background()))
+	now = now.Add(time.Hour)
+	assert.Equal(t, time.Duration(0), test.reconciler.untilNextRun(context.Background()))
+}
+
+func TestQuinyx_Reconcile_FailedCreation(t *testing.T) {
+	test := newReconcileTest(t)
+	test.controller.fail["jane@roe.com"] = true
+
+	report, err := test.reconciler.Reconcile(context.Background(), false)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"jane@roe.com"}, report.Failed)
+	assert.Equal(t, []string{"richard@miles.com"}, report.Created)
+}
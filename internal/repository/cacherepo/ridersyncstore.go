@@ -0,0 +1,48 @@
+package cacherepo
+
+import (
+	"context"
+	"go-structure-demo/internal/adapter/redis"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+var _ contract.RiderSyncStore = (*RiderSyncStore)(nil)
+
+// RiderSyncStore invalidates the cached user once every write of its rider sync is
+// committed, the reads go straight to the next store.
+type RiderSyncStore struct {
+	cache      redis.Adapter
+	next       contract.RiderSyncStore
+	transactor contract.Transactor
+}
+
+func NewRiderSyncStore(cache redis.Adapter, next contract.RiderSyncStore, transactor contract.Transactor) *RiderSyncStore {
+	return &RiderSyncStore{cache: cache, next: next, transactor: transactor}
+}
+
+func (s *RiderSyncStore) DueRiderSyncs(ctx context.Context, now time.Time, limit int) ([]entity.RiderSync, error) {
+	return s.next.DueRiderSyncs(ctx, now, limit)
+}
+
+func (s *RiderSyncStore) MarkRiderSynced(ctx context.Context, userID uint, riderID string, attempts int) error {
+	defer s.invalidate(ctx, userID)
+	return s.next.MarkRiderSynced(ctx, userID, riderID, attempts)
+}
+
+func (s *RiderSyncStore) MarkRiderSyncRetry(ctx context.Context, userID uint, attempts int, lastError string, nextAttemptAt time.Time) error {
+	defer s.invalidate(ctx, userID)
+	return s.next.MarkRiderSyncRetry(ctx, userID, attempts, lastError, nextAttemptAt)
+}
+
+func (s *RiderSyncStore) MarkRiderSyncFailed(ctx context.Context, userID uint, attempts int, lastError string) error {
+	defer s.invalidate(ctx, userID)
+	return s.next.MarkRiderSyncFailed(ctx, userID, attempts, lastError)
+}
+
+func (s *RiderSyncStore) invalidate(ctx context.Context, userID uint) {
+	s.transactor.AfterCommit(ctx, func() {
+		s.cache.Invalidate(ctx, userTag(userID))
+	})
+}
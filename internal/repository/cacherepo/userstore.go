@@ -0,0 +1,194 @@
+package cacherepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-structure-demo/internal/adapter/redis"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/param"
+	"math/rand"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+var _ contract.UserStore = (*UserStore)(nil)
+
+const (
+	metricHit  = "cache.hit"
+	metricMiss = "cache.miss"
+
+	// notFound is cached in place of the users that don't exist
+	notFound = "-"
+)
+
+// UserStore is a read-through cache in front of another contract.UserStore. Both the
+// entries by id and by email are tagged with user:{id}, so a write invalidates all of them.
+// The writes invalidate once their transaction is committed, an entry cached by a read made
+// before the commit would keep the old user otherwise.
+type UserStore struct {
+	cfg        config.UserCache
+	logger     log.Logger
+	metrics    metrics.Metrics
+	cache      redis.Adapter
+	next       contract.UserStore
+	transactor contract.Transactor
+	group      singleflight.Group
+	random     func() float64
+}
+
+func NewUserStore(
+	cfg config.UserCache,
+	logger log.Logger,
+	metrics metrics.Metrics,
+	cache redis.Adapter,
+	next contract.UserStore,
+	transactor contract.Transactor,
+) *UserStore {
+	return &UserStore{
+		cfg:        cfg,
+		logger:     logger,
+		metrics:    metrics,
+		cache:      cache,
+		next:       next,
+		transactor: transactor,
+		random:     rand.Float64,
+	}
+}
+
+func (s *UserStore) CreateUser(ctx context.Context, createUserRequest *param.CreateUserRequest) (entity.User, error) {
+	user, err := s.next.CreateUser(ctx, createUserRequest)
+	if err != nil {
+		return user, err
+	}
+	// a lookup by email may have cached the user as missing
+	s.invalidate(ctx, userTag(user.ID), emailTag(user.Email))
+	return user, nil
+}
+
+func (s *UserStore) GetUserByID(ctx context.Context, id uint) (entity.User, error) {
+	return s.load(ctx, "id", fmt.Sprintf("user:id:%d", id), []string{userTag(id)}, func() (entity.User, error) {
+		return s.next.GetUserByID(ctx, id)
+	})
+}
+
+func (s *UserStore) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	return s.load(ctx, "email", "user:email:"+normalizeEmail(email), []string{emailTag(email)}, func() (entity.User, error) {
+		return s.next.GetUserByEmail(ctx, email)
+	})
+}
+
+func (s *UserStore) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	updated, err := s.next.UpdateUser(ctx, user)
+	if err != nil {
+		return updated, err
+	}
+	// the entry of the old email is tagged with the user id as well
+	s.invalidate(ctx, userTag(user.ID), emailTag(updated.Email))
+	return updated, nil
+}
+
+func (s *UserStore) DeleteUser(ctx context.Context, id uint) error {
+	if err := s.next.DeleteUser(ctx, id); err != nil {
+		return err
+	}
+	s.invalidate(ctx, userTag(id))
+	return nil
+}
+
+// invalidate drops the entries of the tags once the transaction of ctx is committed.
+func (s *UserStore) invalidate(ctx context.Context, tags ...string) {
+	s.transactor.AfterCommit(ctx, func() {
+		s.cache.Invalidate(ctx, tags...)
+	})
+}
+
+// load returns the cached user or loads it once for all the concurrent callers of the key.
+// The reads within a transaction go straight to the next store, they may see the writes of
+// the transaction, which must neither be cached nor handed to the other callers.
+func (s *UserStore) load(ctx context.Context, lookup, key string, tags []string, fetch func() (entity.User, error)) (entity.User, error) {
+	if !s.cfg.Enabled || s.transactor.InTransaction(ctx) {
+		return fetch()
+	}
+
+	value, ok, err := s.cache.Get(ctx, key)
+	if err != nil {
+		s.logger.ErrorWithContext(ctx, "reading cached user failed", map[string]interface{}{
+			"key":        key,
+			log.KeyError: err.Error(),
+		})
+	}
+	if ok {
+		if value == notFound {
+			s.metrics.Count(metricHit, 1, metrics.Tag("cache", "user"), metrics.Tag("lookup", lookup), metrics.Tag("negative", "true"))
+			return entity.User{}, apperror.NotFound("user not found")
+		}
+		var user entity.User
+		if err := json.Unmarshal([]byte(value), &user); err == nil {
+			s.metrics.Count(metricHit, 1, metrics.Tag("cache", "user"), metrics.Tag("lookup", lookup), metrics.Tag("negative", "false"))
+			return user, nil
+		}
+	}
+	s.metrics.Count(metricMiss, 1, metrics.Tag("cache", "user"), metrics.Tag("lookup", lookup))
+
+	result, err, _ := s.group.Do(key, func() (interface{}, error) {
+		user, err := fetch()
+		if apperror.Is(err, apperror.KindNotFound) {
+			s.set(ctx, key, notFound, s.cfg.NotFoundTTL, tags...)
+			return nil, err
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(user)
+		if err != nil {
+			return nil, err
+		}
+		s.set(ctx, key, string(data), s.cfg.TTL, append(tags, userTag(user.ID))...)
+		return user, nil
+	})
+	if err != nil {
+		return entity.User{}, err
+	}
+	return result.(entity.User), nil
+}
+
+// set caches the value with a jittered ttl, a failing cache only costs a reload.
+func (s *UserStore) set(ctx context.Context, key, value string, ttl time.Duration, tags ...string) {
+	if err := s.cache.Set(ctx, key, value, s.jitter(ttl), tags...); err != nil {
+		s.logger.ErrorWithContext(ctx, "caching user failed", map[string]interface{}{
+			"key":        key,
+			log.KeyError: err.Error(),
+		})
+	}
+}
+
+// jitter spreads the ttl in the [ttl-ttl*Jitter, ttl+ttl*Jitter] range, so the entries
+// cached at the same time don't expire together.
+func (s *UserStore) jitter(ttl time.Duration) time.Duration {
+	if s.cfg.Jitter <= 0 {
+		return ttl
+	}
+	spread := float64(ttl) * s.cfg.Jitter
+	return ttl + time.Duration(spread*(2*s.random()-1))
+}
+
+func userTag(id uint) string {
+	return fmt.Sprintf("user:%d", id)
+}
+
+func emailTag(email string) string {
+	return "user-email:" + normalizeEmail(email)
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(email)
+}
@@ -0,0 +1,322 @@
+package cacherepo
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"go-structure-demo/internal/param"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingStore struct {
+	mu      sync.Mutex
+	users   map[uint]entity.User
+	loads   int32
+	release chan struct{}
+}
+
+func newCountingStore(users ...entity.User) *countingStore {
+	s := &countingStore{users: make(map[uint]entity.User)}
+	for _, user := range users {
+		s.users[user.ID] = user
+	}
+	return s
+}
+
+func (s *countingStore) CreateUser(ctx context.Context, createUserRequest *param.CreateUserRequest) (entity.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := entity.User{ID: uint(len(s.users) + 1), Email: createUserRequest.Email}
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *countingStore) GetUserByID(ctx context.Context, id uint) (entity.User, error) {
+	atomic.AddInt32(&s.loads, 1)
+	if s.release != nil {
+		<-s.release
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[id]
+	if !ok {
+		return entity.User{}, apperror.NotFound("user not found")
+	}
+	return user, nil
+}
+
+func (s *countingStore) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	atomic.AddInt32(&s.loads, 1)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+	return entity.User{}, apperror.NotFound("user not found")
+}
+
+func (s *countingStore) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[user.ID] = user
+	return user, nil
+}
+
+func (s *countingStore) DeleteUser(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.users, id)
+	return nil
+}
+
+// fakeTransactor runs the after commit hooks when the outermost transaction returns nil.
+type fakeTransactor struct {
+	hooks *[]func()
+}
+
+type fakeTxKey struct{}
+
+func (t fakeTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(fakeTxKey{}).(*[]func()); ok {
+		return fn(ctx)
+	}
+	hooks := new([]func())
+	if err := fn(context.WithValue(ctx, fakeTxKey{}, hooks)); err != nil {
+		return err
+	}
+	for _, hook := range *hooks {
+		hook()
+	}
+	return nil
+}
+
+func (t fakeTransactor) InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(fakeTxKey{}).(*[]func())
+	return ok
+}
+
+func (t fakeTransactor) AfterCommit(ctx context.Context, fn func()) {
+	if hooks, ok := ctx.Value(fakeTxKey{}).(*[]func()); ok {
+		*hooks = append(*hooks, fn)
+		return
+	}
+	fn()
+}
+
+func newTestUserStore(next *countingStore) (*UserStore, *metrics.Mock, *redistest.Clock) {
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	metricsMock := metrics.NewMock()
+	cfg := config.UserCache{Enabled: true, TTL: time.Minute, NotFoundTTL: time.Second, Jitter: 0.1}
+	store := NewUserStore(cfg, log.NewMock("cache"), metricsMock, redistest.NewMemory(clock.Now), next, fakeTransactor{})
+	return store, metricsMock, clock
+}
+
+func TestUserStore_ReadThrough(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingStore(entity.User{ID: 1, Email: "john@doe.com", FirstName: "John"})
+	store, metricsMock, _ := newTestUserStore(next)
+
+	for i := 0; i < 3; i++ {
+		user, err := store.GetUserByID(ctx, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, "John", user.FirstName)
+		user, err = store.GetUserByEmail(ctx, "John@Doe.com")
+		assert.Nil(t, err)
+		assert.Equal(t, uint(1), user.ID)
+	}
+
+	assert.Equal(t, int32(2), next.loads)
+	assert.Equal(t, int64(2), metricsMock.Counter(metricMiss))
+	assert.Equal(t, int64(4), metricsMock.Counter(metricHit))
+}
+
+func TestUserStore_Invalidation(t *testing.T) {
+	ctx := context.Background()
+
+	testCases := []struct {
+		name   string
+		write  func(store *UserStore) error
+		should entity.User
+	}{
+		{
+			name: "update",
+			write: func(store *UserStore) error {
+				_, err := store.UpdateUser(ctx, entity.User{ID: 1, Email: "jane@doe.com", FirstName: "Jane"})
+				return err
+			},
+			should: entity.User{ID: 1, Email: "jane@doe.com", FirstName: "Jane"},
+		},
+		{
+			name: "delete",
+			write: func(store *UserStore) error {
+				return store.DeleteUser(ctx, 1)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := newCountingStore(entity.User{ID: 1, Email: "john@doe.com", FirstName: "John"})
+			store, _, _ := newTestUserStore(next)
+			_, _ = store.GetUserByID(ctx, 1)
+			_, _ = store.GetUserByEmail(ctx, "john@doe.com")
+
+			assert.Nil(t, tc.write(store))
+
+			user, _ := store.GetUserByID(ctx, 1)
+			assert.Equal(t, tc.should, user)
+			_, err := store.GetUserByEmail(ctx, "john@doe.com")
+			assert.True(t, apperror.Is(err, apperror.KindNotFound))
+		})
+	}
+}
+
+func TestUserStore_InvalidationAfterCommit(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingStore(entity.User{ID: 1, Email: "john@doe.com", FirstName: "John"})
+	store, _, _ := newTestUserStore(next)
+	transactor := fakeTransactor{}
+
+	testCases := []struct {
+		name      string
+		err       error
+		shouldErr bool
+		should    string
+	}{
+		{
+			name:      "rollback",
+			err:       errors.New("rollback"),
+			shouldErr: true,
+			should:    "John",
+		},
+		{
+			name:   "commit",
+			should: "Jane",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, _ = store.GetUserByID(ctx, 1)
+			err := transactor.WithinTransaction(ctx, func(ctx context.Context) error {
+				if _, err := store.UpdateUser(ctx, entity.User{ID: 1, Email: "jane@doe.com", FirstName: "Jane"}); err != nil {
+					return err
+				}
+				// the transaction reads its own write, the others keep the cached user
+				user, _ := store.GetUserByID(ctx, 1)
+				assert.Equal(t, "Jane", user.FirstName)
+				user, _ = store.GetUserByID(context.Background(), 1)
+				assert.Equal(t, "John", user.FirstName)
+				return tc.err
+			})
+			assert.Equal(t, tc.shouldErr, err != nil)
+
+			if tc.shouldErr {
+				// the fake store keeps the write, the rollback is undone by hand
+				next.users[1] = entity.User{ID: 1, Email: "john@doe.com", FirstName: "John"}
+			}
+			user, err := store.GetUserByID(ctx, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.should, user.FirstName)
+		})
+	}
+}
+
+func TestUserStore_WithinTransaction(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingStore(entity.User{ID: 1, Email: "john@doe.com", FirstName: "John"})
+	store, _, _ := newTestUserStore(next)
+
+	err := fakeTransactor{}.WithinTransaction(ctx, func(ctx context.Context) error {
+		next.users[1] = entity.User{ID: 1, Email: "john@doe.com", FirstName: "Jane"}
+		for i := 0; i < 2; i++ {
+			user, err := store.GetUserByID(ctx, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, "Jane", user.FirstName)
+		}
+		return errors.New("rollback")
+	})
+	assert.NotNil(t, err)
+	next.users[1] = entity.User{ID: 1, Email: "john@doe.com", FirstName: "John"}
+
+	// the uncommitted user was never cached
+	user, err := store.GetUserByID(ctx, 1)
+	assert.Nil(t, err)
+	assert.Equal(t, "John", user.FirstName)
+	assert.Equal(t, int32(3), next.loads)
+}
+
+func TestUserStore_NegativeCaching(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingStore()
+	store, _, clock := newTestUserStore(next)
+
+	for i := 0; i < 2; i++ {
+		_, err := store.GetUserByEmail(ctx, "john@doe.com")
+		assert.True(t, apperror.Is(err, apperror.KindNotFound))
+	}
+	assert.Equal(t, int32(1), next.loads)
+
+	// creating the user drops the cached miss
+	_, err := store.CreateUser(ctx, &param.CreateUserRequest{Email: "john@doe.com"})
+	assert.Nil(t, err)
+	user, err := store.GetUserByEmail(ctx, "john@doe.com")
+	assert.Nil(t, err)
+	assert.Equal(t, uint(1), user.ID)
+
+	// the miss expires on its own
+	_, _ = store.GetUserByID(ctx, 2)
+	clock.Advance(2 * time.Second)
+	_, _ = store.GetUserByID(ctx, 2)
+	assert.Equal(t, int32(4), next.loads)
+}
+
+func TestUserStore_Singleflight(t *testing.T) {
+	ctx := context.Background()
+	next := newCountingStore(entity.User{ID: 1, Email: "john@doe.com"})
+	next.release = make(chan struct{})
+	store, metricsMock, _ := newTestUserStore(next)
+
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			user, err := store.GetUserByID(ctx, 1)
+			assert.Nil(t, err)
+			assert.Equal(t, uint(1), user.ID)
+		}()
+	}
+	// every caller missed the cache and joined the in-flight load
+	assert.Eventually(t, func() bool {
+		return metricsMock.Counter(metricMiss) == 10
+	}, time.Second, time.Millisecond)
+	close(next.release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), next.loads)
+}
+
+func TestUserStore_Jitter(t *testing.T) {
+	store, _, _ := newTestUserStore(newCountingStore())
+
+	for _, random := range []float64{0, 0.5, 0.999} {
+		store.random = func() float64 { return random }
+		ttl := store.jitter(time.Minute)
+		assert.GreaterOrEqual(t, ttl, 54*time.Second)
+		assert.LessOrEqual(t, ttl, 66*time.Second)
+	}
+}
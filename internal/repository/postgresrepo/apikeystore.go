@@ -0,0 +1,104 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var _ contract.APIKeyStore = (*PostgresRepo)(nil)
+
+const apiKeyColumns = `id, name, owner, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at`
+
+func (p *PostgresRepo) CreateAPIKey(ctx context.Context, key entity.APIKey) (entity.APIKey, error) {
+	return scanAPIKey(p.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO api_keys (name, owner, prefix, key_hash, scopes, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+apiKeyColumns,
+		key.Name, key.Owner, key.Prefix, key.Hash, pq.Array(key.Scopes), key.ExpiresAt,
+	))
+}
+
+func (p *PostgresRepo) GetAPIKeyByHash(ctx context.Context, hash string) (entity.APIKey, error) {
+	return scanAPIKey(p.conn(ctx).QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE key_hash = $1`, hash))
+}
+
+// ListAPIKeys returns the revoked and expired keys as well, newest first.
+func (p *PostgresRepo) ListAPIKeys(ctx context.Context) ([]entity.APIKey, error) {
+	rows, err := p.conn(ctx).QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY id DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	keys := make([]entity.APIKey, 0)
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+// RevokeAPIKey keeps the first revocation time of an already revoked key.
+func (p *PostgresRepo) RevokeAPIKey(ctx context.Context, id uint, revokedAt time.Time) error {
+	result, err := p.conn(ctx).ExecContext(ctx, `UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $2) WHERE id = $1`, id, revokedAt)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NotFound("api key not found")
+	}
+	return nil
+}
+
+func (p *PostgresRepo) TouchAPIKey(ctx context.Context, id uint, usedAt time.Time) error {
+	_, err := p.conn(ctx).ExecContext(ctx, `UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, usedAt)
+	return err
+}
+
+func scanAPIKey(row rowScanner) (entity.APIKey, error) {
+	var key entity.APIKey
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	err := row.Scan(
+		&key.ID,
+		&key.Name,
+		&key.Owner,
+		&key.Prefix,
+		&key.Hash,
+		pq.Array(&key.Scopes),
+		&expiresAt,
+		&lastUsedAt,
+		&revokedAt,
+		&key.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.APIKey{}, apperror.NotFound("api key not found")
+	}
+	if err != nil {
+		return entity.APIKey{}, err
+	}
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
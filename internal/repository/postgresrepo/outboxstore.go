@@ -0,0 +1,127 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+var _ contract.OutboxStore = (*PostgresRepo)(nil)
+
+func (p *PostgresRepo) AddOutboxEvent(ctx context.Context, event entity.OutboxEvent) error {
+	_, err := p.conn(ctx).ExecContext(
+		ctx,
+		`INSERT INTO outbox_events (aggregate_type, aggregate_id, event_type, payload, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		event.AggregateType, event.AggregateID, event.EventType, []byte(event.Payload), event.CreatedAt, event.NextAttemptAt,
+	)
+	return err
+}
+
+// DueOutboxEvents skips the aggregates whose oldest pending event is waiting for its next
+// attempt, so the order per aggregate holds and the batch is never filled by a single one.
+func (p *PostgresRepo) DueOutboxEvents(ctx context.Context, now time.Time, limit int) ([]entity.OutboxEvent, error) {
+	rows, err := p.conn(ctx).QueryContext(
+		ctx,
+		`SELECT e.id, e.aggregate_type, e.aggregate_id, e.event_type, e.payload, e.attempts, e.last_error, e.created_at, e.next_attempt_at
+		FROM outbox_events e
+		WHERE e.published_at IS NULL AND e.dead_at IS NULL AND e.next_attempt_at <= $1
+		AND NOT EXISTS (
+			SELECT 1 FROM outbox_events earlier
+			WHERE earlier.aggregate_type = e.aggregate_type AND earlier.aggregate_id = e.aggregate_id
+			AND earlier.published_at IS NULL AND earlier.dead_at IS NULL AND earlier.id < e.id
+		)
+		ORDER BY e.id
+		LIMIT $2`,
+		now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := make([]entity.OutboxEvent, 0, limit)
+	for rows.Next() {
+		var event entity.OutboxEvent
+		var payload []byte
+		err = rows.Scan(
+			&event.ID,
+			&event.AggregateType,
+			&event.AggregateID,
+			&event.EventType,
+			&payload,
+			&event.Attempts,
+			&event.LastError,
+			&event.CreatedAt,
+			&event.NextAttemptAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Payload = payload
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (p *PostgresRepo) MarkOutboxEventPublished(ctx context.Context, id uint64, publishedAt time.Time) error {
+	_, err := p.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE outbox_events SET published_at = $2, attempts = attempts + 1, last_error = '' WHERE id = $1`,
+		id, publishedAt,
+	)
+	return err
+}
+
+func (p *PostgresRepo) MarkOutboxEventRetry(ctx context.Context, id uint64, lastError string, nextAttemptAt time.Time) error {
+	_, err := p.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1`,
+		id, lastError, nextAttemptAt,
+	)
+	return err
+}
+
+func (p *PostgresRepo) MarkOutboxEventDead(ctx context.Context, id uint64, lastError string, deadAt time.Time) error {
+	_, err := p.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, dead_at = $3 WHERE id = $1`,
+		id, lastError, deadAt,
+	)
+	return err
+}
+
+func (p *PostgresRepo) DeletePublishedOutboxEvents(ctx context.Context, publishedBefore time.Time) (int64, error) {
+	result, err := p.conn(ctx).ExecContext(
+		ctx,
+		`DELETE FROM outbox_events WHERE published_at IS NOT NULL AND published_at < $1`,
+		publishedBefore,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+func (p *PostgresRepo) OutboxStats(ctx context.Context) (entity.OutboxStats, error) {
+	var stats entity.OutboxStats
+	var oldest sql.NullTime
+	err := p.conn(ctx).QueryRowContext(
+		ctx,
+		`SELECT
+			COUNT(*) FILTER (WHERE published_at IS NULL AND dead_at IS NULL),
+			COUNT(*) FILTER (WHERE dead_at IS NOT NULL),
+			MIN(created_at) FILTER (WHERE published_at IS NULL AND dead_at IS NULL)
+		FROM outbox_events`,
+	).Scan(&stats.Pending, &stats.Dead, &oldest)
+	if err != nil {
+		return entity.OutboxStats{}, err
+	}
+	if oldest.Valid {
+		stats.OldestPendingAt = &oldest.Time
+	}
+	return stats, nil
+}
@@ -1,15 +1,103 @@
 package postgresrepo
 
 import (
+	"context"
+	"database/sql"
 	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+
+	_ "github.com/lib/pq"
 )
 
+var _ contract.Transactor = (*PostgresRepo)(nil)
+
 type PostgresRepo struct {
+	db *sql.DB
+}
+
+func New(cfg *config.Config) (*PostgresRepo, func(), error) {
+	db, err := sql.Open("postgres", cfg.Postgres.DSN)
+	if err != nil {
+		return nil, func() {}, err
+	}
+	db.SetMaxOpenConns(cfg.Postgres.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Postgres.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Postgres.ConnMaxLifetime)
+
+	return &PostgresRepo{db: db}, func() {
+		_ = db.Close()
+	}, nil
+}
+
+type txKey struct{}
+
+// transaction is bound to the ctx of WithinTransaction, the nested calls share it.
+type transaction struct {
+	tx          *sql.Tx
+	afterCommit []func()
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+// conn returns the transaction bound to ctx, if any, otherwise the pool.
+func (p *PostgresRepo) conn(ctx context.Context) querier {
+	if t, ok := ctx.Value(txKey{}).(*transaction); ok {
+		return t.tx
+	}
+	return p.db
+}
+
+// WithinTransaction joins the transaction already bound to ctx or starts a new one.
+func (p *PostgresRepo) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	if _, ok := ctx.Value(txKey{}).(*transaction); ok {
+		return fn(ctx)
+	}
+
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	t := &transaction{tx: tx}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+		if err != nil {
+			_ = tx.Rollback()
+			return
+		}
+		if err = tx.Commit(); err != nil {
+			return
+		}
+		for _, fn := range t.afterCommit {
+			fn()
+		}
+	}()
+
+	return fn(context.WithValue(ctx, txKey{}, t))
+}
+
+func (p *PostgresRepo) InTransaction(ctx context.Context) bool {
+	_, ok := ctx.Value(txKey{}).(*transaction)
+	return ok
 }
 
-func New(cfg *config.Config) (*PostgresRepo, func()) {
-	//open the connection
-	return &PostgresRepo{}, func() {
-		// pass the connections closer
+func (p *PostgresRepo) AfterCommit(ctx context.Context, fn func()) {
+	if t, ok := ctx.Value(txKey{}).(*transaction); ok {
+		t.afterCommit = append(t.afterCommit, fn)
+		return
 	}
+	fn()
 }
@@ -0,0 +1,93 @@
+package postgresrepo
+
+import (
+	"context"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+var _ contract.RiderSyncStore = (*PostgresRepo)(nil)
+
+// DueRiderSyncs returns the pending syncs whose next attempt is due, the most overdue first.
+func (p *PostgresRepo) DueRiderSyncs(ctx context.Context, now time.Time, limit int) ([]entity.RiderSync, error) {
+	rows, err := p.conn(ctx).QueryContext(
+		ctx,
+		`SELECT `+userColumns+`, rider_sync_attempts, rider_sync_error, rider_sync_next_attempt_at
+		FROM users
+		WHERE rider_sync_status = $1 AND rider_sync_next_attempt_at <= $2
+		ORDER BY rider_sync_next_attempt_at, id
+		LIMIT $3`,
+		entity.RiderSyncPending, now, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	syncs := make([]entity.RiderSync, 0, limit)
+	for rows.Next() {
+		var sync entity.RiderSync
+		sync.User, err = p.scanUser(rows, &sync.Attempts, &sync.LastError, &sync.NextAttemptAt)
+		if err != nil {
+			return nil, err
+		}
+		syncs = append(syncs, sync)
+	}
+	return syncs, rows.Err()
+}
+
+func (p *PostgresRepo) MarkRiderSynced(ctx context.Context, userID uint, riderID string, attempts int) error {
+	return p.markRiderSync(
+		ctx,
+		`UPDATE users SET rider_id = $2, rider_sync_status = $3, rider_sync_attempts = $4, rider_sync_error = '' WHERE id = $1`,
+		userID, riderID, entity.RiderSyncSynced, attempts,
+	)
+}
+
+// MarkRiderSyncRetry leaves the synced users alone, an attempt failing late must not undo
+// a concurrent one that succeeded.
+func (p *PostgresRepo) MarkRiderSyncRetry(ctx context.Context, userID uint, attempts int, lastError string, nextAttemptAt time.Time) error {
+	return p.markRiderSync(
+		ctx,
+		`UPDATE users SET rider_sync_status = $2, rider_sync_attempts = $3, rider_sync_error = $4, rider_sync_next_attempt_at = $5
+		WHERE id = $1 AND rider_sync_status <> $6`,
+		userID, entity.RiderSyncPending, attempts, lastError, nextAttemptAt, entity.RiderSyncSynced,
+	)
+}
+
+// MarkRiderSyncFailed leaves the synced users alone, like MarkRiderSyncRetry.
+func (p *PostgresRepo) MarkRiderSyncFailed(ctx context.Context, userID uint, attempts int, lastError string) error {
+	return p.markRiderSync(
+		ctx,
+		`UPDATE users SET rider_sync_status = $2, rider_sync_attempts = $3, rider_sync_error = $4
+		WHERE id = $1 AND rider_sync_status <> $5`,
+		userID, entity.RiderSyncFailed, attempts, lastError, entity.RiderSyncSynced,
+	)
+}
+
+// markRiderSync returns a NotFound app error when the user doesn't exist, a user skipped by
+// the conditions of the query is not an error.
+func (p *PostgresRepo) markRiderSync(ctx context.Context, query string, userID uint, args ...interface{}) error {
+	result, err := p.conn(ctx).ExecContext(ctx, query, append([]interface{}{userID}, args...)...)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var exists bool
+	if err := p.conn(ctx).QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM users WHERE id = $1)`, userID).Scan(&exists); err != nil {
+		return err
+	}
+	if !exists {
+		return apperror.NotFound("user not found")
+	}
+	return nil
+}
@@ -2,10 +2,113 @@ package postgresrepo
 
 import (
 	"context"
+	"database/sql"
+	"errors"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/contract"
 	"go-structure-demo/internal/entity"
 	"go-structure-demo/internal/param"
+	"time"
 )
 
+var _ contract.UserStore = (*PostgresRepo)(nil)
+
+const userColumns = `id, email, first_name, last_name, gender, role, email_verified_at, rider_id, rider_sync_status`
+
+// riderSyncInlineGrace leaves the first rider sync attempt to the request creating the user,
+// the retries only pick the user up once it is over. It outlasts the retries of the rider
+// profile client.
+const riderSyncInlineGrace = time.Minute
+
 func (p *PostgresRepo) CreateUser(ctx context.Context, createUserRequest *param.CreateUserRequest) (entity.User, error) {
-	return entity.User{}, nil
+	user := entity.User{
+		Email:     createUserRequest.Email,
+		FirstName: createUserRequest.FirstName,
+		LastName:  createUserRequest.LastName,
+		Gender:    createUserRequest.Gender,
+		Role:      entity.RoleUser,
+		// the rider sync retries pick the user up if the first attempt never happens
+		RiderSyncStatus: entity.RiderSyncPending,
+	}
+
+	err := p.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO users (email, first_name, last_name, gender, role, rider_sync_status, rider_sync_next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`,
+		user.Email, user.FirstName, user.LastName, user.Gender, user.Role, user.RiderSyncStatus, time.Now().UTC().Add(riderSyncInlineGrace),
+	).Scan(&user.ID)
+	if err != nil {
+		return entity.User{}, err
+	}
+
+	return user, nil
+}
+
+func (p *PostgresRepo) GetUserByID(ctx context.Context, id uint) (entity.User, error) {
+	return p.scanUser(p.conn(ctx).QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE id = $1`, id))
+}
+
+func (p *PostgresRepo) GetUserByEmail(ctx context.Context, email string) (entity.User, error) {
+	return p.scanUser(p.conn(ctx).QueryRowContext(ctx, `SELECT `+userColumns+` FROM users WHERE LOWER(email) = LOWER($1)`, email))
+}
+
+// UpdateUser leaves the rider sync alone, it is written by the RiderSyncStore only.
+func (p *PostgresRepo) UpdateUser(ctx context.Context, user entity.User) (entity.User, error) {
+	return p.scanUser(p.conn(ctx).QueryRowContext(
+		ctx,
+		`UPDATE users SET email = $2, first_name = $3, last_name = $4, gender = $5, role = $6, email_verified_at = $7, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+userColumns,
+		user.ID, user.Email, user.FirstName, user.LastName, user.Gender, user.Role, user.EmailVerifiedAt,
+	))
+}
+
+func (p *PostgresRepo) DeleteUser(ctx context.Context, id uint) error {
+	result, err := p.conn(ctx).ExecContext(ctx, `DELETE FROM users WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NotFound("user not found")
+	}
+	return nil
+}
+
+// scanUser reads the userColumns followed by the extra columns of the query, if any.
+func (p *PostgresRepo) scanUser(row rowScanner, extra ...interface{}) (entity.User, error) {
+	var user entity.User
+	var gender, riderID sql.NullString
+	var emailVerifiedAt sql.NullTime
+	dest := []interface{}{
+		&user.ID,
+		&user.Email,
+		&user.FirstName,
+		&user.LastName,
+		&gender,
+		&user.Role,
+		&emailVerifiedAt,
+		&riderID,
+		&user.RiderSyncStatus,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.User{}, apperror.NotFound("user not found")
+	}
+	if err != nil {
+		return entity.User{}, err
+	}
+	if gender.Valid {
+		user.Gender = &gender.String
+	}
+	if emailVerifiedAt.Valid {
+		user.EmailVerifiedAt = &emailVerifiedAt.Time
+	}
+	if riderID.Valid {
+		user.RiderID = &riderID.String
+	}
+	return user, nil
 }
@@ -0,0 +1,140 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"time"
+)
+
+var _ contract.WebhookDeliveryStore = (*PostgresRepo)(nil)
+
+const webhookDeliveryColumns = `id, webhook_id, event_type, payload, status, attempts, response_status, last_error, redelivery_of, created_at, next_attempt_at, delivered_at`
+
+func (p *PostgresRepo) AddWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	return scanWebhookDelivery(p.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO webhook_deliveries (webhook_id, event_type, payload, redelivery_of, created_at, next_attempt_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+webhookDeliveryColumns,
+		delivery.WebhookID, delivery.EventType, []byte(delivery.Payload), delivery.RedeliveryOf, delivery.CreatedAt, delivery.NextAttemptAt,
+	))
+}
+
+func (p *PostgresRepo) GetWebhookDelivery(ctx context.Context, webhookID uint, id uint64) (entity.WebhookDelivery, error) {
+	return scanWebhookDelivery(p.conn(ctx).QueryRowContext(
+		ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE webhook_id = $1 AND id = $2`,
+		webhookID, id,
+	))
+}
+
+func (p *PostgresRepo) ListWebhookDeliveries(ctx context.Context, webhookID uint, limit int) ([]entity.WebhookDelivery, error) {
+	return p.queryWebhookDeliveries(
+		ctx,
+		`SELECT `+webhookDeliveryColumns+` FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY id DESC LIMIT $2`,
+		webhookID, limit,
+	)
+}
+
+// DueWebhookDeliveries skips the webhooks whose oldest pending delivery is waiting for its
+// next attempt, a delivery is never sent ahead of an earlier one of its webhook.
+func (p *PostgresRepo) DueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	return p.queryWebhookDeliveries(
+		ctx,
+		`SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE status = $1 AND next_attempt_at <= $2 AND webhook_id IN (SELECT id FROM webhooks WHERE active)
+		AND NOT EXISTS (
+			SELECT 1 FROM webhook_deliveries earlier
+			WHERE earlier.webhook_id = d.webhook_id AND earlier.status = $1 AND earlier.id < d.id
+		)
+		ORDER BY next_attempt_at, id
+		LIMIT $3`,
+		entity.WebhookDeliveryPending, now, limit,
+	)
+}
+
+func (p *PostgresRepo) MarkWebhookDeliverySucceeded(ctx context.Context, id uint64, attempts int, responseStatus int, deliveredAt time.Time) error {
+	_, err := p.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE webhook_deliveries SET status = $2, attempts = $3, response_status = $4, last_error = '', delivered_at = $5 WHERE id = $1`,
+		id, entity.WebhookDeliverySucceeded, attempts, responseStatus, deliveredAt,
+	)
+	return err
+}
+
+func (p *PostgresRepo) MarkWebhookDeliveryRetry(ctx context.Context, id uint64, attempts int, responseStatus int, lastError string, nextAttemptAt time.Time) error {
+	_, err := p.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE webhook_deliveries SET attempts = $2, response_status = $3, last_error = $4, next_attempt_at = $5 WHERE id = $1`,
+		id, attempts, responseStatus, lastError, nextAttemptAt,
+	)
+	return err
+}
+
+func (p *PostgresRepo) MarkWebhookDeliveryFailed(ctx context.Context, id uint64, attempts int, responseStatus int, lastError string) error {
+	_, err := p.conn(ctx).ExecContext(
+		ctx,
+		`UPDATE webhook_deliveries SET status = $2, attempts = $3, response_status = $4, last_error = $5 WHERE id = $1`,
+		id, entity.WebhookDeliveryFailed, attempts, responseStatus, lastError,
+	)
+	return err
+}
+
+func (p *PostgresRepo) queryWebhookDeliveries(ctx context.Context, query string, args ...interface{}) ([]entity.WebhookDelivery, error) {
+	rows, err := p.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	deliveries := make([]entity.WebhookDelivery, 0)
+	for rows.Next() {
+		delivery, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+	return deliveries, rows.Err()
+}
+
+func scanWebhookDelivery(row rowScanner) (entity.WebhookDelivery, error) {
+	var delivery entity.WebhookDelivery
+	var payload []byte
+	var redeliveryOf sql.NullInt64
+	var deliveredAt sql.NullTime
+	err := row.Scan(
+		&delivery.ID,
+		&delivery.WebhookID,
+		&delivery.EventType,
+		&payload,
+		&delivery.Status,
+		&delivery.Attempts,
+		&delivery.ResponseStatus,
+		&delivery.LastError,
+		&redeliveryOf,
+		&delivery.CreatedAt,
+		&delivery.NextAttemptAt,
+		&deliveredAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.WebhookDelivery{}, apperror.NotFound("webhook delivery not found")
+	}
+	if err != nil {
+		return entity.WebhookDelivery{}, err
+	}
+	delivery.Payload = payload
+	if redeliveryOf.Valid {
+		id := uint64(redeliveryOf.Int64)
+		delivery.RedeliveryOf = &id
+	}
+	if deliveredAt.Valid {
+		delivery.DeliveredAt = &deliveredAt.Time
+	}
+	return delivery, nil
+}
@@ -0,0 +1,141 @@
+package postgresrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+var _ contract.WebhookStore = (*PostgresRepo)(nil)
+
+const webhookColumns = `id, url, secret, events, active, failures, disabled_at, created_at, updated_at`
+
+func (p *PostgresRepo) CreateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	return scanWebhook(p.conn(ctx).QueryRowContext(
+		ctx,
+		`INSERT INTO webhooks (url, secret, events, active)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+webhookColumns,
+		webhook.URL, webhook.Secret, pq.Array(webhook.Events), webhook.Active,
+	))
+}
+
+func (p *PostgresRepo) GetWebhook(ctx context.Context, id uint) (entity.Webhook, error) {
+	return scanWebhook(p.conn(ctx).QueryRowContext(ctx, `SELECT `+webhookColumns+` FROM webhooks WHERE id = $1`, id))
+}
+
+func (p *PostgresRepo) ListWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	return p.queryWebhooks(ctx, `SELECT `+webhookColumns+` FROM webhooks ORDER BY id`)
+}
+
+func (p *PostgresRepo) UpdateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	return scanWebhook(p.conn(ctx).QueryRowContext(
+		ctx,
+		`UPDATE webhooks SET
+			url = $2,
+			events = $3,
+			failures = CASE WHEN $4 AND NOT active THEN 0 ELSE failures END,
+			disabled_at = CASE WHEN $4 THEN NULL ELSE disabled_at END,
+			active = $4,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+webhookColumns,
+		webhook.ID, webhook.URL, pq.Array(webhook.Events), webhook.Active,
+	))
+}
+
+func (p *PostgresRepo) DeleteWebhook(ctx context.Context, id uint) error {
+	result, err := p.conn(ctx).ExecContext(ctx, `DELETE FROM webhooks WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return apperror.NotFound("webhook not found")
+	}
+	return nil
+}
+
+func (p *PostgresRepo) ActiveWebhooks(ctx context.Context, eventType string) ([]entity.Webhook, error) {
+	return p.queryWebhooks(
+		ctx,
+		`SELECT `+webhookColumns+` FROM webhooks WHERE active AND events && $1 ORDER BY id`,
+		pq.Array([]string{eventType, entity.WebhookEventAll}),
+	)
+}
+
+func (p *PostgresRepo) ResetWebhookFailures(ctx context.Context, id uint) error {
+	_, err := p.conn(ctx).ExecContext(ctx, `UPDATE webhooks SET failures = 0 WHERE id = $1 AND failures > 0`, id)
+	return err
+}
+
+func (p *PostgresRepo) AddWebhookFailure(ctx context.Context, id uint, disableAfter int, now time.Time) (bool, error) {
+	var disabled bool
+	err := p.conn(ctx).QueryRowContext(
+		ctx,
+		`UPDATE webhooks SET
+			failures = failures + 1,
+			active = active AND failures + 1 < $2,
+			disabled_at = CASE WHEN active AND failures + 1 >= $2 THEN $3 ELSE disabled_at END
+		WHERE id = $1
+		RETURNING disabled_at IS NOT DISTINCT FROM $3`,
+		id, disableAfter, now,
+	).Scan(&disabled)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, apperror.NotFound("webhook not found")
+	}
+	return disabled, err
+}
+
+func (p *PostgresRepo) queryWebhooks(ctx context.Context, query string, args ...interface{}) ([]entity.Webhook, error) {
+	rows, err := p.conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	webhooks := make([]entity.Webhook, 0)
+	for rows.Next() {
+		webhook, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks, rows.Err()
+}
+
+func scanWebhook(row rowScanner) (entity.Webhook, error) {
+	var webhook entity.Webhook
+	var disabledAt sql.NullTime
+	err := row.Scan(
+		&webhook.ID,
+		&webhook.URL,
+		&webhook.Secret,
+		pq.Array(&webhook.Events),
+		&webhook.Active,
+		&webhook.Failures,
+		&disabledAt,
+		&webhook.CreatedAt,
+		&webhook.UpdatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return entity.Webhook{}, apperror.NotFound("webhook not found")
+	}
+	if err != nil {
+		return entity.Webhook{}, err
+	}
+	if disabledAt.Valid {
+		webhook.DisabledAt = &disabledAt.Time
+	}
+	return webhook, nil
+}
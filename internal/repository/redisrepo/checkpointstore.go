@@ -0,0 +1,25 @@
+package redisrepo
+
+import (
+	"context"
+	"go-structure-demo/internal/contract"
+	"time"
+)
+
+var _ contract.CheckpointStore = (*RedisRepo)(nil)
+
+func (rr *RedisRepo) GetCheckpoint(ctx context.Context, job string) (string, bool, error) {
+	return rr.adapter.Get(ctx, checkpointKey(job))
+}
+
+func (rr *RedisRepo) SetCheckpoint(ctx context.Context, job string, checkpoint string, expiration time.Duration) error {
+	return rr.adapter.Set(ctx, checkpointKey(job), checkpoint, expiration)
+}
+
+func (rr *RedisRepo) DeleteCheckpoint(ctx context.Context, job string) {
+	rr.adapter.Del(ctx, checkpointKey(job))
+}
+
+func checkpointKey(job string) string {
+	return "checkpoint:" + job
+}
@@ -0,0 +1,36 @@
+package redisrepo
+
+import (
+	"context"
+	"go-structure-demo/internal/contract"
+	"time"
+)
+
+var _ contract.IdempotencyStore = (*RedisRepo)(nil)
+
+const (
+	idempotencyPrefix = "idempotency:"
+
+	// idempotencyProcessing is the value of a claimed key, any other value is a handled one
+	idempotencyProcessing = "processing"
+	idempotencyProcessed  = "processed"
+)
+
+func (rr *RedisRepo) ClaimProcessing(ctx context.Context, key string, lease time.Duration) (bool, bool, error) {
+	claimed, err := rr.adapter.SetNX(ctx, idempotencyPrefix+key, idempotencyProcessing, lease)
+	if err != nil || claimed {
+		return claimed, false, err
+	}
+	value, ok, err := rr.adapter.Get(ctx, idempotencyPrefix+key)
+	return false, ok && value != idempotencyProcessing, err
+}
+
+func (rr *RedisRepo) MarkProcessed(ctx context.Context, key string, expiration time.Duration) error {
+	return rr.adapter.Set(ctx, idempotencyPrefix+key, idempotencyProcessed, expiration)
+}
+
+// ReleaseProcessing leaves the handled keys alone, only a claim is given up.
+func (rr *RedisRepo) ReleaseProcessing(ctx context.Context, key string) error {
+	_, err := rr.adapter.DelIfEqual(ctx, idempotencyPrefix+key, idempotencyProcessing)
+	return err
+}
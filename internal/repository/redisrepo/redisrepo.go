@@ -1,10 +1,31 @@
 package redisrepo
 
-import "go-structure-demo/internal/config"
+import (
+	"go-structure-demo/internal/adapter/redis"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/log"
+)
 
 type RedisRepo struct {
+	adapter redis.Adapter
 }
 
-func New(cfg *config.Config) (*RedisRepo, func()) {
-	return &RedisRepo{}, func() {}
+func New(cfg *config.Config, logger log.Logger) (*RedisRepo, func(), error) {
+	adapter, err := redis.New(cfg.Redis, logger)
+	if err != nil {
+		return nil, func() {}, err
+	}
+
+	return &RedisRepo{adapter: adapter}, func() {
+		_ = adapter.Close()
+	}, nil
+}
+
+// NewWithAdapter builds the repository on an existing adapter, like the in-memory one.
+func NewWithAdapter(adapter redis.Adapter) *RedisRepo {
+	return &RedisRepo{adapter: adapter}
+}
+
+func (r *RedisRepo) Adapter() redis.Adapter {
+	return r.adapter
 }
@@ -0,0 +1,13 @@
+package redisrepo
+
+import (
+	"context"
+	"go-structure-demo/internal/contract"
+	"time"
+)
+
+var _ contract.ThrottleStore = (*RedisRepo)(nil)
+
+func (rr *RedisRepo) Throttle(ctx context.Context, key string, interval time.Duration) (bool, error) {
+	return rr.adapter.SetNX(ctx, "throttle:"+key, 1, interval)
+}
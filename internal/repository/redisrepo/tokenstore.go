@@ -2,13 +2,51 @@ package redisrepo
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"strconv"
 	"time"
 )
 
-func (rr *RedisRepo) SetToken(ctx context.Context, token string, userID uint, expiration time.Duration) error {
-	return nil
+var _ contract.TokenStore = (*RedisRepo)(nil)
+
+func (rr *RedisRepo) SetToken(ctx context.Context, purpose entity.TokenPurpose, token string, userID uint, expiration time.Duration) error {
+	return rr.adapter.Set(ctx, tokenKey(purpose, token), uint64(userID), expiration)
+}
+
+// GetToken reads the token without consuming it, it is meant for the session tokens.
+func (rr *RedisRepo) GetToken(ctx context.Context, purpose entity.TokenPurpose, token string) (uint, bool, error) {
+	value, ok, err := rr.adapter.Get(ctx, tokenKey(purpose, token))
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	userID, ok := parseUserID(value)
+	return userID, ok, nil
+}
+
+// PullToken gets and deletes the token atomically, so concurrent pulls can't both succeed.
+func (rr *RedisRepo) PullToken(ctx context.Context, purpose entity.TokenPurpose, token string) (uint, bool, error) {
+	value, ok, err := rr.adapter.Pull(ctx, tokenKey(purpose, token))
+	if err != nil || !ok {
+		return 0, false, err
+	}
+	userID, ok := parseUserID(fmt.Sprint(value))
+	return userID, ok, nil
+}
+
+func parseUserID(value string) (uint, bool) {
+	userID, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return uint(userID), true
 }
 
-func (rr *RedisRepo) PullToken(ctx context.Context, token string) (uint, bool) {
-	return uint(0), true
+// tokenKey only keeps the hash of the token, a leaked key can't be redeemed.
+func tokenKey(purpose entity.TokenPurpose, token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return fmt.Sprintf("token:%s:%s", purpose, hex.EncodeToString(hash[:]))
 }
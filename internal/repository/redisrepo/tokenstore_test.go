@@ -0,0 +1,113 @@
+package redisrepo
+
+import (
+	"context"
+	"go-structure-demo/internal/adapter/redis/redistest"
+	"go-structure-demo/internal/entity"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedisRepo_PullToken(t *testing.T) {
+	ctx := context.Background()
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		name         string
+		setPurpose   entity.TokenPurpose
+		pullPurpose  entity.TokenPurpose
+		pullToken    string
+		advance      time.Duration
+		shouldUserID uint
+		shouldOk     bool
+	}{
+		{
+			name:         "valid_token",
+			setPurpose:   entity.TokenPurposeEmailVerification,
+			pullPurpose:  entity.TokenPurposeEmailVerification,
+			pullToken:    "the-token",
+			shouldUserID: 42,
+			shouldOk:     true,
+		},
+		{
+			name:        "other_purpose",
+			setPurpose:  entity.TokenPurposeEmailVerification,
+			pullPurpose: entity.TokenPurposePasswordReset,
+			pullToken:   "the-token",
+		},
+		{
+			name:        "unknown_token",
+			setPurpose:  entity.TokenPurposeMagicLogin,
+			pullPurpose: entity.TokenPurposeMagicLogin,
+			pullToken:   "other-token",
+		},
+		{
+			name:        "expired_token",
+			setPurpose:  entity.TokenPurposeMagicLogin,
+			pullPurpose: entity.TokenPurposeMagicLogin,
+			pullToken:   "the-token",
+			advance:     time.Hour,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := NewWithAdapter(redistest.NewMemory(clock.Now))
+			assert.Nil(t, repo.SetToken(ctx, tc.setPurpose, "the-token", 42, time.Hour))
+			clock.Advance(tc.advance)
+
+			userID, ok, err := repo.PullToken(ctx, tc.pullPurpose, tc.pullToken)
+			assert.Nil(t, err)
+			assert.Equal(t, tc.shouldOk, ok)
+			assert.Equal(t, tc.shouldUserID, userID)
+		})
+	}
+}
+
+func TestRedisRepo_PullTokenOnce(t *testing.T) {
+	ctx := context.Background()
+	adapter := redistest.NewMemory(nil)
+	repo := NewWithAdapter(adapter)
+	assert.Nil(t, repo.SetToken(ctx, entity.TokenPurposeMagicLogin, "the-token", 42, time.Hour))
+
+	// the plain token is never stored
+	assert.False(t, adapter.Has(ctx, "token:magic_login:the-token"))
+
+	pulled := int32(0)
+	wg := new(sync.WaitGroup)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, ok, _ := repo.PullToken(ctx, entity.TokenPurposeMagicLogin, "the-token"); ok {
+				atomic.AddInt32(&pulled, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(1), pulled)
+}
+
+func TestRedisRepo_GetToken(t *testing.T) {
+	ctx := context.Background()
+	clock := redistest.NewClock(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	repo := NewWithAdapter(redistest.NewMemory(clock.Now))
+	assert.Nil(t, repo.SetToken(ctx, entity.TokenPurposeSession, "the-token", 42, time.Hour))
+
+	for i := 0; i < 2; i++ {
+		userID, ok, err := repo.GetToken(ctx, entity.TokenPurposeSession, "the-token")
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint(42), userID)
+	}
+	_, ok, _ := repo.GetToken(ctx, entity.TokenPurposeMagicLogin, "the-token")
+	assert.False(t, ok)
+
+	clock.Advance(time.Hour)
+	_, ok, _ = repo.GetToken(ctx, entity.TokenPurposeSession, "the-token")
+	assert.False(t, ok)
+}
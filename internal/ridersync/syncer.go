@@ -0,0 +1,165 @@
+package ridersync
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/backoff"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/gateway/riderprofilegateway"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"strconv"
+	"time"
+)
+
+// LockKey is the key of the leader election, a single replica retries the syncs.
+const LockKey = "rider-sync"
+
+const (
+	metricSynced = "ridersync.synced"
+	metricRetry  = "ridersync.retry"
+	metricFailed = "ridersync.failed"
+)
+
+var _ contract.RiderSyncer = (*Syncer)(nil)
+
+// Syncer creates the rider profiles of the users. A failed attempt is retried in the
+// background with a backoff until MaxAttempts, after that or on a permanent error the user
+// is left as sync_failed for an admin to trigger again. Every attempt of a user sends the
+// same idempotency key, so the rider profile service never creates a rider twice.
+type Syncer struct {
+	cfg     config.RiderSync
+	logger  log.Logger
+	metrics metrics.Metrics
+	store   contract.RiderSyncStore
+	client  riderprofilegateway.Client
+	now     func() time.Time
+}
+
+func NewSyncer(
+	cfg config.RiderSync,
+	logger log.Logger,
+	metrics metrics.Metrics,
+	store contract.RiderSyncStore,
+	client riderprofilegateway.Client,
+) *Syncer {
+	return &Syncer{
+		cfg:     cfg,
+		logger:  logger,
+		metrics: metrics,
+		store:   store,
+		client:  client,
+		now:     func() time.Time { return time.Now().UTC() },
+	}
+}
+
+// SyncRider makes a fresh attempt for the user, the attempts made before are forgotten. The
+// users that have a rider already are returned as they are. It is called within the requests,
+// so the attempt is a single call of at most InlineTimeout and a failed one, timeouts
+// included, is left to Run.
+func (s *Syncer) SyncRider(ctx context.Context, user entity.User) (entity.User, error) {
+	if user.RiderID != nil {
+		return user, nil
+	}
+	return s.attempt(ctx, user, 1, true)
+}
+
+// Run retries the due syncs until ctx is done.
+func (s *Syncer) Run(ctx context.Context) {
+	poll := time.NewTicker(s.cfg.PollInterval)
+	defer poll.Stop()
+
+	s.logger.InfoWithContext(ctx, "rider sync started")
+	for {
+		select {
+		case <-ctx.Done():
+			s.logger.InfoWithContext(ctx, "rider sync stopped")
+			return
+		case <-poll.C:
+			if _, err := s.SyncDue(ctx); err != nil {
+				s.logger.ErrorWithContext(ctx, "rider sync", err)
+			}
+		}
+	}
+}
+
+// SyncDue retries one batch of due syncs and returns how many succeeded.
+func (s *Syncer) SyncDue(ctx context.Context) (int, error) {
+	syncs, err := s.store.DueRiderSyncs(ctx, s.now(), s.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	synced := 0
+	for _, sync := range syncs {
+		if ctx.Err() != nil {
+			return synced, ctx.Err()
+		}
+		if _, err := s.attempt(ctx, sync.User, sync.Attempts+1, false); err == nil {
+			synced++
+		}
+	}
+	return synced, nil
+}
+
+// attempt records the outcome on the user, unless ctx is done. The error is the one of the
+// rider profile service or of the store.
+func (s *Syncer) attempt(ctx context.Context, user entity.User, attempts int, inline bool) (entity.User, error) {
+	callCtx := ctx
+	if inline {
+		var cancel context.CancelFunc
+		callCtx, cancel = context.WithTimeout(riderprofilegateway.WithoutRetry(ctx), s.cfg.InlineTimeout)
+		defer cancel()
+	}
+	rider, err := s.client.CreateRider(callCtx, riderprofilegateway.CreateRiderRequest{
+		Name:           user.GetFullName(),
+		Email:          user.Email,
+		IdempotencyKey: "user-" + strconv.FormatUint(uint64(user.ID), 10),
+	})
+	if err == nil {
+		if err := s.store.MarkRiderSynced(ctx, user.ID, rider.ID, attempts); err != nil {
+			return user, err
+		}
+		s.metrics.Count(metricSynced, 1)
+		user.RiderID = &rider.ID
+		user.RiderSyncStatus = entity.RiderSyncSynced
+		return user, nil
+	}
+	if ctx.Err() != nil {
+		return user, err
+	}
+
+	fields := map[string]interface{}{
+		"user_id":    user.ID,
+		"attempts":   attempts,
+		log.KeyError: err.Error(),
+	}
+	if permanent(err) || attempts >= s.cfg.MaxAttempts {
+		s.logger.ErrorWithContext(ctx, "rider sync failed", fields)
+		s.metrics.Count(metricFailed, 1)
+		if err := s.store.MarkRiderSyncFailed(ctx, user.ID, attempts, err.Error()); err != nil {
+			return user, err
+		}
+		user.RiderSyncStatus = entity.RiderSyncFailed
+		return user, err
+	}
+
+	s.logger.ErrorWithContext(ctx, "rider sync will be retried", fields)
+	s.metrics.Count(metricRetry, 1)
+	next := s.now().Add(backoff.Exponential(s.cfg.InitialBackoff, s.cfg.MaxBackoff, attempts))
+	if err := s.store.MarkRiderSyncRetry(ctx, user.ID, attempts, err.Error(), next); err != nil {
+		return user, err
+	}
+	user.RiderSyncStatus = entity.RiderSyncPending
+	return user, err
+}
+
+// permanent tells the errors a retry doesn't fix, the rider profile service rejected the
+// rider itself.
+func permanent(err error) bool {
+	return errors.Is(err, riderprofilegateway.ErrInvalidRequest) ||
+		errors.Is(err, riderprofilegateway.ErrConflict) ||
+		errors.Is(err, riderprofilegateway.ErrNotFound)
+}
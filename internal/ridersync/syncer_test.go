@@ -0,0 +1,180 @@
+package ridersync
+
+import (
+	"context"
+	"go-structure-demo/internal/circuitbreaker"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/gateway/gatewayfake"
+	"go-structure-demo/internal/gateway/riderprofilegateway"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type memoryStore struct {
+	syncs map[uint]*entity.RiderSync
+}
+
+func (s *memoryStore) DueRiderSyncs(ctx context.Context, now time.Time, limit int) ([]entity.RiderSync, error) {
+	due := make([]entity.RiderSync, 0)
+	for _, sync := range s.syncs {
+		if sync.User.RiderSyncStatus == entity.RiderSyncPending && !sync.NextAttemptAt.After(now) {
+			due = append(due, *sync)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool { return due[i].User.ID < due[j].User.ID })
+	if len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (s *memoryStore) MarkRiderSynced(ctx context.Context, userID uint, riderID string, attempts int) error {
+	sync := s.syncs[userID]
+	sync.User.RiderID = &riderID
+	sync.User.RiderSyncStatus = entity.RiderSyncSynced
+	sync.Attempts = attempts
+	sync.LastError = ""
+	return nil
+}
+
+func (s *memoryStore) MarkRiderSyncRetry(ctx context.Context, userID uint, attempts int, lastError string, nextAttemptAt time.Time) error {
+	sync := s.syncs[userID]
+	sync.User.RiderSyncStatus = entity.RiderSyncPending
+	sync.Attempts = attempts
+	sync.LastError = lastError
+	sync.NextAttemptAt = nextAttemptAt
+	return nil
+}
+
+func (s *memoryStore) MarkRiderSyncFailed(ctx context.Context, userID uint, attempts int, lastError string) error {
+	sync := s.syncs[userID]
+	sync.User.RiderSyncStatus = entity.RiderSyncFailed
+	sync.Attempts = attempts
+	sync.LastError = lastError
+	return nil
+}
+
+func newTestSyncer(store *memoryStore, client *riderprofilegateway.Fake, now *time.Time) *Syncer {
+	syncer := NewSyncer(config.RiderSync{
+		InlineTimeout:  50 * time.Millisecond,
+		BatchSize:      10,
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     time.Hour,
+	}, log.NewMock("ridersync"), metrics.NewNoop(), store, client)
+	syncer.now = func() time.Time { return *now }
+	return syncer
+}
+
+func newStore(users ...entity.User) *memoryStore {
+	store := &memoryStore{syncs: make(map[uint]*entity.RiderSync)}
+	for _, user := range users {
+		user.RiderSyncStatus = entity.RiderSyncPending
+		store.syncs[user.ID] = &entity.RiderSync{User: user}
+	}
+	return store
+}
+
+func TestSyncer_SyncRider(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	unavailable := &riderprofilegateway.Error{StatusCode: http.StatusServiceUnavailable}
+	invalid := &riderprofilegateway.Error{StatusCode: http.StatusUnprocessableEntity}
+
+	testCases := []struct {
+		name           string
+		steps          []gatewayfake.Step
+		shouldStatus   string
+		shouldRiderID  bool
+		shouldErr      bool
+		shouldNextTime time.Time
+	}{
+		{name: "synced", shouldStatus: entity.RiderSyncSynced, shouldRiderID: true},
+		{name: "transient_error", steps: []gatewayfake.Step{{Err: unavailable}}, shouldStatus: entity.RiderSyncPending, shouldErr: true, shouldNextTime: now.Add(time.Minute)},
+		{name: "circuit_open", steps: []gatewayfake.Step{{Err: circuitbreaker.ErrOpen}}, shouldStatus: entity.RiderSyncPending, shouldErr: true, shouldNextTime: now.Add(time.Minute)},
+		{name: "timeout", steps: []gatewayfake.Step{{Latency: time.Second}}, shouldStatus: entity.RiderSyncPending, shouldErr: true, shouldNextTime: now.Add(time.Minute)},
+		{name: "permanent_error", steps: []gatewayfake.Step{{Err: invalid}}, shouldStatus: entity.RiderSyncFailed, shouldErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			user := entity.User{ID: 42, Email: "john@doe.com", FirstName: "John", LastName: "Doe"}
+			store := newStore(user)
+			client := riderprofilegateway.NewFake()
+			client.Script(riderprofilegateway.MethodCreateRider, tc.steps...)
+
+			synced, err := newTestSyncer(store, client, &now).SyncRider(ctx, user)
+			assert.Equal(t, tc.shouldErr, err != nil)
+			assert.Equal(t, tc.shouldStatus, synced.RiderSyncStatus)
+			assert.Equal(t, tc.shouldStatus, store.syncs[42].User.RiderSyncStatus)
+			assert.Equal(t, tc.shouldRiderID, synced.RiderID != nil)
+			assert.Equal(t, tc.shouldNextTime, store.syncs[42].NextAttemptAt)
+			request := client.Calls(riderprofilegateway.MethodCreateRider)[0].Args[0].(riderprofilegateway.CreateRiderRequest)
+			assert.Equal(t, "user-42", request.IdempotencyKey)
+			assert.Equal(t, "John Doe", request.Name)
+		})
+	}
+}
+
+func TestSyncer_SyncRider_AlreadySynced(t *testing.T) {
+	riderID := "rider-1"
+	client := riderprofilegateway.NewFake()
+	now := time.Now()
+
+	user, err := newTestSyncer(newStore(), client, &now).SyncRider(context.Background(), entity.User{ID: 1, RiderID: &riderID})
+	assert.Nil(t, err)
+	assert.Equal(t, &riderID, user.RiderID)
+	assert.Empty(t, client.Calls(""))
+}
+
+func TestSyncer_SyncDue(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	unavailable := &riderprofilegateway.Error{StatusCode: http.StatusServiceUnavailable}
+	store := newStore(
+		entity.User{ID: 1, Email: "flaky@doe.com"},
+		entity.User{ID: 2, Email: "down@doe.com"},
+	)
+	// the due syncs are attempted by user id, the flaky one succeeds on its second attempt
+	client := riderprofilegateway.NewFake()
+	client.Script(riderprofilegateway.MethodCreateRider,
+		gatewayfake.Step{Err: unavailable, Times: 2},
+		gatewayfake.Step{},
+		gatewayfake.Step{Err: unavailable, Times: -1},
+	)
+	syncer := newTestSyncer(store, client, &now)
+
+	synced, err := syncer.SyncDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, synced)
+
+	// nothing is due before the backoff
+	synced, err = syncer.SyncDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, synced)
+	assert.Len(t, client.Calls(riderprofilegateway.MethodCreateRider), 2)
+
+	now = now.Add(time.Minute)
+	synced, err = syncer.SyncDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, synced)
+	assert.Equal(t, entity.RiderSyncSynced, store.syncs[1].User.RiderSyncStatus)
+	assert.Equal(t, 2, store.syncs[1].Attempts)
+	assert.Equal(t, now.Add(2*time.Minute), store.syncs[2].NextAttemptAt)
+
+	// the third attempt is the last one
+	now = now.Add(2 * time.Minute)
+	synced, err = syncer.SyncDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, synced)
+	assert.Equal(t, entity.RiderSyncFailed, store.syncs[2].User.RiderSyncStatus)
+	assert.Equal(t, 3, store.syncs[2].Attempts)
+	assert.NotEmpty(t, store.syncs[2].LastError)
+}
@@ -0,0 +1,32 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/policy"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func CreateAPIKeyRequest(ctx context.Context, dto *param.CreateAPIKeyRequest) error {
+	if strings.TrimSpace(dto.Name) == "" {
+		return errors.New("name is required")
+	}
+	if strings.TrimSpace(dto.Owner) == "" {
+		return errors.New("owner is required")
+	}
+	if len(dto.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range dto.Scopes {
+		if !policy.IsAction(scope) {
+			return errors.New("scope " + strconv.Quote(scope) + " is not valid")
+		}
+	}
+	if dto.ExpiresAt != nil && !dto.ExpiresAt.After(time.Now()) {
+		return errors.New("expires_at must be in the future")
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/param"
+	"go-structure-demo/internal/webhook"
+	"net/url"
+	"strconv"
+)
+
+// webhookEvents are the events a webhook can subscribe to
+var webhookEvents = map[string]bool{
+	entity.WebhookEventAll:  true,
+	entity.EventUserCreated: true,
+	entity.EventUserUpdated: true,
+	entity.EventUserDeleted: true,
+}
+
+const minWebhookSecretLength = 16
+
+func CreateWebhookRequest(ctx context.Context, dto *param.CreateWebhookRequest) error {
+	if err := webhookRequest(dto.URL, dto.Events); err != nil {
+		return err
+	}
+	if dto.Secret != "" && len(dto.Secret) < minWebhookSecretLength {
+		return errors.New("secret must have at least " + strconv.Itoa(minWebhookSecretLength) + " characters")
+	}
+	return nil
+}
+
+func UpdateWebhookRequest(ctx context.Context, dto *param.UpdateWebhookRequest) error {
+	return webhookRequest(dto.URL, dto.Events)
+}
+
+// webhookRequest refuses the urls of the service's own network, the names resolving to
+// one are refused by the dispatcher when it dials them.
+func webhookRequest(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return errors.New("url must be an absolute https url")
+	}
+	if webhook.CheckHost(u.Hostname()) != nil {
+		return errors.New("url must not target a local, private or link-local address")
+	}
+	if len(events) == 0 {
+		return errors.New("at least one event is required")
+	}
+	for _, event := range events {
+		if !webhookEvents[event] {
+			return errors.New("event " + strconv.Quote(event) + " is not valid")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/param"
+	"strconv"
+	"time"
+)
+
+// maxLogLevelRevertAfter keeps a forgotten DEBUG from lasting more than a day.
+const maxLogLevelRevertAfter = 24 * time.Hour
+
+func SetLogLevelRequest(ctx context.Context, dto *param.SetLogLevelRequest) error {
+	if _, ok := log.ParseLevel(dto.Level); !ok {
+		return errors.New("level " + strconv.Quote(dto.Level) + " is not valid")
+	}
+	if dto.RevertAfter < 0 {
+		return errors.New("revert_after must be positive")
+	}
+	if dto.RevertAfter > maxLogLevelRevertAfter {
+		return errors.New("revert_after must be at most " + maxLogLevelRevertAfter.String())
+	}
+	return nil
+}
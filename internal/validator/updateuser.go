@@ -0,0 +1,16 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/param"
+	"strings"
+)
+
+func UpdateUserRequest(ctx context.Context, dto *param.UpdateUserRequest, store contract.ValidatorStore) error {
+	if dto.Email == "" || !strings.Contains(dto.Email, "@") {
+		return errors.New("email is not valid")
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"syscall"
+)
+
+// ErrAddressNotAllowed is returned for the webhooks targeting the service's own network.
+var ErrAddressNotAllowed = errors.New("webhook: address not allowed")
+
+// AllowedIP tells if the webhooks may be sent to ip. The loopback, private and link-local
+// addresses are refused, a webhook could reach the internal services or the metadata server
+// of the cloud provider otherwise.
+func AllowedIP(ip net.IP) bool {
+	return !(ip.IsLoopback() ||
+		ip.IsPrivate() ||
+		ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() ||
+		ip.IsMulticast() ||
+		ip.IsUnspecified())
+}
+
+// CheckHost refuses the local host names and the addresses AllowedIP refuses. The other
+// names are only checked once resolved, when the deliveries dial them.
+func CheckHost(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrAddressNotAllowed
+	}
+	if ip := net.ParseIP(host); ip != nil && !AllowedIP(ip) {
+		return ErrAddressNotAllowed
+	}
+	return nil
+}
+
+// control checks the address right before the connection is made, so a name resolving to a
+// refused address is caught whatever it resolved to when the webhook was created.
+func control(allowed func(ip net.IP) bool) func(network, address string, c syscall.RawConn) error {
+	return func(network, address string, c syscall.RawConn) error {
+		host, _, err := net.SplitHostPort(address)
+		if err != nil {
+			return err
+		}
+		ip := net.ParseIP(host)
+		if ip == nil || !allowed(ip) {
+			return ErrAddressNotAllowed
+		}
+		return nil
+	}
+}
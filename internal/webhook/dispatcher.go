@@ -0,0 +1,281 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go-structure-demo/internal/backoff"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/contract"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	httptrace "gopkg.in/DataDog/dd-trace-go.v1/contrib/net/http"
+)
+
+// LockKey is the key of the leader election, a single replica sends the deliveries.
+const LockKey = "webhook-dispatcher"
+
+const (
+	metricDelivered = "webhook.delivered"
+	metricRetry     = "webhook.retry"
+	metricFailed    = "webhook.failed"
+	metricDisabled  = "webhook.disabled"
+
+	maxResponseSize = 1 << 20
+)
+
+var _ contract.WebhookPublisher = (*Dispatcher)(nil)
+
+// Dispatcher queues the events for the subscribed webhooks and sends them. A failed attempt
+// is retried with a backoff until MaxAttempts, and a webhook failing DisableAfter attempts in
+// a row is disabled, its pending deliveries wait until it is enabled again.
+type Dispatcher struct {
+	cfg           config.Webhook
+	logger        log.Logger
+	metrics       metrics.Metrics
+	webhookStore  contract.WebhookStore
+	deliveryStore contract.WebhookDeliveryStore
+	client        *http.Client
+	// allowed checks the addresses the deliveries dial, see AllowedIP
+	allowed func(ip net.IP) bool
+	now     func() time.Time
+}
+
+func NewDispatcher(
+	cfg config.Webhook,
+	logger log.Logger,
+	metrics metrics.Metrics,
+	webhookStore contract.WebhookStore,
+	deliveryStore contract.WebhookDeliveryStore,
+) *Dispatcher {
+	d := &Dispatcher{
+		cfg:           cfg,
+		logger:        logger,
+		metrics:       metrics,
+		webhookStore:  webhookStore,
+		deliveryStore: deliveryStore,
+		allowed:       AllowedIP,
+		now:           func() time.Time { return time.Now().UTC() },
+	}
+
+	dialer := &net.Dialer{
+		Timeout:   cfg.Timeout,
+		KeepAlive: 30 * time.Second,
+		Control:   control(func(ip net.IP) bool { return d.allowed(ip) }),
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed in place of the webhook, its address is not the one to check
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	d.client = httptrace.WrapClient(&http.Client{
+		Transport: transport,
+		Timeout:   cfg.Timeout,
+		// a redirect is a misconfigured webhook, it is not followed
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	})
+	return d
+}
+
+// PublishWebhookEvent adds a delivery of the event for every active webhook subscribed to it.
+func (d *Dispatcher) PublishWebhookEvent(ctx context.Context, eventType string, payload interface{}) error {
+	webhooks, err := d.webhookStore.ActiveWebhooks(ctx, eventType)
+	if err != nil || len(webhooks) == 0 {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := d.now()
+	for _, webhook := range webhooks {
+		_, err := d.deliveryStore.AddWebhookDelivery(ctx, entity.WebhookDelivery{
+			WebhookID:     webhook.ID,
+			EventType:     eventType,
+			Payload:       data,
+			CreatedAt:     now,
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Run sends the due deliveries until ctx is done.
+func (d *Dispatcher) Run(ctx context.Context) {
+	poll := time.NewTicker(d.cfg.PollInterval)
+	defer poll.Stop()
+
+	d.logger.InfoWithContext(ctx, "webhook dispatcher started")
+	for {
+		select {
+		case <-ctx.Done():
+			d.logger.InfoWithContext(ctx, "webhook dispatcher stopped")
+			return
+		case <-poll.C:
+			if _, err := d.DeliverDue(ctx); err != nil {
+				d.logger.ErrorWithContext(ctx, "webhook dispatcher", err)
+			}
+		}
+	}
+}
+
+// DeliverDue sends the due deliveries batch after batch until none is left, and returns how
+// many succeeded. A batch holds the oldest pending delivery of each webhook, so a webhook
+// gets its deliveries in order and a failed one holds up the later ones until it succeeds
+// or fails for good. The webhooks of a batch are sent to concurrently, a slow webhook only
+// holds up its own deliveries.
+func (d *Dispatcher) DeliverDue(ctx context.Context) (int, error) {
+	delivered := 0
+	for {
+		batch, err := d.deliverBatch(ctx)
+		delivered += batch
+		if err != nil || batch == 0 {
+			return delivered, err
+		}
+	}
+}
+
+func (d *Dispatcher) deliverBatch(ctx context.Context) (int, error) {
+	deliveries, err := d.deliveryStore.DueWebhookDeliveries(ctx, d.now(), d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var delivered int64
+	group, groupCtx := errgroup.WithContext(ctx)
+	for _, delivery := range deliveries {
+		delivery := delivery
+		group.Go(func() error {
+			ok, err := d.deliver(groupCtx, delivery)
+			if ok {
+				atomic.AddInt64(&delivered, 1)
+			}
+			return err
+		})
+	}
+	err = group.Wait()
+	return int(delivered), err
+}
+
+// deliver sends the delivery to its webhook and records the outcome, it returns whether the
+// delivery succeeded. The error is the one of the stores.
+func (d *Dispatcher) deliver(ctx context.Context, delivery entity.WebhookDelivery) (bool, error) {
+	webhook, err := d.webhookStore.GetWebhook(ctx, delivery.WebhookID)
+	if err != nil {
+		return false, err
+	}
+
+	attempts := delivery.Attempts + 1
+	responseStatus, err := d.send(ctx, webhook, delivery)
+	if err == nil {
+		if err := d.deliveryStore.MarkWebhookDeliverySucceeded(ctx, delivery.ID, attempts, responseStatus, d.now()); err != nil {
+			return false, err
+		}
+		if webhook.Failures > 0 {
+			if err := d.webhookStore.ResetWebhookFailures(ctx, webhook.ID); err != nil {
+				return false, err
+			}
+		}
+		d.metrics.Count(metricDelivered, 1, metrics.Tag("event_type", delivery.EventType))
+		return true, nil
+	}
+	if ctx.Err() != nil {
+		return false, ctx.Err()
+	}
+
+	fields := map[string]interface{}{
+		"webhook_id":      webhook.ID,
+		"delivery_id":     delivery.ID,
+		"event_type":      delivery.EventType,
+		"attempts":        attempts,
+		"response_status": responseStatus,
+		log.KeyError:      err.Error(),
+	}
+	if attempts >= d.cfg.MaxAttempts {
+		d.logger.ErrorWithContext(ctx, "webhook delivery failed", fields)
+		d.metrics.Count(metricFailed, 1, metrics.Tag("event_type", delivery.EventType))
+		if err := d.deliveryStore.MarkWebhookDeliveryFailed(ctx, delivery.ID, attempts, responseStatus, err.Error()); err != nil {
+			return false, err
+		}
+	} else {
+		d.logger.ErrorWithContext(ctx, "webhook delivery will be retried", fields)
+		d.metrics.Count(metricRetry, 1, metrics.Tag("event_type", delivery.EventType))
+		next := d.now().Add(backoff.Exponential(d.cfg.InitialBackoff, d.cfg.MaxBackoff, attempts))
+		if err := d.deliveryStore.MarkWebhookDeliveryRetry(ctx, delivery.ID, attempts, responseStatus, err.Error(), next); err != nil {
+			return false, err
+		}
+	}
+
+	disabled, err := d.webhookStore.AddWebhookFailure(ctx, webhook.ID, d.cfg.DisableAfter, d.now())
+	if err != nil {
+		return false, err
+	}
+	webhook.Failures++
+	if disabled {
+		d.logger.ErrorWithContext(ctx, "webhook disabled", map[string]interface{}{
+			"webhook_id": webhook.ID,
+			"failures":   webhook.Failures,
+		})
+		d.metrics.Count(metricDisabled, 1)
+	}
+	return false, nil
+}
+
+// body is what the webhooks receive. The ID is the one of the first delivery of the event,
+// so the receivers can tell a redelivery from a new event.
+type body struct {
+	ID        uint64          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// send posts the delivery and returns the status of the response, anything but a 2xx fails.
+func (d *Dispatcher) send(ctx context.Context, webhook entity.Webhook, delivery entity.WebhookDelivery) (int, error) {
+	id := delivery.ID
+	if delivery.RedeliveryOf != nil {
+		id = *delivery.RedeliveryOf
+	}
+	payload, err := json.Marshal(body{ID: id, Event: delivery.EventType, CreatedAt: delivery.CreatedAt, Data: delivery.Payload})
+	if err != nil {
+		return 0, err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(payload))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := d.now()
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(HeaderEvent, delivery.EventType)
+	request.Header.Set(HeaderDelivery, strconv.FormatUint(delivery.ID, 10))
+	request.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp.Unix(), 10))
+	request.Header.Set(HeaderSignature, Sign(webhook.Secret, timestamp, payload))
+
+	response, err := d.client.Do(request)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = response.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(response.Body, maxResponseSize))
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return response.StatusCode, fmt.Errorf("webhook responded with status %d", response.StatusCode)
+	}
+	return response.StatusCode, nil
+}
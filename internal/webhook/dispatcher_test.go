@@ -0,0 +1,540 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"go-structure-demo/internal/apperror"
+	"go-structure-demo/internal/config"
+	"go-structure-demo/internal/entity"
+	"go-structure-demo/internal/log"
+	"go-structure-demo/internal/metrics"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// memoryStore is both the webhook and the delivery store.
+type memoryStore struct {
+	mu         sync.Mutex
+	webhooks   map[uint]entity.Webhook
+	deliveries map[uint64]entity.WebhookDelivery
+	nextID     uint64
+}
+
+func newMemoryStore(webhooks ...entity.Webhook) *memoryStore {
+	s := &memoryStore{webhooks: make(map[uint]entity.Webhook), deliveries: make(map[uint64]entity.WebhookDelivery)}
+	for _, webhook := range webhooks {
+		s.webhooks[webhook.ID] = webhook
+	}
+	return s
+}
+
+func (s *memoryStore) CreateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	webhook.ID = uint(len(s.webhooks) + 1)
+	s.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (s *memoryStore) GetWebhook(ctx context.Context, id uint) (entity.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	webhook, ok := s.webhooks[id]
+	if !ok {
+		return entity.Webhook{}, apperror.NotFound("webhook not found")
+	}
+	return webhook, nil
+}
+
+func (s *memoryStore) ListWebhooks(ctx context.Context) ([]entity.Webhook, error) {
+	return s.ActiveWebhooks(ctx, "")
+}
+
+func (s *memoryStore) UpdateWebhook(ctx context.Context, webhook entity.Webhook) (entity.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhooks[webhook.ID] = webhook
+	return webhook, nil
+}
+
+func (s *memoryStore) DeleteWebhook(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.webhooks, id)
+	return nil
+}
+
+// ActiveWebhooks returns every webhook when eventType is empty.
+func (s *memoryStore) ActiveWebhooks(ctx context.Context, eventType string) ([]entity.Webhook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var webhooks []entity.Webhook
+	for _, webhook := range s.webhooks {
+		if eventType == "" || webhook.Active && webhook.Subscribes(eventType) {
+			webhooks = append(webhooks, webhook)
+		}
+	}
+	sort.Slice(webhooks, func(i, j int) bool { return webhooks[i].ID < webhooks[j].ID })
+	return webhooks, nil
+}
+
+func (s *memoryStore) ResetWebhookFailures(ctx context.Context, id uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	webhook := s.webhooks[id]
+	webhook.Failures = 0
+	s.webhooks[id] = webhook
+	return nil
+}
+
+func (s *memoryStore) AddWebhookFailure(ctx context.Context, id uint, disableAfter int, now time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	webhook := s.webhooks[id]
+	webhook.Failures++
+	disabled := webhook.Active && webhook.Failures >= disableAfter
+	if disabled {
+		webhook.Active = false
+		webhook.DisabledAt = &now
+	}
+	s.webhooks[id] = webhook
+	return disabled, nil
+}
+
+func (s *memoryStore) AddWebhookDelivery(ctx context.Context, delivery entity.WebhookDelivery) (entity.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	delivery.ID = s.nextID
+	delivery.Status = entity.WebhookDeliveryPending
+	s.deliveries[delivery.ID] = delivery
+	return delivery, nil
+}
+
+func (s *memoryStore) GetWebhookDelivery(ctx context.Context, webhookID uint, id uint64) (entity.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery, ok := s.deliveries[id]
+	if !ok || delivery.WebhookID != webhookID {
+		return entity.WebhookDelivery{}, apperror.NotFound("webhook delivery not found")
+	}
+	return delivery, nil
+}
+
+func (s *memoryStore) ListWebhookDeliveries(ctx context.Context, webhookID uint, limit int) ([]entity.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deliveries []entity.WebhookDelivery
+	for _, delivery := range s.deliveries {
+		if delivery.WebhookID == webhookID {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) DueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]entity.WebhookDelivery, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// the oldest pending delivery of each webhook
+	heads := make(map[uint]entity.WebhookDelivery)
+	for _, delivery := range s.deliveries {
+		head, ok := heads[delivery.WebhookID]
+		if delivery.Status == entity.WebhookDeliveryPending && (!ok || delivery.ID < head.ID) {
+			heads[delivery.WebhookID] = delivery
+		}
+	}
+	var deliveries []entity.WebhookDelivery
+	for _, delivery := range heads {
+		if !delivery.NextAttemptAt.After(now) && s.webhooks[delivery.WebhookID].Active {
+			deliveries = append(deliveries, delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID < deliveries[j].ID })
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, nil
+}
+
+func (s *memoryStore) MarkWebhookDeliverySucceeded(ctx context.Context, id uint64, attempts int, responseStatus int, deliveredAt time.Time) error {
+	return s.mark(id, func(delivery *entity.WebhookDelivery) {
+		delivery.Status = entity.WebhookDeliverySucceeded
+		delivery.Attempts = attempts
+		delivery.ResponseStatus = responseStatus
+		delivery.DeliveredAt = &deliveredAt
+	})
+}
+
+func (s *memoryStore) MarkWebhookDeliveryRetry(ctx context.Context, id uint64, attempts int, responseStatus int, lastError string, nextAttemptAt time.Time) error {
+	return s.mark(id, func(delivery *entity.WebhookDelivery) {
+		delivery.Attempts = attempts
+		delivery.ResponseStatus = responseStatus
+		delivery.LastError = lastError
+		delivery.NextAttemptAt = nextAttemptAt
+	})
+}
+
+func (s *memoryStore) MarkWebhookDeliveryFailed(ctx context.Context, id uint64, attempts int, responseStatus int, lastError string) error {
+	return s.mark(id, func(delivery *entity.WebhookDelivery) {
+		delivery.Status = entity.WebhookDeliveryFailed
+		delivery.Attempts = attempts
+		delivery.ResponseStatus = responseStatus
+		delivery.LastError = lastError
+	})
+}
+
+func (s *memoryStore) mark(id uint64, fn func(delivery *entity.WebhookDelivery)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delivery := s.deliveries[id]
+	fn(&delivery)
+	s.deliveries[id] = delivery
+	return nil
+}
+
+// receiver verifies the signatures and answers with status, the bodies of the valid
+// requests are kept.
+type receiver struct {
+	mu     sync.Mutex
+	status int
+	bodies []body
+}
+
+func newReceiver(t *testing.T, secret string, now func() time.Time) (*receiver, *httptest.Server) {
+	r := &receiver{status: http.StatusOK}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		payload, _ := io.ReadAll(request.Body)
+		err := Verify(secret, request.Header.Get(HeaderTimestamp), request.Header.Get(HeaderSignature), payload, now(), time.Minute)
+		if !assert.Nil(t, err) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var b body
+		assert.Nil(t, json.Unmarshal(payload, &b))
+		assert.Equal(t, b.Event, request.Header.Get(HeaderEvent))
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.bodies = append(r.bodies, b)
+		w.WriteHeader(r.status)
+	}))
+	t.Cleanup(server.Close)
+	return r, server
+}
+
+func (r *receiver) setStatus(status int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.status = status
+}
+
+type dispatcherTest struct {
+	now        time.Time
+	store      *memoryStore
+	receiver   *receiver
+	dispatcher *Dispatcher
+}
+
+func newDispatcherTest(t *testing.T, cfg config.Webhook) *dispatcherTest {
+	test := &dispatcherTest{now: time.Date(2022, 3, 1, 12, 0, 0, 0, time.UTC)}
+	now := func() time.Time { return test.now }
+	var server *httptest.Server
+	test.receiver, server = newReceiver(t, "secret", now)
+	test.store = newMemoryStore(
+		entity.Webhook{ID: 1, URL: server.URL, Secret: "secret", Events: []string{entity.WebhookEventAll}, Active: true},
+		entity.Webhook{ID: 2, URL: server.URL, Secret: "secret", Events: []string{entity.EventUserDeleted}, Active: true},
+		entity.Webhook{ID: 3, URL: server.URL, Secret: "secret", Events: []string{entity.WebhookEventAll}, Active: false},
+	)
+	test.dispatcher = NewDispatcher(cfg, log.NewMock("webhook"), metrics.NewNoop(), test.store, test.store)
+	test.dispatcher.now = now
+	// the receiver listens on the loopback
+	test.dispatcher.allowed = func(ip net.IP) bool { return true }
+	return test
+}
+
+func testWebhookConfig() config.Webhook {
+	return config.Webhook{
+		BatchSize:      10,
+		Timeout:        time.Second,
+		MaxAttempts:    3,
+		InitialBackoff: time.Minute,
+		MaxBackoff:     90 * time.Second,
+		DisableAfter:   10,
+	}
+}
+
+func TestDispatcher_PublishWebhookEvent(t *testing.T) {
+	test := newDispatcherTest(t, testWebhookConfig())
+	ctx := context.Background()
+
+	assert.Nil(t, test.dispatcher.PublishWebhookEvent(ctx, entity.EventUserCreated, map[string]interface{}{"id": 7}))
+	assert.Nil(t, test.dispatcher.PublishWebhookEvent(ctx, entity.EventUserDeleted, map[string]interface{}{"id": 7}))
+
+	deliveries, _ := test.store.ListWebhookDeliveries(ctx, 1, 10)
+	assert.Len(t, deliveries, 2)
+	deliveries, _ = test.store.ListWebhookDeliveries(ctx, 2, 10)
+	if assert.Len(t, deliveries, 1) {
+		assert.Equal(t, entity.EventUserDeleted, deliveries[0].EventType)
+		assert.JSONEq(t, `{"id": 7}`, string(deliveries[0].Payload))
+	}
+	deliveries, _ = test.store.ListWebhookDeliveries(ctx, 3, 10)
+	assert.Empty(t, deliveries)
+}
+
+func TestDispatcher_DeliverDue(t *testing.T) {
+	test := newDispatcherTest(t, testWebhookConfig())
+	ctx := context.Background()
+	assert.Nil(t, test.dispatcher.PublishWebhookEvent(ctx, entity.EventUserCreated, map[string]interface{}{"id": 7}))
+
+	delivered, err := test.dispatcher.DeliverDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, delivered)
+
+	delivery, _ := test.store.GetWebhookDelivery(ctx, 1, 1)
+	assert.Equal(t, entity.WebhookDeliverySucceeded, delivery.Status)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Equal(t, http.StatusOK, delivery.ResponseStatus)
+	if assert.Len(t, test.receiver.bodies, 1) {
+		assert.Equal(t, uint64(1), test.receiver.bodies[0].ID)
+		assert.Equal(t, entity.EventUserCreated, test.receiver.bodies[0].Event)
+		assert.JSONEq(t, `{"id": 7}`, string(test.receiver.bodies[0].Data))
+	}
+
+	delivered, err = test.dispatcher.DeliverDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+}
+
+func TestDispatcher_DeliverDue_Retry(t *testing.T) {
+	test := newDispatcherTest(t, testWebhookConfig())
+	ctx := context.Background()
+	test.receiver.setStatus(http.StatusServiceUnavailable)
+	assert.Nil(t, test.dispatcher.PublishWebhookEvent(ctx, entity.EventUserCreated, map[string]interface{}{"id": 7}))
+
+	tests := []struct {
+		name     string
+		status   string
+		attempts int
+		next     time.Duration
+	}{
+		{name: "first_attempt", status: entity.WebhookDeliveryPending, attempts: 1, next: time.Minute},
+		{name: "backoff_is_capped", status: entity.WebhookDeliveryPending, attempts: 2, next: 90 * time.Second},
+		{name: "max_attempts", status: entity.WebhookDeliveryFailed, attempts: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivered, err := test.dispatcher.DeliverDue(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, delivered)
+
+			delivery, _ := test.store.GetWebhookDelivery(ctx, 1, 1)
+			assert.Equal(t, tt.status, delivery.Status)
+			assert.Equal(t, tt.attempts, delivery.Attempts)
+			assert.Equal(t, http.StatusServiceUnavailable, delivery.ResponseStatus)
+			assert.NotEmpty(t, delivery.LastError)
+			if tt.next > 0 {
+				assert.Equal(t, test.now.Add(tt.next), delivery.NextAttemptAt)
+			}
+
+			// nothing is due before the backoff is over
+			delivered, err = test.dispatcher.DeliverDue(ctx)
+			assert.Nil(t, err)
+			assert.Equal(t, 0, delivered)
+			assert.Len(t, test.receiver.bodies, tt.attempts)
+			test.now = test.now.Add(tt.next)
+		})
+	}
+
+	webhook, _ := test.store.GetWebhook(ctx, 1)
+	assert.Equal(t, 3, webhook.Failures)
+	assert.True(t, webhook.Active)
+}
+
+func TestDispatcher_DeliverDue_Disable(t *testing.T) {
+	cfg := testWebhookConfig()
+	cfg.DisableAfter = 2
+	test := newDispatcherTest(t, cfg)
+	ctx := context.Background()
+	test.receiver.setStatus(http.StatusInternalServerError)
+	for i := 0; i < 3; i++ {
+		assert.Nil(t, test.dispatcher.PublishWebhookEvent(ctx, entity.EventUserCreated, map[string]interface{}{"id": i}))
+	}
+
+	// the first delivery is retried, the later ones wait for it
+	for i := 0; i < 2; i++ {
+		_, err := test.dispatcher.DeliverDue(ctx)
+		assert.Nil(t, err)
+		test.now = test.now.Add(time.Minute)
+	}
+
+	// the others are left pending once the webhook is disabled
+	webhook, _ := test.store.GetWebhook(ctx, 1)
+	assert.False(t, webhook.Active)
+	assert.Equal(t, test.now.Add(-time.Minute), *webhook.DisabledAt)
+	assert.Len(t, test.receiver.bodies, 2)
+	for id := uint64(2); id <= 3; id++ {
+		delivery, _ := test.store.GetWebhookDelivery(ctx, 1, id)
+		assert.Equal(t, 0, delivery.Attempts)
+	}
+
+	// a success resets the failures, and the deliveries go out in order
+	test.receiver.setStatus(http.StatusNoContent)
+	test.now = test.now.Add(time.Minute)
+	webhook.Active = true
+	webhook.Failures = 1
+	_, _ = test.store.UpdateWebhook(ctx, webhook)
+	delivered, err := test.dispatcher.DeliverDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, delivered)
+	webhook, _ = test.store.GetWebhook(ctx, 1)
+	assert.Equal(t, 0, webhook.Failures)
+	if assert.Len(t, test.receiver.bodies, 5) {
+		for i, id := range []uint64{1, 2, 3} {
+			assert.Equal(t, id, test.receiver.bodies[i+2].ID)
+		}
+	}
+}
+
+func TestDispatcher_DeliverDue_Redelivery(t *testing.T) {
+	test := newDispatcherTest(t, testWebhookConfig())
+	ctx := context.Background()
+	redeliveryOf := uint64(42)
+	_, _ = test.store.AddWebhookDelivery(ctx, entity.WebhookDelivery{
+		WebhookID:     1,
+		EventType:     entity.EventUserUpdated,
+		Payload:       json.RawMessage(`{}`),
+		RedeliveryOf:  &redeliveryOf,
+		NextAttemptAt: test.now,
+	})
+
+	delivered, err := test.dispatcher.DeliverDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, delivered)
+	if assert.Len(t, test.receiver.bodies, 1) {
+		assert.Equal(t, redeliveryOf, test.receiver.bodies[0].ID)
+	}
+}
+
+func TestDispatcher_DeliverDue_RefusedAddress(t *testing.T) {
+	test := newDispatcherTest(t, testWebhookConfig())
+	test.dispatcher.allowed = AllowedIP
+	ctx := context.Background()
+	assert.Nil(t, test.dispatcher.PublishWebhookEvent(ctx, entity.EventUserCreated, map[string]interface{}{"id": 7}))
+
+	delivered, err := test.dispatcher.DeliverDue(ctx)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, delivered)
+	assert.Empty(t, test.receiver.bodies)
+	delivery, _ := test.store.GetWebhookDelivery(ctx, 1, 1)
+	assert.Equal(t, 1, delivery.Attempts)
+	assert.Contains(t, delivery.LastError, ErrAddressNotAllowed.Error())
+}
+
+func TestDispatcher_DeliverDue_SlowWebhook(t *testing.T) {
+	test := newDispatcherTest(t, testWebhookConfig())
+	ctx := context.Background()
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, request *http.Request) {
+		<-release
+	}))
+	t.Cleanup(slow.Close)
+	webhook, _ := test.store.GetWebhook(ctx, 2)
+	webhook.URL = slow.URL
+	webhook.Events = []string{entity.WebhookEventAll}
+	_, _ = test.store.UpdateWebhook(ctx, webhook)
+	assert.Nil(t, test.dispatcher.PublishWebhookEvent(ctx, entity.EventUserCreated, map[string]interface{}{"id": 7}))
+
+	done := make(chan int)
+	go func() {
+		delivered, err := test.dispatcher.DeliverDue(ctx)
+		assert.Nil(t, err)
+		done <- delivered
+	}()
+
+	// the delivery of the first webhook doesn't wait for the slow one
+	assert.Eventually(t, func() bool {
+		delivery, _ := test.store.GetWebhookDelivery(ctx, 1, 1)
+		return delivery.Status == entity.WebhookDeliverySucceeded
+	}, time.Second, 5*time.Millisecond)
+	close(release)
+	assert.Equal(t, 2, <-done)
+}
+
+func TestCheckHost(t *testing.T) {
+	testCases := []struct {
+		host        string
+		shouldError bool
+	}{
+		{host: "example.com"},
+		{host: "93.184.216.34"},
+		{host: "2606:2800:220:1:248:1893:25c8:1946"},
+		{host: "localhost", shouldError: true},
+		{host: "api.localhost.", shouldError: true},
+		{host: "127.0.0.1", shouldError: true},
+		{host: "::1", shouldError: true},
+		{host: "10.1.2.3", shouldError: true},
+		{host: "172.16.0.1", shouldError: true},
+		{host: "192.168.1.1", shouldError: true},
+		{host: "169.254.169.254", shouldError: true},
+		{host: "fe80::1", shouldError: true},
+		{host: "fd00::1", shouldError: true},
+		{host: "0.0.0.0", shouldError: true},
+		{host: "::ffff:127.0.0.1", shouldError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.host, func(t *testing.T) {
+			err := CheckHost(tc.host)
+			if tc.shouldError {
+				assert.ErrorIs(t, err, ErrAddressNotAllowed)
+				return
+			}
+			assert.Nil(t, err)
+		})
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Unix(1646136000, 0)
+	payload := []byte(`{"id":1}`)
+	signature := Sign("secret", now, payload)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		signature string
+		body      []byte
+		valid     bool
+	}{
+		{name: "valid", secret: "secret", timestamp: "1646136000", signature: signature, body: payload, valid: true},
+		{name: "wrong_secret", secret: "other", timestamp: "1646136000", signature: signature, body: payload},
+		{name: "tampered_body", secret: "secret", timestamp: "1646136000", signature: signature, body: []byte(`{"id":2}`)},
+		{name: "other_timestamp", secret: "secret", timestamp: "1646136001", signature: signature, body: payload},
+		{name: "stale_timestamp", secret: "secret", timestamp: "1646135000", signature: Sign("secret", time.Unix(1646135000, 0), payload), body: payload},
+		{name: "invalid_timestamp", secret: "secret", timestamp: "now", signature: signature, body: payload},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Verify(tt.secret, tt.timestamp, tt.signature, tt.body, now, 5*time.Minute)
+			if tt.valid {
+				assert.Nil(t, err)
+			} else {
+				assert.Equal(t, ErrInvalidSignature, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,8 @@
+DROP INDEX IF EXISTS users_rider_sync_due_idx;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS rider_sync_next_attempt_at,
+    DROP COLUMN IF EXISTS rider_sync_error,
+    DROP COLUMN IF EXISTS rider_sync_attempts,
+    DROP COLUMN IF EXISTS rider_sync_status,
+    DROP COLUMN IF EXISTS rider_id;
@@ -0,0 +1,15 @@
+-- the users created before the rider sync are left as sync_failed for an admin to sync, as
+-- sync_pending the retries would all be due at once and flood the rider profile service
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS rider_id                   VARCHAR(64),
+    ADD COLUMN IF NOT EXISTS rider_sync_status          VARCHAR(32) NOT NULL DEFAULT 'sync_failed',
+    ADD COLUMN IF NOT EXISTS rider_sync_attempts        INT         NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS rider_sync_error           TEXT        NOT NULL DEFAULT 'created before the rider sync',
+    ADD COLUMN IF NOT EXISTS rider_sync_next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+-- the new users start pending, CreateUser pushes their first retry past the inline attempt
+ALTER TABLE users
+    ALTER COLUMN rider_sync_status SET DEFAULT 'sync_pending',
+    ALTER COLUMN rider_sync_error SET DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS users_rider_sync_due_idx ON users (rider_sync_next_attempt_at) WHERE rider_sync_status = 'sync_pending';
@@ -0,0 +1,2 @@
+-- DueOutboxEvents looks up the earlier pending events of each aggregate
+CREATE INDEX IF NOT EXISTS outbox_events_aggregate_pending_idx ON outbox_events (aggregate_type, aggregate_id, id) WHERE published_at IS NULL AND dead_at IS NULL;